@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hadar/claude-deck/internal/cli"
+	"github.com/hadar/claude-deck/internal/ui"
+)
+
+func main() {
+	// Subcommands run headless; no arguments starts the TUI
+	if len(os.Args) > 1 {
+		os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr))
+	}
+
+	app, err := ui.NewApp()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "claude-deck: %v\n", err)
+		os.Exit(1)
+	}
+
+	p := tea.NewProgram(app, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	if _, err := p.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "claude-deck: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,150 @@
+// Package cli implements claude-deck's headless subcommands
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/hadar/claude-deck/internal/session"
+	"github.com/hadar/claude-deck/internal/terminal"
+)
+
+// Exit codes returned by Run
+const (
+	ExitOK    = 0
+	ExitError = 1
+	ExitUsage = 2
+)
+
+// command is a subcommand handler
+type command struct {
+	usage string
+	help  string
+	run   func(c *ctx, args []string) error
+}
+
+// ctx carries the output streams for a command
+type ctx struct {
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// errUsage signals a bad invocation (the command's usage is printed)
+var errUsage = errors.New("usage")
+
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"list":   {"list [--json] [--active]", "List sessions with status, group, branch and last activity", runList},
+		"open":   {"open <id|name>", "Open (or focus) a session in a terminal tab", runOpen},
+		"new":    {"new <path> [--name NAME]", "Start a new Claude session in a terminal tab", runNew},
+		"kill":   {"kill <id|name>", "Close a session's terminal tab", runKill},
+		"rename": {"rename <id|name> <new-name>", "Rename a session (empty name resets to the tab title)", runRename},
+		"pin":    {"pin [--off] <id|name>", "Pin (or unpin) a session", runPin},
+		"move":   {"move <id|name> <group>", "Move a session to a group (\"\" for no group)", runMove},
+		"hooks":  {"hooks install|uninstall|status", "Manage Claude Code hooks for push-based status", runHooks},
+		"paths":  {"paths [--ambiguous]", "Show which project path each Claude project directory maps to", runPaths},
+		"usage":  {"usage [--month M] [--by X] [--json]", "Show token usage and estimated cost by project, group or session", runUsage},
+	}
+}
+
+// Run executes a subcommand and returns the process exit code
+func Run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage(stdout)
+		return ExitOK
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "claude-deck: unknown command %q\n\n", args[0])
+		printUsage(stderr)
+		return ExitUsage
+	}
+
+	err := cmd.run(&ctx{stdout: stdout, stderr: stderr}, args[1:])
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, flag.ErrHelp):
+		return ExitOK
+	case errors.Is(err, errUsage):
+		fmt.Fprintf(stderr, "usage: claude-deck %s\n", cmd.usage)
+		return ExitUsage
+	default:
+		fmt.Fprintf(stderr, "claude-deck %s: %v\n", args[0], err)
+		return ExitError
+	}
+}
+
+// printUsage prints the command overview
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: claude-deck [command]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Without a command, starts the interactive deck.")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		cmd := commands[name]
+		fmt.Fprintf(w, "  %-34s %s\n", cmd.usage, cmd.help)
+	}
+}
+
+// newFlagSet returns a flag set that reports errors instead of exiting
+func (c *ctx) newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	return fs
+}
+
+// parseFlags parses flags and checks the positional argument count
+// Flags may come before or after positional arguments.
+func parseFlags(fs *flag.FlagSet, args []string, positional int) ([]string, error) {
+	var rest []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, errUsage
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		rest = append(rest, args[0])
+		args = args[1:]
+	}
+	if len(rest) != positional {
+		return nil, errUsage
+	}
+	return rest, nil
+}
+
+// loadManager loads sessions and selects the configured terminal backend
+func loadManager() (*session.Manager, error) {
+	m, err := session.NewManager()
+	if err != nil {
+		return nil, err
+	}
+	terminal.UseBackend(m.GetTerminal())
+	return m, nil
+}
+
+// requireTerminal fails when only the embedded backend is available,
+// since sessions embedded in a CLI process would exit with it
+func requireTerminal() error {
+	if terminal.Embedded() != nil {
+		return errors.New("no terminal backend found (run inside Kitty, tmux, WezTerm or Zellij)")
+	}
+	return nil
+}
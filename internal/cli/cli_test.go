@@ -0,0 +1,282 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hadar/claude-deck/internal/control"
+	"github.com/hadar/claude-deck/internal/session"
+	"github.com/hadar/claude-deck/internal/terminal"
+)
+
+const (
+	apiSessionID = "550e8400-e29b-41d4-a716-446655440000"
+	webSessionID = "550e8400-e29b-41d4-a716-446655440001"
+	cliSessionID = "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+)
+
+// setupHome creates a fake ~/.claude/projects with three sessions
+// PATH is emptied so no real terminal (or git) is touched.
+func setupHome(t *testing.T) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("PATH", t.TempDir())
+	for _, env := range []string{"TMUX", "ZELLIJ", "KITTY_WINDOW_ID", "KITTY_LISTEN_ON", "WEZTERM_PANE"} {
+		t.Setenv(env, "")
+	}
+
+	write := func(project, id string) {
+		dir := filepath.Join(home, ".claude", "projects", session.EncodeProjectPath(project))
+		os.MkdirAll(dir, 0755)
+		content := `{"type":"user","cwd":"` + project + `","message":{"role":"user","content":"hi"}}` + "\n"
+		if err := os.WriteFile(filepath.Join(dir, id+".jsonl"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("/work/api", apiSessionID)
+	write("/work/web", webSessionID)
+	write("/work/cli", cliSessionID)
+}
+
+func run(t *testing.T, args ...string) (string, string, int) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := Run(args, &stdout, &stderr)
+	return stdout.String(), stderr.String(), code
+}
+
+func TestRunUsage(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"no args prints help", nil, ExitOK},
+		{"help", []string{"help"}, ExitOK},
+		{"unknown command", []string{"frobnicate"}, ExitUsage},
+		{"missing argument", []string{"rename", "x"}, ExitUsage},
+		{"unknown flag", []string{"list", "--nope"}, ExitUsage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, code := run(t, tt.args...); code != tt.code {
+				t.Errorf("exit code = %d, want %d", code, tt.code)
+			}
+		})
+	}
+}
+
+func TestListJSON(t *testing.T) {
+	setupHome(t)
+
+	stdout, stderr, code := run(t, "list", "--json")
+	if code != ExitOK {
+		t.Fatalf("exit code = %d, stderr = %s", code, stderr)
+	}
+	var sessions []control.SessionInfo
+	if err := json.Unmarshal([]byte(stdout), &sessions); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout)
+	}
+	if len(sessions) != 3 {
+		t.Fatalf("expected 3 sessions, got %d", len(sessions))
+	}
+	for _, s := range sessions {
+		if s.Status != "idle" || s.ProjectPath == "" || s.LastActivity.IsZero() {
+			t.Errorf("unexpected session: %+v", s)
+		}
+	}
+
+	// --active filters out sessions without a tab
+	stdout, _, _ = run(t, "list", "--json", "--active")
+	if strings.TrimSpace(stdout) != "[]" {
+		t.Errorf("expected no active sessions, got %s", stdout)
+	}
+}
+
+func TestListTable(t *testing.T) {
+	setupHome(t)
+
+	stdout, _, code := run(t, "list")
+	if code != ExitOK {
+		t.Fatalf("exit code = %d", code)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "ID") {
+		t.Fatalf("unexpected table:\n%s", stdout)
+	}
+	if !strings.Contains(stdout, "7c9e6679") || !strings.Contains(stdout, "/work/cli") {
+		t.Errorf("table missing session:\n%s", stdout)
+	}
+}
+
+func TestPaths(t *testing.T) {
+	setupHome(t)
+
+	stdout, stderr, code := run(t, "paths")
+	if code != ExitOK {
+		t.Fatalf("exit code = %d", code)
+	}
+	if !strings.Contains(stdout, "-work-api  cwd") || !strings.Contains(stdout, "/work/api") {
+		t.Errorf("unexpected paths:\n%s", stdout)
+	}
+	if stderr != "" {
+		t.Errorf("unexpected ambiguity report: %s", stderr)
+	}
+
+	stdout, _, _ = run(t, "paths", "--ambiguous")
+	if lines := strings.Split(strings.TrimSpace(stdout), "\n"); len(lines) != 1 {
+		t.Errorf("--ambiguous listed unambiguous directories:\n%s", stdout)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	setupHome(t)
+	home, _ := os.UserHomeDir()
+	response := func(id, timestamp, model string, input int64) string {
+		return `{"type":"assistant","timestamp":"` + timestamp + `","message":{"id":"` + id + `","role":"assistant","model":"` + model +
+			`","content":[],"usage":{"input_tokens":` + fmt.Sprint(input) + `,"output_tokens":0}}}` + "\n"
+	}
+	appendLines := func(project, id string, lines ...string) {
+		path := filepath.Join(home, ".claude", "projects", session.EncodeProjectPath(project), id+".jsonl")
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		f.WriteString(strings.Join(lines, ""))
+	}
+	appendLines("/work/api", apiSessionID,
+		response("m1", "2026-10-10T10:00:00Z", "claude-sonnet-4-5", 1_000_000),
+		response("m2", "2026-09-10T10:00:00Z", "claude-sonnet-4-5", 1_000_000))
+	appendLines("/work/web", webSessionID, response("m3", "2026-10-10T10:00:00Z", "mystery-model", 500))
+
+	stdout, stderr, code := run(t, "usage", "--month", "2026-10", "--json")
+	if code != ExitOK {
+		t.Fatalf("exit code = %d, stderr = %s", code, stderr)
+	}
+	var rows []usageRow
+	if err := json.Unmarshal([]byte(stdout), &rows); err != nil {
+		t.Fatalf("bad JSON: %v\n%s", err, stdout)
+	}
+	if len(rows) != 2 || rows[0].Key != "/work/api" || rows[0].Input != 1_000_000 || rows[0].Cost != 3 || !rows[0].Priced {
+		t.Errorf("unexpected rows: %+v", rows)
+	}
+	if len(rows) == 2 && rows[1].Priced {
+		t.Errorf("%s should be unpriced", rows[1].Key)
+	}
+
+	stdout, stderr, code = run(t, "usage", "--month", "all")
+	if code != ExitOK || !strings.Contains(stdout, "$6.00") || !strings.Contains(stdout, "TOTAL") {
+		t.Errorf("unexpected table (exit %d):\n%s", code, stdout)
+	}
+	if !strings.Contains(stderr, "no price") {
+		t.Errorf("expected a note about unpriced models, got %q", stderr)
+	}
+
+	// Ungrouped sessions are labelled as in the deck
+	stdout, _, code = run(t, "usage", "--month", "all", "--by", "group")
+	if code != ExitOK || !strings.Contains(stdout, "(no group)") {
+		t.Errorf("unexpected group table (exit %d):\n%s", code, stdout)
+	}
+
+	for _, args := range [][]string{{"usage", "--month", "October"}, {"usage", "--by", "branch"}} {
+		if _, _, code := run(t, args...); code != ExitError {
+			t.Errorf("%v: exit code = %d, want %d", args, code, ExitError)
+		}
+	}
+}
+
+func TestRenamePinMove(t *testing.T) {
+	setupHome(t)
+
+	if _, stderr, code := run(t, "rename", "7c9e", "tooling"); code != ExitOK {
+		t.Fatalf("rename failed: %s", stderr)
+	}
+	// Name lookups are case-insensitive
+	if _, stderr, code := run(t, "pin", "Tooling"); code != ExitOK {
+		t.Fatalf("pin failed: %s", stderr)
+	}
+	if _, _, code := run(t, "move", "tooling", "nowhere"); code != ExitError {
+		t.Errorf("move to missing group: exit code = %d, want %d", code, ExitError)
+	}
+
+	m, err := session.NewManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.CreateGroup("Tools", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, stderr, code := run(t, "move", "tooling", "Tools"); code != ExitOK {
+		t.Fatalf("move failed: %s", stderr)
+	}
+
+	m, _ = session.NewManager()
+	s := m.FindSession(cliSessionID)
+	if s == nil || s.Name != "tooling" || !s.Renamed || !s.Pinned || s.GroupPath != "Tools" {
+		t.Fatalf("unexpected session after commands: %+v", s)
+	}
+
+	// Pinning is idempotent; --off unpins
+	run(t, "pin", "tooling")
+	run(t, "pin", "--off", "tooling")
+	m, _ = session.NewManager()
+	if m.FindSession(cliSessionID).Pinned {
+		t.Error("session should be unpinned")
+	}
+}
+
+// fakeKitty puts a kitty on PATH that lists one Claude tab for the api session
+// and logs every other remote control command; it returns the log's path
+func fakeKitty(t *testing.T) string {
+	t.Helper()
+	dir := os.Getenv("PATH")
+	log := filepath.Join(t.TempDir(), "kitty.log")
+	ls := `[{"tabs":[{"title":"✳ api","windows":[{"id":5,"title":"✳ api","cwd":"/work/api","cmdline":["claude","--resume","` + apiSessionID + `"]}]}]}]`
+	script := "#!/bin/sh\nif [ \"$2\" = ls ]; then\n  printf '%s' '" + ls + "'\nelse\n  echo \"$*\" >> '" + log + "'\nfi\n"
+	if err := os.WriteFile(filepath.Join(dir, "kitty"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { terminal.SetBackend(nil) })
+	return log
+}
+
+func TestRenameRetitlesTab(t *testing.T) {
+	setupHome(t)
+	if _, err := os.Stat("/bin/sh"); err != nil {
+		t.Skip("no /bin/sh")
+	}
+	log := fakeKitty(t)
+
+	if _, stderr, code := run(t, "rename", apiSessionID, "api work"); code != ExitOK || stderr != "" {
+		t.Fatalf("rename: exit code = %d, stderr = %q", code, stderr)
+	}
+	// An empty name hands the tab title back to Claude
+	if _, stderr, code := run(t, "rename", apiSessionID, ""); code != ExitOK || stderr != "" {
+		t.Fatalf("reset: exit code = %d, stderr = %q", code, stderr)
+	}
+
+	got, _ := os.ReadFile(log)
+	want := "@ set-tab-title --match window_id:5 api work\n@ set-tab-title --match window_id:5\n"
+	if string(got) != want {
+		t.Errorf("kitty commands = %q, want %q", got, want)
+	}
+}
+
+func TestOpenRequiresTerminal(t *testing.T) {
+	setupHome(t)
+
+	_, stderr, code := run(t, "open", "7c9e")
+	if code != ExitError || !strings.Contains(stderr, "no terminal backend") {
+		t.Errorf("open without terminal: code = %d, stderr = %q", code, stderr)
+	}
+	if _, _, code := run(t, "kill", "7c9e"); code != ExitError {
+		t.Errorf("kill without tab: exit code = %d, want %d", code, ExitError)
+	}
+}
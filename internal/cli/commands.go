@@ -0,0 +1,450 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hadar/claude-deck/internal/control"
+	"github.com/hadar/claude-deck/internal/session"
+	"github.com/hadar/claude-deck/internal/terminal"
+)
+
+func runList(c *ctx, args []string) error {
+	fs := c.newFlagSet("list")
+	asJSON := fs.Bool("json", false, "print sessions as JSON")
+	activeOnly := fs.Bool("active", false, "only list sessions with an open tab")
+	if _, err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+
+	m, err := loadManager()
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+	session.RefreshStatuses(m.Sessions)
+	session.RefreshGitBranches(m.Sessions)
+
+	var sessions []*session.Session
+	for _, s := range m.Sessions {
+		if !*activeOnly || s.Status.IsActive() {
+			sessions = append(sessions, s)
+		}
+	}
+	// Most recent first, like the deck's Active group
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].LastAccessedAt.After(sessions[j].LastAccessedAt)
+	})
+
+	if *asJSON {
+		out := make([]control.SessionInfo, 0, len(sessions))
+		for _, s := range sessions {
+			out = append(out, control.NewSessionInfo(s))
+		}
+		enc := json.NewEncoder(c.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	}
+
+	tw := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSTATUS\tNAME\tGROUP\tBRANCH\tLAST ACTIVE\tPATH")
+	for _, s := range sessions {
+		name := s.Name
+		if s.Pinned {
+			name = "★ " + name
+		}
+		fmt.Fprintf(tw, "%s\t%s %s\t%s\t%s\t%s\t%s\t%s\n",
+			s.ShortID(), s.Status.Symbol(), s.Status, name, dash(s.GroupPath), dash(s.GitBranch),
+			s.LastAccessedAt.Format("Jan 2 15:04"), s.ProjectPath)
+	}
+	return tw.Flush()
+}
+
+// dash returns "-" for empty table cells
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func runOpen(c *ctx, args []string) error {
+	rest, err := parseFlags(c.newFlagSet("open"), args, 1)
+	if err != nil {
+		return err
+	}
+	m, err := loadManager()
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+	if err := requireTerminal(); err != nil {
+		return err
+	}
+	s, err := m.ResolveSession(rest[0])
+	if err != nil {
+		return err
+	}
+
+	windowID, err := terminal.OpenSession(s.ProjectPath, s.ClaudeSessionID, s.ConfigDir(), session.GetActiveWindowID(s), s.Name)
+	if err != nil {
+		return err
+	}
+	// Store window ID for reliable tab matching (and clear from other sessions)
+	if windowID > 0 && session.ClaimWindowID(m.Sessions, s, windowID) {
+		if err := m.Save(); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(c.stdout, "Opened %s (%s)\n", s.Name, s.ShortID())
+	return nil
+}
+
+func runNew(c *ctx, args []string) error {
+	fs := c.newFlagSet("new")
+	name := fs.String("name", "", "session name")
+	rest, err := parseFlags(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	path, err := filepath.Abs(rest[0])
+	if err != nil {
+		return err
+	}
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", path)
+	}
+
+	m, err := loadManager()
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+	if err := requireTerminal(); err != nil {
+		return err
+	}
+
+	windowID, err := terminal.NewSession(path, *name)
+	if err != nil {
+		return err
+	}
+	// Claude only writes the JSONL later - the pending session carries the
+	// name and window until it's discovered
+	if windowID > 0 {
+		if _, err := m.AddPendingSession(path, *name, windowID); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(c.stdout, "Started new session in %s\n", path)
+	return nil
+}
+
+func runKill(c *ctx, args []string) error {
+	rest, err := parseFlags(c.newFlagSet("kill"), args, 1)
+	if err != nil {
+		return err
+	}
+	m, err := loadManager()
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+	s, err := m.ResolveSession(rest[0])
+	if err != nil {
+		return err
+	}
+
+	// Find window ID using all matching strategies (stored ID, --resume flag, project path)
+	windowID := session.FindWindowIDForSession(s)
+	if windowID <= 0 {
+		return fmt.Errorf("%s has no open tab", s.Name)
+	}
+	if err := terminal.CloseWindow(windowID); err != nil {
+		return err
+	}
+	s.KittyWindowID = 0
+	s.Status = session.StatusIdle
+
+	// Don't resume a killed session on the deck's next startup
+	var remaining []string
+	for _, id := range m.GetLastActiveSessionIDs() {
+		if id != s.ClaudeSessionID {
+			remaining = append(remaining, id)
+		}
+	}
+	m.SetLastActiveSessionIDs(remaining)
+	if err := m.Save(); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stdout, "Killed %s\n", s.Name)
+	return nil
+}
+
+func runRename(c *ctx, args []string) error {
+	rest, err := parseFlags(c.newFlagSet("rename"), args, 2)
+	if err != nil {
+		return err
+	}
+	m, err := loadManager()
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+	s, err := m.ResolveSession(rest[0])
+	if err != nil {
+		return err
+	}
+	if err := m.RenameSession(s.ID, rest[1]); err != nil {
+		return err
+	}
+	if err := session.RetitleTab(s, rest[1]); err != nil {
+		fmt.Fprintf(c.stderr, "claude-deck rename: renamed, but the tab title wasn't updated: %v\n", err)
+	}
+	if rest[1] == "" {
+		fmt.Fprintf(c.stdout, "Reset name of %s\n", s.ShortID())
+	} else {
+		fmt.Fprintf(c.stdout, "Renamed %s to %s\n", s.ShortID(), rest[1])
+	}
+	return nil
+}
+
+func runPin(c *ctx, args []string) error {
+	fs := c.newFlagSet("pin")
+	off := fs.Bool("off", false, "unpin the session")
+	rest, err := parseFlags(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	m, err := loadManager()
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+	s, err := m.ResolveSession(rest[0])
+	if err != nil {
+		return err
+	}
+	if s.Pinned == !*off {
+		return nil // already in the requested state
+	}
+	if err := m.TogglePin(s.ID); err != nil {
+		return err
+	}
+	if s.Pinned {
+		fmt.Fprintf(c.stdout, "Pinned %s\n", s.Name)
+	} else {
+		fmt.Fprintf(c.stdout, "Unpinned %s\n", s.Name)
+	}
+	return nil
+}
+
+func runMove(c *ctx, args []string) error {
+	rest, err := parseFlags(c.newFlagSet("move"), args, 2)
+	if err != nil {
+		return err
+	}
+	m, err := loadManager()
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+	s, err := m.ResolveSession(rest[0])
+	if err != nil {
+		return err
+	}
+
+	groupPath := ""
+	if rest[1] != "" {
+		g, err := m.ResolveGroup(rest[1])
+		if err != nil {
+			return err
+		}
+		groupPath = g.Path
+	}
+	if err := m.MoveSession(s.ID, groupPath); err != nil {
+		return err
+	}
+	if groupPath == "" {
+		fmt.Fprintf(c.stdout, "Moved %s out of its group\n", s.Name)
+	} else {
+		fmt.Fprintf(c.stdout, "Moved %s to %s\n", s.Name, groupPath)
+	}
+	return nil
+}
+
+func runHooks(c *ctx, args []string) error {
+	rest, err := parseFlags(c.newFlagSet("hooks"), args, 1)
+	if err != nil {
+		return err
+	}
+	switch rest[0] {
+	case "install":
+		if err := session.InstallHooks(); err != nil {
+			return err
+		}
+		for _, path := range session.ClaudeSettingsFiles() {
+			fmt.Fprintf(c.stdout, "Installed hooks in %s\n", path)
+		}
+	case "uninstall":
+		if err := session.UninstallHooks(); err != nil {
+			return err
+		}
+		for _, path := range session.ClaudeSettingsFiles() {
+			fmt.Fprintf(c.stdout, "Removed hooks from %s\n", path)
+		}
+	case "status":
+		tw := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+		for _, path := range session.ClaudeSettingsFiles() {
+			status := "not installed"
+			if session.HooksInstalledIn(path) {
+				status = "installed"
+			}
+			fmt.Fprintf(tw, "%s\t%s\n", status, path)
+		}
+		tw.Flush()
+	default:
+		return errors.New("expected install, uninstall or status")
+	}
+	return nil
+}
+
+func runPaths(c *ctx, args []string) error {
+	fs := c.newFlagSet("paths")
+	ambiguousOnly := fs.Bool("ambiguous", false, "only list directories that map to more than one path")
+	if _, err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+
+	dirs, err := session.ResolveProjectDirs()
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "DIRECTORY\tSOURCE\tPATH")
+	ambiguous := 0
+	for _, d := range dirs {
+		if d.Ambiguous() {
+			ambiguous++
+		} else if *ambiguousOnly {
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", filepath.Base(d.Dir), d.Source, d.Path)
+		if d.Ambiguous() {
+			for _, candidate := range d.Candidates[1:] {
+				fmt.Fprintf(tw, "\t\t%s (also matches)\n", candidate)
+			}
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if ambiguous == 1 {
+		fmt.Fprintln(c.stderr, "1 directory maps to more than one project path")
+	} else if ambiguous > 1 {
+		fmt.Fprintf(c.stderr, "%d directories map to more than one project path\n", ambiguous)
+	}
+	return nil
+}
+
+// usageRow is a line of `usage --json`
+type usageRow struct {
+	Key        string  `json:"key"`
+	Sessions   int     `json:"sessions"`
+	Input      int64   `json:"input_tokens"`
+	Output     int64   `json:"output_tokens"`
+	CacheWrite int64   `json:"cache_write_tokens"`
+	CacheRead  int64   `json:"cache_read_tokens"`
+	Cost       float64 `json:"cost_usd"`
+	Priced     bool    `json:"priced"` // false if a model wasn't in the price table
+}
+
+func runUsage(c *ctx, args []string) error {
+	fs := c.newFlagSet("usage")
+	month := fs.String("month", time.Now().Format(session.UsageMonthLayout), "month to total (YYYY-MM), or all")
+	by := fs.String("by", "project", "total by project, group or session")
+	asJSON := fs.Bool("json", false, "print totals as JSON")
+	if _, err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+	if *month == "all" {
+		*month = ""
+	} else if _, err := time.Parse(session.UsageMonthLayout, *month); err != nil {
+		return fmt.Errorf("--month %q is not YYYY-MM or all", *month)
+	}
+	var key func(*session.Session) string
+	switch *by {
+	case "project":
+		key = func(s *session.Session) string { return s.ProjectPath }
+	case "group":
+		key = func(s *session.Session) string {
+			if s.GroupPath == "" {
+				return "(no group)" // as in the deck's usage view
+			}
+			return s.GroupPath
+		}
+	case "session":
+		key = func(s *session.Session) string { return s.ShortID() + " " + s.Name }
+	default:
+		return fmt.Errorf("--by %q is not project, group or session", *by)
+	}
+
+	m, err := loadManager()
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+	totals := session.RollupUsage(m.Sessions, *month, m.GetPrices(), key)
+
+	if *asJSON {
+		out := make([]usageRow, 0, len(totals))
+		for _, t := range totals {
+			out = append(out, usageRow{t.Key, t.Sessions, t.Tokens.Input, t.Tokens.Output,
+				t.Tokens.CacheWrite, t.Tokens.CacheRead, t.Cost, t.Priced})
+		}
+		enc := json.NewEncoder(c.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	}
+
+	tw := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\tSESSIONS\tINPUT\tOUTPUT\tCACHE WRITE\tCACHE READ\tCOST\n", strings.ToUpper(*by))
+	var sum session.UsageTotal
+	sum.Key, sum.Priced = "TOTAL", true
+	for _, t := range totals {
+		printUsageRow(tw, t)
+		sum.Sessions += t.Sessions
+		sum.Tokens.Add(t.Tokens)
+		sum.Cost += t.Cost
+		sum.Priced = sum.Priced && t.Priced
+	}
+	if len(totals) > 1 {
+		printUsageRow(tw, sum)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if !sum.Priced {
+		fmt.Fprintln(c.stderr, "* some models have no price; add them under \"prices\" in the settings block of sessions.json")
+	}
+	return nil
+}
+
+// printUsageRow prints a usage table line (costs with unpriced models are marked *)
+func printUsageRow(tw *tabwriter.Writer, t session.UsageTotal) {
+	cost := fmt.Sprintf("$%.2f", t.Cost)
+	if !t.Priced {
+		cost += "*"
+	}
+	fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t%s\t%s\n", t.Key, t.Sessions,
+		session.FormatTokens(t.Tokens.Input), session.FormatTokens(t.Tokens.Output),
+		session.FormatTokens(t.Tokens.CacheWrite), session.FormatTokens(t.Tokens.CacheRead), cost)
+}
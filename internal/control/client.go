@@ -0,0 +1,142 @@
+package control
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Client calls a running deck over its control socket
+type Client struct {
+	conn    net.Conn
+	scanner *bufio.Scanner
+	mu      sync.Mutex
+	nextID  int
+	events  chan StatusEvent
+}
+
+// Dial connects to the deck's control socket at path
+func Dial(path string) (*Client, error) {
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+	return &Client{conn: conn, scanner: scanner}, nil
+}
+
+// Close closes the connection
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Call sends a request and decodes the result into result (which may be nil)
+// Status notifications received while waiting are queued for Events.
+func (c *Client) Call(method string, params, result interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.nextID++
+	id := json.RawMessage(strconv.Itoa(c.nextID))
+	req := &message{JSONRPC: "2.0", ID: &id, Method: method}
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		req.Params = data
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	c.conn.SetDeadline(time.Now().Add(2 * requestTimeout))
+	defer c.conn.SetDeadline(time.Time{})
+	if _, err := c.conn.Write(append(data, '\n')); err != nil {
+		return err
+	}
+
+	for {
+		msg, err := c.read()
+		if err != nil {
+			return err
+		}
+		if msg.ID == nil {
+			c.queueEvent(msg)
+			continue
+		}
+		if string(*msg.ID) != string(id) {
+			continue
+		}
+		if msg.Error != nil {
+			return msg.Error
+		}
+		if result != nil && len(msg.Result) > 0 {
+			return json.Unmarshal(msg.Result, result)
+		}
+		return nil
+	}
+}
+
+// Subscribe asks the deck for status notifications and returns the event channel
+// The channel is closed when the connection ends. After subscribing, the client
+// should only be used to receive events.
+func (c *Client) Subscribe() (<-chan StatusEvent, error) {
+	c.mu.Lock()
+	if c.events == nil {
+		c.events = make(chan StatusEvent, 64)
+	}
+	c.mu.Unlock()
+
+	if err := c.Call(MethodSubscribe, nil, nil); err != nil {
+		return nil, err
+	}
+	go func() {
+		defer close(c.events)
+		for {
+			msg, err := c.read()
+			if err != nil {
+				return
+			}
+			if msg.ID == nil {
+				c.queueEvent(msg)
+			}
+		}
+	}()
+	return c.events, nil
+}
+
+func (c *Client) read() (*message, error) {
+	if !c.scanner.Scan() {
+		if err := c.scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("deck closed the connection")
+	}
+	var msg message
+	if err := json.Unmarshal(c.scanner.Bytes(), &msg); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// queueEvent forwards a status notification (dropped when nobody subscribed or the queue is full)
+func (c *Client) queueEvent(msg *message) {
+	if c.events == nil || msg.Method != NotifyStatus {
+		return
+	}
+	var event StatusEvent
+	if json.Unmarshal(msg.Params, &event) != nil {
+		return
+	}
+	select {
+	case c.events <- event:
+	default:
+	}
+}
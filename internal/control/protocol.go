@@ -0,0 +1,105 @@
+// Package control implements the deck's local control socket: newline-delimited
+// JSON-RPC 2.0 over a unix socket, so editors and status bars can drive a running deck.
+package control
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"time"
+
+	"github.com/hadar/claude-deck/internal/session"
+)
+
+// Methods served by a running deck
+const (
+	MethodList      = "list"      // -> []SessionInfo
+	MethodSelect    = "select"    // {id} -> SessionInfo (moves the list cursor)
+	MethodOpen      = "open"      // {id} -> SessionInfo (open or focus the tab)
+	MethodFocus     = "focus"     // {id} -> SessionInfo (focus an open tab only)
+	MethodRename    = "rename"    // {id, name} -> SessionInfo
+	MethodMove      = "move"      // {id, group} -> SessionInfo
+	MethodRefresh   = "refresh"   // -> null (re-query the terminal)
+	MethodSubscribe = "subscribe" // -> {"subscribed": true}, then status notifications
+)
+
+// NotifyStatus is the notification method pushed to subscribers on status changes
+const NotifyStatus = "status"
+
+// JSON-RPC error codes
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+	CodeNotFound       = 1 // session or group doesn't exist
+	CodeFailed         = 2 // the operation itself failed (e.g. terminal error)
+)
+
+// SocketPath returns the path of the control socket
+func SocketPath() string {
+	return filepath.Join(session.StorageDir(), "deck.sock")
+}
+
+// SessionParams identifies a session by ID, unique ID prefix or name
+type SessionParams struct {
+	ID    string `json:"id"`
+	Name  string `json:"name,omitempty"`  // rename: new name ("" resets to tab title)
+	Group string `json:"group,omitempty"` // move: group ID, path or name ("" for none)
+}
+
+// SessionInfo is the wire representation of a session
+type SessionInfo struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Status       string    `json:"status"`
+	Group        string    `json:"group,omitempty"`
+	Branch       string    `json:"branch,omitempty"`
+	ProjectPath  string    `json:"project_path"`
+	Pinned       bool      `json:"pinned"`
+	LastActivity time.Time `json:"last_activity"`
+	WindowID     int       `json:"window_id,omitempty"`
+}
+
+// NewSessionInfo converts a session for the wire
+func NewSessionInfo(s *session.Session) SessionInfo {
+	return SessionInfo{
+		ID:           s.ClaudeSessionID,
+		Name:         s.Name,
+		Status:       s.Status.String(),
+		Group:        s.GroupPath,
+		Branch:       s.GitBranch,
+		ProjectPath:  s.ProjectPath,
+		Pinned:       s.Pinned,
+		LastActivity: s.LastAccessedAt,
+		WindowID:     s.KittyWindowID,
+	}
+}
+
+// StatusEvent is pushed to subscribers when a session's status changes
+type StatusEvent struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	OldStatus string `json:"old_status"`
+}
+
+// Error is a JSON-RPC error object
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// message is any JSON-RPC message (request, response or notification)
+type message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  json.RawMessage  `json:"result,omitempty"`
+	Error   *Error           `json:"error,omitempty"`
+}
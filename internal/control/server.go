@@ -0,0 +1,289 @@
+package control
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrAlreadyRunning is returned by Listen when another deck owns the socket
+var ErrAlreadyRunning = errors.New("another deck is already listening")
+
+// requestTimeout bounds how long a client waits for the deck to handle a request
+const requestTimeout = 5 * time.Second
+
+// maxMessageSize bounds a single request line
+const maxMessageSize = 1 << 20
+
+// notifyQueueSize bounds the notifications waiting to be written to one subscriber;
+// a subscriber that falls further behind is disconnected
+const notifyQueueSize = 64
+
+// Request is a call waiting to be handled by the deck's main loop
+type Request struct {
+	Method string
+	Params json.RawMessage
+	reply  chan *message
+}
+
+// Decode unmarshals the request params
+func (r *Request) Decode(v interface{}) error {
+	if len(r.Params) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(r.Params, v); err != nil {
+		return &Error{Code: CodeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+// Reply sends the result (or error) back to the caller
+// Errors that aren't *Error are reported as CodeFailed.
+func (r *Request) Reply(result interface{}, err error) {
+	resp := &message{}
+	if err != nil {
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &Error{Code: CodeFailed, Message: err.Error()}
+		}
+		resp.Error = rpcErr
+	} else {
+		data, mErr := json.Marshal(result)
+		if mErr != nil {
+			resp.Error = &Error{Code: CodeInternalError, Message: mErr.Error()}
+		} else {
+			resp.Result = data
+		}
+	}
+	// reply is buffered, so a client that gave up never blocks the deck
+	select {
+	case r.reply <- resp:
+	default:
+	}
+}
+
+// Server accepts control connections and queues requests for the deck
+type Server struct {
+	path     string
+	listener net.Listener
+	requests chan *Request
+
+	mu          sync.Mutex
+	conns       map[*serverConn]bool
+	subscribers map[*serverConn]bool
+	closed      bool
+}
+
+// serverConn is one client connection (writes are serialized)
+type serverConn struct {
+	conn   net.Conn
+	mu     sync.Mutex
+	notify chan *message // notifications queued for a subscriber (nil until it subscribes)
+}
+
+func (c *serverConn) write(msg *message) error {
+	out := *msg // notifications are shared between subscribers
+	out.JSONRPC = "2.0"
+	data, err := json.Marshal(&out)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(requestTimeout))
+	_, err = c.conn.Write(append(data, '\n'))
+	return err
+}
+
+// sendNotifications writes queued notifications until the queue is closed
+func (c *serverConn) sendNotifications(queue <-chan *message) {
+	for msg := range queue {
+		if err := c.write(msg); err != nil {
+			c.conn.Close() // the read loop drops it
+			return
+		}
+	}
+}
+
+// Listen creates the control socket at path
+// A stale socket left by a crashed deck is replaced; a live one returns ErrAlreadyRunning.
+func Listen(path string) (*Server, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); err == nil {
+		if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+			conn.Close()
+			return nil, ErrAlreadyRunning
+		}
+		os.Remove(path)
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	// Only the user may drive the deck
+	os.Chmod(path, 0600)
+
+	s := &Server{
+		path:        path,
+		listener:    listener,
+		requests:    make(chan *Request),
+		conns:       make(map[*serverConn]bool),
+		subscribers: make(map[*serverConn]bool),
+	}
+	go s.acceptLoop()
+	return s, nil
+}
+
+// Requests returns the channel of requests for the deck to handle
+func (s *Server) Requests() <-chan *Request {
+	return s.requests
+}
+
+// Broadcast queues a notification for all subscribers without waiting for it
+// to be written; a subscriber whose queue is full is disconnected
+func (s *Server) Broadcast(method string, params interface{}) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return
+	}
+	msg := &message{Method: method, Params: data}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range s.subscribers {
+		select {
+		case c.notify <- msg:
+		default:
+			c.conn.Close() // the read loop drops it
+		}
+	}
+}
+
+// Close stops listening, disconnects clients and removes the socket file
+func (s *Server) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	for c := range s.conns {
+		c.conn.Close()
+	}
+	s.mu.Unlock()
+
+	err := s.listener.Close()
+	os.Remove(s.path)
+	return err
+}
+
+func (s *Server) acceptLoop() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		c := &serverConn{conn: conn}
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		s.conns[c] = true
+		s.mu.Unlock()
+		go s.serve(c)
+	}
+}
+
+// serve reads requests from one connection until it closes
+func (s *Server) serve(c *serverConn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, c)
+		if s.subscribers[c] {
+			delete(s.subscribers, c)
+			close(c.notify)
+		}
+		s.mu.Unlock()
+		c.conn.Close()
+	}()
+
+	scanner := bufio.NewScanner(c.conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		resp := s.handle(c, line)
+		if resp == nil {
+			continue // notification - no reply
+		}
+		if err := c.write(resp); err != nil {
+			return
+		}
+	}
+}
+
+// handle processes one request line and returns the response
+func (s *Server) handle(c *serverConn, line []byte) *message {
+	var req message
+	if err := json.Unmarshal(line, &req); err != nil {
+		null := json.RawMessage("null")
+		return &message{ID: &null, Error: &Error{Code: CodeParseError, Message: err.Error()}}
+	}
+	if req.Method == "" {
+		return &message{ID: req.ID, Error: &Error{Code: CodeInvalidRequest, Message: "missing method"}}
+	}
+
+	var resp *message
+	switch req.Method {
+	case MethodSubscribe:
+		s.mu.Lock()
+		if !s.subscribers[c] {
+			s.subscribers[c] = true
+			c.notify = make(chan *message, notifyQueueSize)
+			go c.sendNotifications(c.notify)
+		}
+		s.mu.Unlock()
+		resp = &message{Result: json.RawMessage(`{"subscribed":true}`)}
+	case MethodList, MethodSelect, MethodOpen, MethodFocus, MethodRename, MethodMove, MethodRefresh:
+		resp = s.dispatch(req.Method, req.Params)
+	default:
+		resp = &message{Error: &Error{Code: CodeMethodNotFound, Message: fmt.Sprintf("unknown method %q", req.Method)}}
+	}
+
+	if req.ID == nil {
+		return nil
+	}
+	resp.ID = req.ID
+	return resp
+}
+
+// dispatch hands a request to the deck and waits for its reply
+func (s *Server) dispatch(method string, params json.RawMessage) *message {
+	r := &Request{Method: method, Params: params, reply: make(chan *message, 1)}
+	timeout := time.NewTimer(requestTimeout)
+	defer timeout.Stop()
+
+	select {
+	case s.requests <- r:
+	case <-timeout.C:
+		return &message{Error: &Error{Code: CodeInternalError, Message: "deck is busy"}}
+	}
+	select {
+	case resp := <-r.reply:
+		return resp
+	case <-timeout.C:
+		return &message{Error: &Error{Code: CodeInternalError, Message: "deck did not reply"}}
+	}
+}
@@ -0,0 +1,237 @@
+package control
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// listenTemp starts a server on a short temp path (unix socket paths are length-limited)
+func listenTemp(t *testing.T) (*Server, string) {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "deck")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, "deck.sock")
+	s, err := Listen(path)
+	if err != nil {
+		t.Skipf("unix sockets not available: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s, path
+}
+
+// fakeDeck answers requests the way the deck's main loop would
+func fakeDeck(s *Server) {
+	for req := range s.Requests() {
+		switch req.Method {
+		case MethodList:
+			req.Reply([]SessionInfo{{ID: "abc", Name: "api", Status: "idle"}}, nil)
+		case MethodRename:
+			var p SessionParams
+			if err := req.Decode(&p); err != nil {
+				req.Reply(nil, err)
+				continue
+			}
+			if p.ID != "abc" {
+				req.Reply(nil, &Error{Code: CodeNotFound, Message: "no session"})
+				continue
+			}
+			req.Reply(SessionInfo{ID: p.ID, Name: p.Name}, nil)
+		case MethodFocus:
+			req.Reply(nil, errors.New("session has no open tab"))
+		default:
+			req.Reply(nil, nil)
+		}
+	}
+}
+
+func TestServerCalls(t *testing.T) {
+	s, path := listenTemp(t)
+	go fakeDeck(s)
+
+	c, err := Dial(path)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer c.Close()
+
+	var sessions []SessionInfo
+	if err := c.Call(MethodList, nil, &sessions); err != nil {
+		t.Fatalf("list error = %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].Name != "api" {
+		t.Errorf("list = %+v", sessions)
+	}
+
+	var info SessionInfo
+	if err := c.Call(MethodRename, SessionParams{ID: "abc", Name: "backend"}, &info); err != nil {
+		t.Fatalf("rename error = %v", err)
+	}
+	if info.Name != "backend" {
+		t.Errorf("rename result = %+v", info)
+	}
+
+	tests := []struct {
+		method string
+		params interface{}
+		code   int
+	}{
+		{MethodRename, SessionParams{ID: "zzz"}, CodeNotFound},
+		{MethodRename, "not an object", CodeInvalidParams},
+		{MethodFocus, SessionParams{ID: "abc"}, CodeFailed},
+		{"explode", nil, CodeMethodNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			err := c.Call(tt.method, tt.params, nil)
+			var rpcErr *Error
+			if !errors.As(err, &rpcErr) || rpcErr.Code != tt.code {
+				t.Errorf("error = %v, want code %d", err, tt.code)
+			}
+		})
+	}
+
+	if err := c.Call(MethodRefresh, nil, nil); err != nil {
+		t.Errorf("refresh error = %v", err)
+	}
+}
+
+func TestServerRawProtocol(t *testing.T) {
+	s, path := listenTemp(t)
+	go fakeDeck(s)
+
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	r := bufio.NewReader(conn)
+
+	// A notification (no id) gets no reply; the next request is answered
+	conn.Write([]byte(`{"jsonrpc":"2.0","method":"refresh"}` + "\n"))
+	conn.Write([]byte(`{"jsonrpc":"2.0","id":"x","method":"refresh"}` + "\n"))
+	line, err := r.ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(line, `"id":"x"`) || !strings.Contains(line, `"result":null`) {
+		t.Errorf("unexpected response: %s", line)
+	}
+
+	conn.Write([]byte("{not json\n"))
+	line, _ = r.ReadString('\n')
+	if !strings.Contains(line, `"code":-32700`) {
+		t.Errorf("expected parse error, got: %s", line)
+	}
+}
+
+func TestServerSubscribe(t *testing.T) {
+	s, path := listenTemp(t)
+	go fakeDeck(s)
+
+	c, err := Dial(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	events, err := c.Subscribe()
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	s.Broadcast(NotifyStatus, StatusEvent{ID: "abc", Name: "api", Status: "running", OldStatus: "idle"})
+	select {
+	case e := <-events:
+		if e.ID != "abc" || e.Status != "running" || e.OldStatus != "idle" {
+			t.Errorf("unexpected event: %+v", e)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no status event received")
+	}
+
+	// Closing the server ends the subscription
+	s.Close()
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Error("expected events channel to close")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("events channel not closed")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Close() should remove the socket file")
+	}
+}
+
+func TestServerSlowSubscriber(t *testing.T) {
+	s, path := listenTemp(t)
+
+	// A raw subscriber that never reads its notifications
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.Write([]byte(`{"jsonrpc":"2.0","id":1,"method":"subscribe"}` + "\n"))
+	r := bufio.NewReader(conn)
+	if line, err := r.ReadString('\n'); err != nil || !strings.Contains(line, "subscribed") {
+		t.Fatalf("subscribe reply = %q, %v", line, err)
+	}
+
+	// Broadcast never waits on it, however far behind it falls
+	name := strings.Repeat("x", 4096)
+	start := time.Now()
+	for i := 0; i < 1000; i++ {
+		s.Broadcast(NotifyStatus, StatusEvent{ID: "abc", Name: name, Status: "running"})
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Broadcast() took %v with a stuck subscriber", elapsed)
+	}
+
+	// ...and it's disconnected once its queue overflows
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		if _, err := r.ReadString('\n'); err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				t.Fatal("slow subscriber was not disconnected")
+			}
+			break
+		}
+	}
+}
+
+func TestListenSocketInUse(t *testing.T) {
+	s, path := listenTemp(t)
+
+	if _, err := Listen(path); !errors.Is(err, ErrAlreadyRunning) {
+		t.Errorf("second Listen() error = %v, want ErrAlreadyRunning", err)
+	}
+
+	// A stale socket left by a crashed deck is replaced
+	s.Close()
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	l.Close()
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("expected stale socket file: %v", err)
+	}
+
+	s2, err := Listen(path)
+	if err != nil {
+		t.Fatalf("Listen() over stale socket error = %v", err)
+	}
+	s2.Close()
+}
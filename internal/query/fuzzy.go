@@ -0,0 +1,146 @@
+package query
+
+import (
+	"unicode"
+)
+
+// Fuzzy match scores, after fzf: every matched character scores, gaps between
+// matches cost, and characters at the start of a word or right after another
+// match earn a bonus
+const (
+	scoreMatch        = 16
+	scoreGapStart     = -3
+	scoreGapExtension = -1
+
+	bonusBoundary    = scoreMatch / 2                    // at the start, or after a space, /, -, _, ...
+	bonusCamel       = bonusBoundary + scoreGapExtension // lower to Upper, letter to digit
+	bonusConsecutive = -(scoreGapStart + scoreGapExtension)
+	bonusFirstChar   = 2 // multiplies the bonus of the pattern's first character
+)
+
+// noScore marks a pattern character that can't be matched at a position
+const noScore = -1 << 30
+
+// Fuzzy matches the characters of pattern in order, case-insensitively, with
+// anything in between ("cfgld" matches "config-loader"). It returns the score
+// of the best alignment (higher is better) and the byte offsets in text of the
+// matched characters.
+func Fuzzy(pattern, text string) (score int, positions []int, ok bool) {
+	pat := lowerRunes(pattern)
+	if len(pat) == 0 {
+		return 0, nil, true
+	}
+
+	var runes []rune
+	var offsets []int
+	for i, r := range text {
+		runes = append(runes, r)
+		offsets = append(offsets, i)
+	}
+	lower := lowerRunes(text)
+	if !isSubsequence(pat, lower) {
+		return 0, nil, false
+	}
+
+	n, m := len(pat), len(runes)
+	bonus := make([]int, m)
+	prev := ' '
+	for j, r := range runes {
+		bonus[j] = bonusAt(prev, r)
+		prev = r
+	}
+
+	// best[i*m+j] is the best score for pat[:i+1] with pat[i] matched at j,
+	// run the bonus of the consecutive run it ends and from where pat[i-1] matched
+	best := make([]int, n*m)
+	run := make([]int, n*m)
+	from := make([]int, n*m)
+	for i := 0; i < n; i++ {
+		gapScore, gapFrom := noScore, -1
+		for j := 0; j < m; j++ {
+			k := i*m + j
+			best[k] = noScore
+
+			// Best match of pat[i-1] at least one character back
+			if i > 0 && j >= 2 {
+				gapScore += scoreGapExtension
+				if s := best[k-m-2]; s != noScore && s+scoreGapStart > gapScore {
+					gapScore, gapFrom = s+scoreGapStart, j-2
+				}
+			}
+
+			if lower[j] != pat[i] {
+				continue
+			}
+			if i == 0 {
+				best[k], run[k], from[k] = scoreMatch+bonus[j]*bonusFirstChar, bonus[j], -1
+				continue
+			}
+			if gapFrom >= 0 {
+				best[k], run[k], from[k] = gapScore+scoreMatch+bonus[j], bonus[j], gapFrom
+			}
+			if j > 0 && best[k-m-1] != noScore {
+				s := best[k-m-1] + scoreMatch + max(bonus[j], run[k-m-1], bonusConsecutive)
+				if s >= best[k] {
+					best[k], run[k], from[k] = s, max(bonus[j], run[k-m-1]), j-1
+				}
+			}
+		}
+	}
+
+	end := -1
+	score = noScore
+	for j := 0; j < m; j++ {
+		if s := best[(n-1)*m+j]; s > score {
+			score, end = s, j
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+
+	positions = make([]int, n)
+	for i, j := n-1, end; i >= 0; i-- {
+		positions[i] = offsets[j]
+		j = from[i*m+j]
+	}
+	return score, positions, true
+}
+
+// bonusAt is the bonus for matching cur when it follows prev
+func bonusAt(prev, cur rune) int {
+	switch {
+	case !isWordRune(cur):
+		return 0
+	case !isWordRune(prev):
+		return bonusBoundary
+	case unicode.IsLower(prev) && unicode.IsUpper(cur),
+		unicode.IsLetter(prev) && unicode.IsDigit(cur):
+		return bonusCamel
+	}
+	return 0
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// lowerRunes lowercases rune by rune, so indexes line up with the original
+func lowerRunes(s string) []rune {
+	runes := []rune(s)
+	for i, r := range runes {
+		runes[i] = unicode.ToLower(r)
+	}
+	return runes
+}
+
+// isSubsequence reports whether pat's runes appear in text in order
+func isSubsequence(pat, text []rune) bool {
+	i := 0
+	for _, r := range text {
+		if i < len(pat) && r == pat[i] {
+			i++
+		}
+	}
+	return i == len(pat)
+}
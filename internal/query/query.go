@@ -0,0 +1,468 @@
+// Package query parses the session list filter into a predicate tree.
+//
+// A query is words and field:value terms, all of which have to match:
+//
+//	api project:backend branch:feat/* status:waiting pinned:yes after:2026-09-01 msgs:>50
+//
+// Words match fuzzily (see Fuzzy) against a session's name, title, folder or
+// branch; quoted words match the name exactly. Terms can be negated with a
+// leading "-" (or NOT), combined with OR and grouped with parentheses; values
+// with spaces are quoted. Text values match anywhere, case-insensitively,
+// unless they contain a glob (*, ?, [...]), in which case the whole value has
+// to match.
+package query
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hadar/claude-deck/internal/session"
+)
+
+// Fields lists the fields a query can use
+var Fields = []string{"name", "project", "branch", "status", "group", "pinned", "after", "before", "msgs", "id"}
+
+// now is the time relative dates (after:7d) count back from
+var now = time.Now
+
+// Target is a row of the session list
+type Target struct {
+	Name    string
+	Session *session.Session // nil for group rows, which only match words
+}
+
+// Node is a parsed query
+type Node interface {
+	Match(t Target) bool
+	String() string
+}
+
+// Parse parses a query; an empty query gives a nil Node
+func Parse(q string) (Node, error) {
+	p := &parser{tokens: tokenize(q)}
+	if len(p.tokens) == 0 {
+		return nil, nil
+	}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok, ok := p.peek(); ok {
+		return nil, fmt.Errorf("unexpected %q", tok.text)
+	}
+	return node, nil
+}
+
+// token is a word, quoted text or parenthesis
+type token struct {
+	text   string
+	quoted bool // all of it was in quotes (never an operator or field)
+}
+
+// tokenize splits a query at spaces and parentheses, keeping quoted text together
+func tokenize(q string) []token {
+	var tokens []token
+	for i := 0; i < len(q); {
+		switch c := q[i]; {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, token{text: string(c)})
+			i++
+		default:
+			var b strings.Builder
+			quoted := c == '"'
+			for i < len(q) && q[i] != ' ' && q[i] != '\t' && q[i] != '(' && q[i] != ')' {
+				if q[i] != '"' {
+					b.WriteByte(q[i])
+					i++
+					continue
+				}
+				// Quoted text runs to the closing quote (or the end)
+				end := strings.IndexByte(q[i+1:], '"')
+				if end < 0 {
+					end = len(q) - i - 1
+				}
+				b.WriteString(q[i+1 : i+1+end])
+				i = min(i+end+2, len(q))
+			}
+			tokens = append(tokens, token{text: b.String(), quoted: quoted})
+		}
+	}
+	return tokens
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func isOperator(tok token, op string) bool {
+	return !tok.quoted && tok.text == op
+}
+
+// parseOr parses terms joined by OR
+func (p *parser) parseOr() (Node, error) {
+	var nodes orNode
+	for {
+		node, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+		tok, ok := p.peek()
+		if !ok || !(isOperator(tok, "OR") || isOperator(tok, "|")) {
+			break
+		}
+		p.pos++
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return nodes, nil
+}
+
+// parseAnd parses terms up to an OR, a closing parenthesis or the end
+func (p *parser) parseAnd() (Node, error) {
+	var nodes andNode
+	for {
+		tok, ok := p.peek()
+		if !ok || isOperator(tok, ")") || isOperator(tok, "OR") || isOperator(tok, "|") {
+			break
+		}
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	switch len(nodes) {
+	case 0:
+		return nil, fmt.Errorf("missing term")
+	case 1:
+		return nodes[0], nil
+	}
+	return nodes, nil
+}
+
+// parseUnary parses a negated term, a group in parentheses or a single term
+func (p *parser) parseUnary() (Node, error) {
+	tok, _ := p.peek()
+	p.pos++
+	switch {
+	case isOperator(tok, "NOT"):
+		if _, ok := p.peek(); !ok {
+			return nil, fmt.Errorf("NOT needs a term")
+		}
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{node}, nil
+	case isOperator(tok, "("):
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing, ok := p.peek(); !ok || !isOperator(closing, ")") {
+			return nil, fmt.Errorf("missing )")
+		}
+		p.pos++
+		return node, nil
+	case !tok.quoted && len(tok.text) > 1 && tok.text[0] == '-':
+		node, err := parseTerm(token{text: tok.text[1:]})
+		if err != nil {
+			return nil, err
+		}
+		return notNode{node}, nil
+	}
+	return parseTerm(tok)
+}
+
+// parseTerm parses a word or field:value
+func parseTerm(tok token) (Node, error) {
+	field, value, ok := strings.Cut(tok.text, ":")
+	if tok.quoted || !ok {
+		if !tok.quoted && !strings.ContainsAny(tok.text, "*?[") {
+			return fuzzyNode{strings.ToLower(tok.text)}, nil
+		}
+		return newTextNode("name", tok.text, func(t Target) string { return t.Name })
+	}
+	field = strings.ToLower(field)
+	if value == "" {
+		return nil, fmt.Errorf("%s: needs a value", field)
+	}
+
+	switch field {
+	case "name":
+		return newTextNode(field, value, func(t Target) string { return t.Name })
+	case "project":
+		return newTextNode(field, value, sessionText(func(s *session.Session) string { return s.ProjectPath }))
+	case "branch":
+		return newTextNode(field, value, sessionText(func(s *session.Session) string { return s.GitBranch }))
+	case "group":
+		return newTextNode(field, value, sessionText(func(s *session.Session) string { return s.GroupPath }))
+	case "id":
+		return newTextNode(field, value, sessionText(func(s *session.Session) string { return s.ClaudeSessionID }))
+	case "status":
+		if strings.EqualFold(value, "active") {
+			return fieldNode{field, value, func(s *session.Session) bool { return s.Status.IsActive() }}, nil
+		}
+		if strings.EqualFold(value, "waiting") {
+			return fieldNode{field, value, func(s *session.Session) bool { return s.Status.IsWaiting() }}, nil
+		}
+		return newTextNode(field, value, sessionText(func(s *session.Session) string { return s.Status.String() }))
+	case "pinned":
+		pinned, err := parseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("pinned: %w", err)
+		}
+		return fieldNode{field, value, func(s *session.Session) bool { return s.Pinned == pinned }}, nil
+	case "after", "before":
+		t, err := parseDate(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", field, err)
+		}
+		if field == "after" {
+			return fieldNode{field, value, func(s *session.Session) bool { return !s.LastAccessedAt.Before(t) }}, nil
+		}
+		return fieldNode{field, value, func(s *session.Session) bool { return s.LastAccessedAt.Before(t) }}, nil
+	case "msgs":
+		cmp, err := parseComparison(value)
+		if err != nil {
+			return nil, fmt.Errorf("msgs: %w", err)
+		}
+		return fieldNode{field, value, func(s *session.Session) bool { return cmp(s.MessageCount) }}, nil
+	}
+	return nil, fmt.Errorf("unknown field %q (fields: %s)", field, strings.Join(Fields, ", "))
+}
+
+// sessionText adapts a session field to a Target (empty for group rows)
+func sessionText(get func(*session.Session) string) func(Target) string {
+	return func(t Target) string {
+		if t.Session == nil {
+			return ""
+		}
+		return get(t.Session)
+	}
+}
+
+// parseBool accepts yes/no, true/false, y/n and 1/0
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "yes", "y", "true", "1":
+		return true, nil
+	case "no", "n", "false", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("%q is not yes or no", value)
+}
+
+// parseDate accepts a date (2026-09-01), a date and time (2026-09-01T15:04)
+// or a time ago (90m, 12h, 7d, 2w)
+func parseDate(value string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02", "2006-01-02T15:04"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	units := map[byte]time.Duration{'m': time.Minute, 'h': time.Hour, 'd': 24 * time.Hour, 'w': 7 * 24 * time.Hour}
+	if unit, ok := units[value[len(value)-1]]; ok {
+		if n, err := strconv.Atoi(value[:len(value)-1]); err == nil && n >= 0 {
+			return now().Add(-time.Duration(n) * unit), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a date (2026-09-01) or time ago (7d)", value)
+}
+
+// parseComparison parses a number with an optional >, >=, <, <= or = in front
+func parseComparison(value string) (func(int) bool, error) {
+	op := strings.TrimRight(value, "0123456789")
+	n, err := strconv.Atoi(value[len(op):])
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a number", value)
+	}
+	switch op {
+	case ">":
+		return func(v int) bool { return v > n }, nil
+	case ">=":
+		return func(v int) bool { return v >= n }, nil
+	case "<":
+		return func(v int) bool { return v < n }, nil
+	case "<=":
+		return func(v int) bool { return v <= n }, nil
+	case "", "=":
+		return func(v int) bool { return v == n }, nil
+	}
+	return nil, fmt.Errorf("unknown comparison %q", op)
+}
+
+type andNode []Node
+
+func (n andNode) Match(t Target) bool {
+	for _, node := range n {
+		if !node.Match(t) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n andNode) String() string { return "(and " + joinNodes(n) + ")" }
+
+type orNode []Node
+
+func (n orNode) Match(t Target) bool {
+	for _, node := range n {
+		if node.Match(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n orNode) String() string { return "(or " + joinNodes(n) + ")" }
+
+func joinNodes(nodes []Node) string {
+	parts := make([]string, len(nodes))
+	for i, node := range nodes {
+		parts[i] = node.String()
+	}
+	return strings.Join(parts, " ")
+}
+
+type notNode struct{ node Node }
+
+func (n notNode) Match(t Target) bool { return !n.node.Match(t) }
+
+func (n notNode) String() string { return "(not " + n.node.String() + ")" }
+
+// textNode matches a text field by substring or glob
+type textNode struct {
+	field   string
+	pattern string // lowercase
+	glob    bool
+	get     func(Target) string
+}
+
+func newTextNode(field, value string, get func(Target) string) (Node, error) {
+	pattern := strings.ToLower(value)
+	glob := strings.ContainsAny(pattern, "*?[")
+	if glob {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("%s: bad pattern %q", field, value)
+		}
+	}
+	return textNode{field, pattern, glob, get}, nil
+}
+
+func (n textNode) Match(t Target) bool {
+	value := strings.ToLower(n.get(t))
+	if !n.glob {
+		return strings.Contains(value, n.pattern)
+	}
+	if ok, _ := path.Match(n.pattern, value); ok {
+		return true
+	}
+	// Paths also match by their last element (project:api*)
+	ok, _ := path.Match(n.pattern, path.Base(value))
+	return ok && value != ""
+}
+
+func (n textNode) String() string { return fmt.Sprintf("%s:%q", n.field, n.pattern) }
+
+// fuzzyNode matches a word fuzzily against the name, title, folder and branch
+type fuzzyNode struct{ pattern string }
+
+func (n fuzzyNode) Match(t Target) bool {
+	_, ok := n.score(t)
+	return ok
+}
+
+// score is the best score over the fields the word matches
+func (n fuzzyNode) score(t Target) (int, bool) {
+	fields := []string{t.Name}
+	if s := t.Session; s != nil {
+		fields = append(fields, s.Title, s.FolderName(), s.GitBranch)
+	}
+	best, matched := 0, false
+	for _, field := range fields {
+		if score, _, ok := Fuzzy(n.pattern, field); ok && (!matched || score > best) {
+			best, matched = score, true
+		}
+	}
+	return best, matched
+}
+
+func (n fuzzyNode) String() string { return fmt.Sprintf("fuzzy:%q", n.pattern) }
+
+// Score ranks a target that matches a query by its fuzzy words: the sum of
+// each word's best score (higher is better). Queries without words score 0.
+func Score(n Node, t Target) int {
+	switch n := n.(type) {
+	case andNode:
+		total := 0
+		for _, node := range n {
+			total += Score(node, t)
+		}
+		return total
+	case orNode:
+		best, matched := 0, false
+		for _, node := range n {
+			if !node.Match(t) {
+				continue
+			}
+			if score := Score(node, t); !matched || score > best {
+				best, matched = score, true
+			}
+		}
+		return best
+	case fuzzyNode:
+		score, _ := n.score(t)
+		return score
+	}
+	return 0
+}
+
+// Words returns the fuzzy words of a query, leaving out negated ones
+func Words(n Node) []string {
+	switch n := n.(type) {
+	case andNode:
+		return nodeWords(n)
+	case orNode:
+		return nodeWords(n)
+	case fuzzyNode:
+		return []string{n.pattern}
+	}
+	return nil
+}
+
+func nodeWords(nodes []Node) []string {
+	var words []string
+	for _, node := range nodes {
+		words = append(words, Words(node)...)
+	}
+	return words
+}
+
+// fieldNode matches a session by a parsed value; group rows never match
+type fieldNode struct {
+	field string
+	value string
+	match func(*session.Session) bool
+}
+
+func (n fieldNode) Match(t Target) bool { return t.Session != nil && n.match(t.Session) }
+
+func (n fieldNode) String() string { return n.field + ":" + n.value }
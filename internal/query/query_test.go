@@ -0,0 +1,216 @@
+package query
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hadar/claude-deck/internal/session"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", "<nil>"},
+		{"api", `fuzzy:"api"`},
+		{"API*", `name:"api*"`},
+		{"api project:Backend", `(and fuzzy:"api" project:"backend")`},
+		{`project:"my app" -pinned:yes`, `(and project:"my app" (not pinned:yes))`},
+		{"status:waiting OR status:running msgs:>50", `(or status:waiting (and status:"running" msgs:>50))`},
+		{"(a | b) NOT c", `(and (or fuzzy:"a" fuzzy:"b") (not fuzzy:"c"))`},
+		{`"status:waiting"`, `name:"status:waiting"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			node, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			got := "<nil>"
+			if node != nil {
+				got = node.String()
+			}
+			if got != tt.want {
+				t.Errorf("Parse() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, q := range []string{"foo:bar", "project:", "msgs:>x", "msgs:!5", "pinned:maybe", "after:yesterday", "(api", "api )", "a OR", "NOT", "branch:[x"} {
+		if _, err := Parse(q); err == nil {
+			t.Errorf("Parse(%q) should fail", q)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	now = func() time.Time { return time.Date(2026, 10, 1, 12, 0, 0, 0, time.Local) }
+	defer func() { now = time.Now }()
+
+	s := &session.Session{
+		Name:           "Fix login",
+		ProjectPath:    "/work/api-server",
+		GitBranch:      "feat/login",
+		GroupPath:      "work/backend",
+		Pinned:         true,
+		Status:         session.StatusWaiting,
+		LastAccessedAt: time.Date(2026, 9, 28, 9, 0, 0, 0, time.Local),
+		MessageCount:   60,
+	}
+	row := Target{Name: s.Name, Session: s}
+	group := Target{Name: "backend"}
+
+	tests := []struct {
+		query string
+		row   bool
+		group bool
+	}{
+		{"login", true, false},
+		{"fxlgn", true, false},
+		{"apisrv", true, false},
+		{"feat", true, false},
+		{"bcknd", false, true},
+		{`"fxlgn"`, false, false},
+		{"project:api", true, false},
+		{"project:api*", true, false},
+		{"project:web", false, false},
+		{"branch:feat/*", true, false},
+		{"branch:fix/*", false, false},
+		{"status:waiting", true, false},
+		{"status:active", true, false},
+		{"group:backend", true, false},
+		{"pinned:yes", true, false},
+		{"pinned:no", false, false},
+		{"after:2026-09-01", true, false},
+		{"before:2026-09-01", false, false},
+		{"after:7d", true, false},
+		{"after:2d", false, false},
+		{"msgs:>50", true, false},
+		{"msgs:<=50", false, false},
+		{"msgs:60", true, false},
+		{"-pinned:yes", false, true},
+		{"login OR backend", true, true},
+		{"login status:idle", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			node, err := Parse(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := node.Match(row); got != tt.row {
+				t.Errorf("session Match() = %v, want %v", got, tt.row)
+			}
+			if got := node.Match(group); got != tt.group {
+				t.Errorf("group Match() = %v, want %v", got, tt.group)
+			}
+		})
+	}
+}
+
+func TestMatchWaiting(t *testing.T) {
+	tests := []struct {
+		status session.Status
+		want   bool
+	}{
+		{session.StatusWaiting, true},
+		{session.StatusNeedsPermission, true},
+		{session.StatusAwaitingReply, true},
+		{session.StatusErrored, true},
+		{session.StatusRunning, false},
+		{session.StatusIdle, false},
+	}
+	node, err := Parse("status:waiting")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		s := &session.Session{Status: tt.status}
+		if got := node.Match(Target{Session: s}); got != tt.want {
+			t.Errorf("status:waiting on %s = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestFuzzy(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		text      string
+		ok        bool
+		positions []int
+	}{
+		{"", "anything", true, nil},
+		{"cfg", "config", true, []int{0, 3, 5}},
+		{"cl", "config-loader", true, []int{0, 7}},
+		{"CL", "config-loader", true, []int{0, 7}},
+		{"ld", "config-loader", true, []int{7, 10}},
+		{"apis", "my api-server", true, []int{3, 4, 5, 7}},
+		{"gc", "getConfig", true, []int{0, 3}},
+		{"lc", "config", false, nil},
+		{"xyz", "config", false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+"/"+tt.text, func(t *testing.T) {
+			_, positions, ok := Fuzzy(tt.pattern, tt.text)
+			if ok != tt.ok {
+				t.Fatalf("Fuzzy() ok = %v, want %v", ok, tt.ok)
+			}
+			if fmt.Sprint(positions) != fmt.Sprint(tt.positions) {
+				t.Errorf("Fuzzy() positions = %v, want %v", positions, tt.positions)
+			}
+		})
+	}
+}
+
+func TestFuzzyRanking(t *testing.T) {
+	// Each pattern should score its first text above the second
+	tests := []struct {
+		pattern       string
+		better, worse string
+	}{
+		{"api", "api-server", "a-p-i"},    // consecutive
+		{"srv", "api-srv", "sxrxv"},       // consecutive
+		{"ls", "login-server", "illness"}, // word starts
+		{"gc", "getConfig", "gxcxxxx"},    // camel case
+	}
+	for _, tt := range tests {
+		better, _, ok1 := Fuzzy(tt.pattern, tt.better)
+		worse, _, ok2 := Fuzzy(tt.pattern, tt.worse)
+		if !ok1 || !ok2 {
+			t.Fatalf("%q should match %q and %q", tt.pattern, tt.better, tt.worse)
+		}
+		if better <= worse {
+			t.Errorf("%q: %q scored %d, not above %q (%d)", tt.pattern, tt.better, better, tt.worse, worse)
+		}
+	}
+}
+
+func TestScore(t *testing.T) {
+	exact := Target{Name: "deck", Session: &session.Session{Name: "deck"}}
+	loose := Target{Name: "dev checklist", Session: &session.Session{Name: "dev checklist"}}
+
+	node, err := Parse("deck")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if Score(node, exact) <= Score(node, loose) {
+		t.Errorf("exact name should outrank a scattered match")
+	}
+
+	node, err = Parse("deck -pinned:yes project:x OR dk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(Words(node)); got != "[deck dk]" {
+		t.Errorf("Words() = %s, want [deck dk]", got)
+	}
+	if Score(node, exact) == 0 {
+		t.Errorf("Score() should count the OR branch that matched")
+	}
+}
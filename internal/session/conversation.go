@@ -0,0 +1,106 @@
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// ConversationPageSize is how many messages a page of the transcript viewer holds
+const ConversationPageSize = 200
+
+// ConversationMessage is one message of a full transcript (untruncated)
+// Messages that only carry tool results have no Content or Tools; their
+// Results belong to tool calls in earlier messages.
+type ConversationMessage struct {
+	Offset    int64 // byte offset of its JSONL line
+	Role      string
+	Content   string
+	Timestamp time.Time
+	Tools     []ToolCall
+	Results   map[string]*ToolResult // by tool_use ID
+}
+
+// ConversationPage is a run of messages read from a JSONL
+type ConversationPage struct {
+	Messages []ConversationMessage
+	Next     int64 // offset to read the next page from
+	Done     bool  // the end of the file was reached (more may be appended later)
+}
+
+// ReadConversation reads up to limit user/assistant messages from a JSONL,
+// starting at byte offset from (0 or a previous page's Next).
+// Lines still being written are left for the next read.
+func ReadConversation(path string, from int64, limit int) (ConversationPage, error) {
+	page := ConversationPage{Next: from}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return page, err
+	}
+	defer file.Close()
+	if _, err := file.Seek(from, io.SeekStart); err != nil {
+		return page, err
+	}
+
+	reader := bufio.NewReaderSize(file, 64*1024)
+	for len(page.Messages) < limit {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			// EOF, or a partial last line Claude is still writing
+			page.Done = true
+			return page, nil
+		}
+		offset := page.Next
+		page.Next += int64(len(line))
+
+		if msg, ok := parseConversationLine(line); ok {
+			msg.Offset = offset
+			page.Messages = append(page.Messages, msg)
+		}
+	}
+
+	// Peek so a page that ends exactly at EOF reports it
+	if _, err := reader.Peek(1); err != nil {
+		page.Done = true
+	}
+	return page, nil
+}
+
+// parseConversationLine returns the message in a JSONL line, if it has text or tools
+func parseConversationLine(line []byte) (ConversationMessage, bool) {
+	var entry JSONLEntry
+	if err := json.Unmarshal(line, &entry); err != nil || entry.Message == nil {
+		return ConversationMessage{}, false
+	}
+	if entry.Message.Role != "user" && entry.Message.Role != "assistant" {
+		return ConversationMessage{}, false
+	}
+
+	parts := entry.Message.GetParts()
+	msg := ConversationMessage{Role: entry.Message.Role, Content: strings.TrimSpace(messageText(parts))}
+	for _, part := range parts {
+		switch part.Type {
+		case "tool_use":
+			msg.Tools = append(msg.Tools, newToolCall(part))
+		case "tool_result":
+			if msg.Results == nil {
+				msg.Results = make(map[string]*ToolResult)
+			}
+			msg.Results[part.ToolUseID] = newToolResult(part)
+		}
+	}
+	if msg.Content == "" && len(msg.Tools) == 0 && len(msg.Results) == 0 {
+		return ConversationMessage{}, false
+	}
+
+	if entry.Timestamp != "" {
+		if t, err := time.Parse(time.RFC3339, entry.Timestamp); err == nil {
+			msg.Timestamp = t
+		}
+	}
+	return msg, true
+}
@@ -0,0 +1,82 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadConversation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	long := strings.Repeat("x", MaxContentLength*2)
+	lines := []string{
+		`{"type":"summary","summary":"skipped"}`,
+		`{"type":"user","message":{"role":"user","content":"first"},"timestamp":"2024-03-15T14:30:00Z"}`,
+		`{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{}}]}}`,
+		`{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"` + long + `"}]}}`,
+		`not json`,
+		`{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"ok"}]}}`,
+		`{"type":"user","message":{"role":"user","content":"third"}}`,
+	}
+	content := strings.Join(lines, "\n") + "\n" + `{"type":"user","message":{"role":"user","content":"partial`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	page, err := ReadConversation(path, 0, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Messages) != 3 || page.Done {
+		t.Fatalf("first page = %d messages, done %v; want 3, not done", len(page.Messages), page.Done)
+	}
+	if page.Messages[0].Content != "first" || page.Messages[0].Timestamp.IsZero() {
+		t.Errorf("first message = %+v", page.Messages[0])
+	}
+	if tools := page.Messages[1].Tools; len(tools) != 1 || tools[0].Name != "Bash" || page.Messages[1].Content != "" {
+		t.Errorf("tool call message = %+v", page.Messages[1])
+	}
+	if page.Messages[2].Content != long {
+		t.Errorf("assistant message truncated to %d chars", len(page.Messages[2].Content))
+	}
+	wantOffset := int64(len(lines[0]) + len(lines[1]) + len(lines[2]) + 3)
+	if page.Messages[2].Offset != wantOffset {
+		t.Errorf("offset = %d, want %d", page.Messages[2].Offset, wantOffset)
+	}
+
+	rest, err := ReadConversation(path, page.Next, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rest.Messages) != 2 || rest.Messages[1].Content != "third" || !rest.Done {
+		t.Fatalf("second page = %+v", rest)
+	}
+	if r := rest.Messages[0].Results["t1"]; r == nil || r.Summary != "1 line" {
+		t.Errorf("tool result = %+v", rest.Messages[0].Results)
+	}
+	// The partial line is left for when it's complete
+	if rest.Next != int64(len(strings.Join(lines, "\n"))+1) {
+		t.Errorf("next = %d, stops inside the partial line", rest.Next)
+	}
+}
+
+func TestReadConversationExactPage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	var b strings.Builder
+	for i := 0; i < 3; i++ {
+		fmt.Fprintf(&b, `{"type":"user","message":{"role":"user","content":"m%d"}}`+"\n", i)
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	page, err := ReadConversation(path, 0, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Messages) != 3 || !page.Done {
+		t.Errorf("got %d messages, done %v; want 3, done", len(page.Messages), page.Done)
+	}
+}
@@ -0,0 +1,194 @@
+package session
+
+import "strings"
+
+// diffContext is how many unchanged lines are kept around each change
+const diffContext = 3
+
+// maxDiffCells bounds the line-diff table (old lines x new lines); bigger edits
+// are shown as a removal followed by an addition
+const maxDiffCells = 1 << 20
+
+// Diff line operations
+const (
+	DiffContext = ' '
+	DiffRemove  = '-'
+	DiffAdd     = '+'
+	DiffGap     = '@' // unchanged lines left out between hunks
+)
+
+// DiffLine is one line of a unified diff
+type DiffLine struct {
+	Op   byte
+	Text string
+}
+
+// FileDiff is the change an Edit, MultiEdit or Write call made to a file
+type FileDiff struct {
+	Path    string
+	Lines   []DiffLine
+	Added   int
+	Removed int
+}
+
+// Diffs returns the file changes of an Edit, MultiEdit or Write call (nil for other tools)
+func (t ToolCall) Diffs() []FileDiff {
+	fields, _ := t.Input.(map[string]any)
+	str := func(m map[string]any, key string) string {
+		s, _ := m[key].(string)
+		return s
+	}
+	path := str(fields, "file_path")
+
+	var lines []DiffLine
+	switch t.Name {
+	case "Edit":
+		lines = diffStrings(str(fields, "old_string"), str(fields, "new_string"))
+	case "MultiEdit":
+		edits, _ := fields["edits"].([]any)
+		for _, e := range edits {
+			edit, _ := e.(map[string]any)
+			if len(lines) > 0 {
+				lines = append(lines, DiffLine{Op: DiffGap})
+			}
+			lines = append(lines, diffStrings(str(edit, "old_string"), str(edit, "new_string"))...)
+		}
+	case "Write":
+		// The old content isn't in the transcript - show the file as written
+		lines = diffStrings("", str(fields, "content"))
+	default:
+		return nil
+	}
+	if path == "" && len(lines) == 0 {
+		return nil
+	}
+
+	diff := FileDiff{Path: path, Lines: lines}
+	for _, l := range lines {
+		switch l.Op {
+		case DiffAdd:
+			diff.Added++
+		case DiffRemove:
+			diff.Removed++
+		}
+	}
+	return []FileDiff{diff}
+}
+
+// diffStrings returns a line diff of two texts, with diffContext lines of context
+func diffStrings(before, after string) []DiffLine {
+	return withContext(diffLines(splitLines(before), splitLines(after)), diffContext)
+}
+
+// splitLines splits text into lines (none for empty text)
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines returns every line of a and b as context, removal or addition,
+// using the longest common subsequence
+func diffLines(a, b []string) []DiffLine {
+	// Common prefix and suffix don't need the table
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var out []DiffLine
+	for _, l := range a[:prefix] {
+		out = append(out, DiffLine{DiffContext, l})
+	}
+	out = append(out, diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, l := range a[len(a)-suffix:] {
+		out = append(out, DiffLine{DiffContext, l})
+	}
+	return out
+}
+
+// diffMiddle diffs the part of two texts between their common prefix and suffix
+func diffMiddle(a, b []string) []DiffLine {
+	var out []DiffLine
+	if len(a)*len(b) > maxDiffCells {
+		for _, l := range a {
+			out = append(out, DiffLine{DiffRemove, l})
+		}
+		for _, l := range b {
+			out = append(out, DiffLine{DiffAdd, l})
+		}
+		return out
+	}
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			out = append(out, DiffLine{DiffContext, a[i]})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			out = append(out, DiffLine{DiffRemove, a[i]})
+			i++
+		default:
+			out = append(out, DiffLine{DiffAdd, b[j]})
+			j++
+		}
+	}
+	return out
+}
+
+// withContext drops unchanged lines further than n from a change, marking gaps
+func withContext(lines []DiffLine, n int) []DiffLine {
+	// Distance from each line to the nearest change
+	dist := make([]int, len(lines))
+	last := -1 << 30
+	for i, l := range lines {
+		if l.Op != DiffContext {
+			last = i
+		}
+		dist[i] = i - last
+	}
+	last = 1 << 30
+	for i := len(lines) - 1; i >= 0; i-- {
+		if lines[i].Op != DiffContext {
+			last = i
+		}
+		dist[i] = min(dist[i], last-i)
+	}
+
+	var out []DiffLine
+	skipped := false
+	for i, l := range lines {
+		if dist[i] > n {
+			skipped = true
+			continue
+		}
+		if skipped && len(out) > 0 {
+			out = append(out, DiffLine{Op: DiffGap})
+		}
+		skipped = false
+		out = append(out, l)
+	}
+	return out
+}
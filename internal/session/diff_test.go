@@ -0,0 +1,75 @@
+package session
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// formatDiff renders diff lines as "<op><text>" joined by "|"
+func formatDiff(lines []DiffLine) string {
+	var parts []string
+	for _, l := range lines {
+		parts = append(parts, string(l.Op)+l.Text)
+	}
+	return strings.Join(parts, "|")
+}
+
+func TestDiffStrings(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   string
+	}{
+		{"change", "a\nb\nc", "a\nB\nc", " a|-b|+B| c"},
+		{"insert", "a\nc", "a\nb\nc", " a|+b| c"},
+		{"delete", "a\nb\nc\n", "a\nc\n", " a|-b| c"},
+		{"new text", "", "x\ny", "+x|+y"},
+		{"removed text", "x", "", "-x"},
+		{"same", "a\nb", "a\nb", ""},
+		{"far context dropped", "1\n2\n3\n4\n5\n6\n7\n8\n9", "1\n2\n3\n4\nfive\n6\n7\n8\n9", " 2| 3| 4|-5|+five| 6| 7| 8"},
+		{"gap between hunks", "a\n1\n2\n3\n4\n5\n6\n7\nb", "A\n1\n2\n3\n4\n5\n6\n7\nB", "-a|+A| 1| 2| 3|@| 5| 6| 7|-b|+B"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatDiff(diffStrings(tt.before, tt.after)); got != tt.want {
+				t.Errorf("diffStrings() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToolCallDiffs(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		added   int
+		removed int
+	}{
+		{"Edit", `{"file_path":"/work/a.go","old_string":"x := 1","new_string":"x := 2"}`, "-x := 1|+x := 2", 1, 1},
+		{"MultiEdit", `{"file_path":"/work/a.go","edits":[{"old_string":"a","new_string":"b"},{"old_string":"c","new_string":""}]}`, "-a|+b|@|-c", 1, 2},
+		{"Write", `{"file_path":"/work/a.go","content":"package a\n"}`, "+package a", 1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var input any
+			json.Unmarshal([]byte(tt.input), &input)
+			diffs := ToolCall{Name: tt.name, Input: input}.Diffs()
+			if len(diffs) != 1 {
+				t.Fatalf("Diffs() = %+v, want one file", diffs)
+			}
+			d := diffs[0]
+			if d.Path != "/work/a.go" || formatDiff(d.Lines) != tt.want || d.Added != tt.added || d.Removed != tt.removed {
+				t.Errorf("Diffs() = %s %q +%d -%d, want %q +%d -%d", d.Path, formatDiff(d.Lines), d.Added, d.Removed, tt.want, tt.added, tt.removed)
+			}
+		})
+	}
+
+	if diffs := (ToolCall{Name: "Bash", Input: map[string]any{"command": "ls"}}).Diffs(); diffs != nil {
+		t.Errorf("Bash Diffs() = %+v, want nil", diffs)
+	}
+}
@@ -1,22 +1,25 @@
 package session
 
 import (
+	"context"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-// ClaudeProjectsDir returns the path to Claude Code's projects directory
+// ClaudeProjectsDir returns the projects directory of the default data root
+// (see ProjectsDirs for all of them)
 func ClaudeProjectsDir() string {
-	home, _ := os.UserHomeDir()
-	return filepath.Join(home, ".claude", "projects")
+	return filepath.Join(DefaultDataRoot(), "projects")
 }
 
 // DecodeProjectPath converts an encoded directory name back to the original path
-// Note: Claude's encoding is lossy (/ _ and - all become -), so this is a fallback
+// Note: Claude's encoding is lossy (everything but letters and digits becomes -),
+// so this is the last fallback of the path resolver (see ProjectDir)
 func DecodeProjectPath(encoded string) string {
 	if encoded == "" {
 		return ""
@@ -32,22 +35,35 @@ type sessionFileInfo struct {
 	hasContent bool
 }
 
+// sessionHeadSize is how much of a JSONL is read for cwd, branch and title
+const sessionHeadSize = 20 * 1024
+
 // GetSessionFileInfo reads info from a JSONL file
 func GetSessionFileInfo(jsonlPath string) sessionFileInfo {
+	return parseSessionHead(readSessionHead(jsonlPath))
+}
+
+// readSessionHead returns the first sessionHeadSize bytes of a JSONL (nil on error)
+func readSessionHead(jsonlPath string) []byte {
 	file, err := os.Open(jsonlPath)
 	if err != nil {
-		return sessionFileInfo{}
+		return nil
 	}
 	defer file.Close()
 
-	// Read first 20KB to find cwd, gitBranch and check for content
-	data := make([]byte, 20*1024)
+	data := make([]byte, sessionHeadSize)
 	n, _ := file.Read(data)
+	return data[:n]
+}
+
+// parseSessionHead finds cwd, gitBranch and whether there's content in the start of a JSONL
+func parseSessionHead(data []byte) sessionFileInfo {
+	n := len(data)
 	if n == 0 {
 		return sessionFileInfo{}
 	}
 
-	content := string(data[:n])
+	content := string(data)
 	info := sessionFileInfo{}
 
 	// Look for "cwd" field in the JSON
@@ -93,30 +109,44 @@ func ProjectPathFromJSONL(jsonlPath string) string {
 	if cwd := GetProjectPathFromJSONL(jsonlPath); cwd != "" {
 		return cwd
 	}
-	// Fall back to decoding directory name (lossy - hyphens in names get confused)
-	dir := filepath.Dir(jsonlPath)
-	encoded := filepath.Base(dir)
-	return DecodeProjectPath(encoded)
+	// Fall back to resolving the directory name (cache, then filesystem, then decoding)
+	return loadPathCache().resolve(filepath.Dir(jsonlPath), nil).Path
 }
 
 // EncodeProjectPath converts a path to the encoded directory name format
 func EncodeProjectPath(path string) string {
-	// Claude replaces both / and _ with -
-	encoded := strings.ReplaceAll(path, "/", "-")
-	encoded = strings.ReplaceAll(encoded, "_", "-")
-	return encoded
+	// Claude replaces everything but ASCII letters and digits with -, one per
+	// UTF-16 code unit (it's JavaScript), so an emoji becomes --
+	var b strings.Builder
+	for _, r := range path {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r >= 0x10000:
+			b.WriteString("--")
+		default:
+			b.WriteByte('-')
+		}
+	}
+	return b.String()
 }
 
-// GetSessionUUIDsAtPath returns all session UUIDs (from JSONL filenames) for a project path
-func GetSessionUUIDsAtPath(projectPath string) []string {
-	projectsDir := ClaudeProjectsDir()
+// sessionFilesAtPath returns the JSONLs for a project path across all data roots
+func sessionFilesAtPath(projectPath string) []string {
 	encodedPath := EncodeProjectPath(projectPath)
-	projectDir := filepath.Join(projectsDir, encodedPath)
-
-	jsonlFiles, err := filepath.Glob(filepath.Join(projectDir, "*.jsonl"))
-	if err != nil {
-		return nil
+	var files []string
+	for _, projectsDir := range ProjectsDirs() {
+		jsonlFiles, err := filepath.Glob(filepath.Join(projectsDir, encodedPath, "*.jsonl"))
+		if err == nil {
+			files = append(files, jsonlFiles...)
+		}
 	}
+	return files
+}
+
+// GetSessionUUIDsAtPath returns all session UUIDs (from JSONL filenames) for a project path
+func GetSessionUUIDsAtPath(projectPath string) []string {
+	jsonlFiles := sessionFilesAtPath(projectPath)
 
 	var uuids []string
 	for _, jsonlPath := range jsonlFiles {
@@ -130,14 +160,7 @@ func GetSessionUUIDsAtPath(projectPath string) []string {
 
 // FindNewestSessionAtPath returns the UUID of the most recently modified JSONL at a path
 func FindNewestSessionAtPath(projectPath string) string {
-	projectsDir := ClaudeProjectsDir()
-	encodedPath := EncodeProjectPath(projectPath)
-	projectDir := filepath.Join(projectsDir, encodedPath)
-
-	jsonlFiles, err := filepath.Glob(filepath.Join(projectDir, "*.jsonl"))
-	if err != nil {
-		return ""
-	}
+	jsonlFiles := sessionFilesAtPath(projectPath)
 
 	var newestUUID string
 	var newestTime time.Time
@@ -162,15 +185,107 @@ func FindNewestSessionAtPath(projectPath string) string {
 	return newestUUID
 }
 
-// DiscoverSessions scans ~/.claude/projects for Claude Code sessions
+// discoveryBatch is how many sessions discovery finds between progress reports
+const discoveryBatch = 100
+
+// DiscoverSessions scans the projects directory of every data root for Claude Code sessions
+// Metadata read from each JSONL is cached in the discovery index, so only new
+// and changed files are opened.
 func DiscoverSessions() ([]*Session, error) {
-	projectsDir := ClaudeProjectsDir()
+	return discoverSessions(nil)
+}
 
-	if _, err := os.Stat(projectsDir); os.IsNotExist(err) {
-		return nil, nil // No projects directory yet
+// sessionFile is a JSONL found by discovery
+type sessionFile struct {
+	path      string
+	sessionID string
+	dataRoot  string
+}
+
+// discovered is a session found by discovery and the cwd its JSONL recorded
+type discovered struct {
+	session *Session
+	cwd     string
+}
+
+// discoverSessions is DiscoverSessions with progress reports: progress (if set) is
+// called from the calling goroutine with the sessions found so far, every
+// discoveryBatch sessions. Files are read on the worker pool.
+func discoverSessions(progress func([]*Session)) ([]*Session, error) {
+	var files []sessionFile
+	for _, root := range DataRoots() {
+		rootFiles, err := listSessionFiles(root)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, rootFiles...)
 	}
 
+	index := loadIndex()
+	paths := loadPathCache()
+	found := Parallel(context.Background(), len(files), func(i int) discovered {
+		return discoverSession(index, paths, files[i])
+	})
+
 	var sessions []*Session
+	var noCwd []*Session
+	cwds := make(map[string][]string)
+	seen := make(map[string]bool)
+	for d := range found {
+		s := d.session
+		if s == nil {
+			continue
+		}
+		seen[s.JSONLPath] = true
+		sessions = append(sessions, s)
+		if d.cwd == "" {
+			noCwd = append(noCwd, s)
+		} else {
+			dir := filepath.Dir(s.JSONLPath)
+			cwds[dir] = append(cwds[dir], d.cwd)
+		}
+		if progress != nil && len(sessions)%discoveryBatch == 0 {
+			progress(sessions[:len(sessions):len(sessions)])
+		}
+	}
+
+	index.prune(seen)
+	index.save()
+
+	// Sessions that didn't record a cwd take their directory's path, resolved from
+	// the cwds of the other sessions in it (which also teaches the path cache)
+	resolved := make(map[string]ProjectDir)
+	for dir, dirCwds := range cwds {
+		resolved[dir] = paths.resolve(dir, dirCwds)
+	}
+	for _, s := range noCwd {
+		dir := filepath.Dir(s.JSONLPath)
+		pd, ok := resolved[dir]
+		if !ok {
+			pd = paths.resolve(dir, nil)
+			resolved[dir] = pd
+		}
+		if pd.Path != s.ProjectPath {
+			s.ProjectPath = pd.Path
+			s.Name = formatSessionName(pd.Path, s.LastAccessedAt)
+		}
+	}
+	paths.save()
+
+	// Workers finish in any order - keep the result stable
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].JSONLPath < sessions[j].JSONLPath
+	})
+	return sessions, nil
+}
+
+// listSessionFiles lists the session JSONLs under a data root's projects directory
+func listSessionFiles(root string) ([]sessionFile, error) {
+	projectsDir := filepath.Join(root, "projects")
+
+	if _, err := os.Stat(projectsDir); os.IsNotExist(err) {
+		return nil, nil // No projects directory yet
+	}
 
 	// Scan project directories
 	projectEntries, err := os.ReadDir(projectsDir)
@@ -178,12 +293,12 @@ func DiscoverSessions() ([]*Session, error) {
 		return nil, err
 	}
 
+	var files []sessionFile
 	for _, projectEntry := range projectEntries {
 		if !projectEntry.IsDir() {
 			continue
 		}
 
-		projectPath := DecodeProjectPath(projectEntry.Name())
 		projectDir := filepath.Join(projectsDir, projectEntry.Name())
 
 		// Scan for JSONL files (each is a session)
@@ -199,37 +314,47 @@ func DiscoverSessions() ([]*Session, error) {
 			if !isValidUUID(sessionID) {
 				continue
 			}
+			files = append(files, sessionFile{path: jsonlPath, sessionID: sessionID, dataRoot: root})
+		}
+	}
+	return files, nil
+}
 
-			// Get file info for timestamps
-			info, err := os.Stat(jsonlPath)
-			if err != nil {
-				continue
-			}
-
-			// Get session info from JSONL (cwd and content check)
-			fileInfo := GetSessionFileInfo(jsonlPath)
+// discoverSession builds a session for one JSONL (nil if it vanished)
+func discoverSession(index *discoveryIndex, paths *pathCache, file sessionFile) discovered {
+	// Get file info for timestamps
+	info, err := os.Stat(file.path)
+	if err != nil {
+		return discovered{}
+	}
 
-			actualPath := fileInfo.cwd
-			if actualPath == "" {
-				actualPath = projectPath // Fallback to decoded path
-			}
+	// Get session info from JSONL (cached unless the file changed)
+	entry := index.lookup(file.path, info)
 
-			session := &Session{
-				ID:              sessionID, // Use ClaudeSessionID directly as the unique ID
-				Name:            formatSessionName(actualPath, info.ModTime()),
-				ProjectPath:     actualPath,
-				ClaudeSessionID: sessionID,
-				JSONLPath:       jsonlPath,
-				CreatedAt:       info.ModTime(), // Use mtime as approximation
-				LastAccessedAt:  info.ModTime(),
-				GitBranch:       fileInfo.gitBranch, // From Claude's JSONL
-			}
+	actualPath := entry.Cwd
+	if actualPath == "" {
+		actualPath = paths.guess(filepath.Dir(file.path)) // Resolved properly once all cwds are known
+	}
 
-			sessions = append(sessions, session)
-		}
+	createdAt := entry.FirstAt
+	if createdAt.IsZero() {
+		createdAt = info.ModTime() // Use mtime as approximation
 	}
 
-	return sessions, nil
+	return discovered{cwd: entry.Cwd, session: &Session{
+		ID:              file.sessionID, // Use ClaudeSessionID directly as the unique ID
+		Name:            formatSessionName(actualPath, info.ModTime()),
+		ProjectPath:     actualPath,
+		ClaudeSessionID: file.sessionID,
+		JSONLPath:       file.path,
+		CreatedAt:       createdAt,
+		LastAccessedAt:  info.ModTime(),
+		GitBranch:       entry.GitBranch, // From Claude's JSONL
+		Title:           entry.Title,
+		MessageCount:    entry.MessageCount,
+		Usage:           entry.Usage,
+		DataRoot:        file.dataRoot,
+	}}
 }
 
 // isValidUUID checks if a string looks like a UUID
@@ -1,6 +1,7 @@
 package session
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -61,6 +62,16 @@ func TestEncodeProjectPath(t *testing.T) {
 			path: "/Users/hadar/my_project",
 			want: "-Users-hadar-my-project",
 		},
+		{
+			name: "dots and spaces",
+			path: "/Users/hadar/.config/my app.v2",
+			want: "-Users-hadar--config-my-app-v2",
+		},
+		{
+			name: "non-ASCII",
+			path: "/home/zoë/🚀",
+			want: "-home-zo----",
+		},
 		{
 			name: "root",
 			path: "/",
@@ -518,3 +529,32 @@ func TestGetSessionFileInfoHasContentAssistant(t *testing.T) {
 		t.Error("hasContent should be true for assistant message")
 	}
 }
+
+func TestDiscoverSessionsProgress(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	total := discoveryBatch + discoveryBatch/2
+	for i := 0; i < total; i++ {
+		writeTestSessionFile(t, tmpDir, "/work/api", fmt.Sprintf("550e8400-e29b-41d4-a716-%012d", i))
+	}
+
+	var reports []int
+	sessions, err := discoverSessions(func(found []*Session) {
+		reports = append(reports, len(found))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != total {
+		t.Fatalf("got %d sessions, want %d", len(sessions), total)
+	}
+	if len(reports) != 1 || reports[0] != discoveryBatch {
+		t.Errorf("progress reports = %v, want [%d]", reports, discoveryBatch)
+	}
+	for i := 1; i < len(sessions); i++ {
+		if sessions[i-1].JSONLPath > sessions[i].JSONLPath {
+			t.Fatal("sessions should be sorted by path")
+		}
+	}
+}
@@ -7,24 +7,44 @@ import (
 	"time"
 )
 
+// GitBranch is the current branch of a project path
+type GitBranch struct {
+	Path   string
+	Branch string // empty if not a git repository
+}
+
 // RefreshGitBranches updates git branch for all sessions (unique paths only)
 // Called once on startup
 func RefreshGitBranches(sessions []*Session) {
-	// Group by unique path
-	byPath := make(map[string][]*Session)
+	for b := range GitBranches(context.Background(), sessions) {
+		ApplyGitBranch(sessions, b)
+	}
+}
+
+// GitBranches looks up the current branch of each unique session path on the
+// worker pool, sending results as they finish
+func GitBranches(ctx context.Context, sessions []*Session) <-chan GitBranch {
+	seen := make(map[string]bool)
+	var paths []string
 	for _, s := range sessions {
-		if s.ProjectPath != "" {
-			byPath[s.ProjectPath] = append(byPath[s.ProjectPath], s)
+		if s.ProjectPath != "" && !seen[s.ProjectPath] {
+			seen[s.ProjectPath] = true
+			paths = append(paths, s.ProjectPath)
 		}
 	}
+	return Parallel(ctx, len(paths), func(i int) GitBranch {
+		return GitBranch{Path: paths[i], Branch: getCurrentBranch(paths[i])}
+	})
+}
 
-	// Get current branch for each unique path
-	for path, pathSessions := range byPath {
-		branch := getCurrentBranch(path)
-		if branch != "" {
-			for _, s := range pathSessions {
-				s.GitBranch = branch
-			}
+// ApplyGitBranch sets the branch on the sessions at b.Path (kept as is if b has none)
+func ApplyGitBranch(sessions []*Session, b GitBranch) {
+	if b.Branch == "" {
+		return
+	}
+	for _, s := range sessions {
+		if s.ProjectPath == b.Path {
+			s.GitBranch = b.Branch
 		}
 	}
 }
@@ -0,0 +1,339 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// hookMarker tags hook commands installed by the deck so they can be found and removed
+const hookMarker = "# claude-deck"
+
+// HookEvents are the Claude Code hook events the deck listens to
+var HookEvents = []string{"SessionStart", "UserPromptSubmit", "PostToolUse", "Notification", "Stop", "SessionEnd"}
+
+// HooksDir returns the spool directory hook commands write event records into
+func HooksDir() string {
+	return filepath.Join(StorageDir(), "events")
+}
+
+// ClaudeSettingsFiles returns the path to Claude Code's user settings in every
+// data root, so sessions of each config dir report hook events. Roots other than
+// the default are left out until they exist.
+func ClaudeSettingsFiles() []string {
+	var files []string
+	for i, root := range DataRoots() {
+		if i > 0 {
+			if info, err := os.Stat(root); err != nil || !info.IsDir() {
+				continue
+			}
+		}
+		files = append(files, filepath.Join(root, "settings.json"))
+	}
+	return files
+}
+
+// hookCommand returns the shell command Claude runs for each hook event.
+// It copies the event JSON from stdin into a temp file and renames it into place,
+// so the deck never sees a partial record. $PPID (the Claude process) is put in the
+// file name so the deck can tell when a session died without a SessionEnd event.
+func hookCommand() string {
+	dir := HooksDir()
+	return fmt.Sprintf(`d=%q; mkdir -p "$d" && f=$(mktemp "$d/.hook.XXXXXX") && cat > "$f" && mv "$f" "$d/$PPID${f##*.hook}.json" %s`,
+		dir, hookMarker)
+}
+
+// isDeckHook returns true if a hook command was installed by the deck
+func isDeckHook(command string) bool {
+	return strings.HasSuffix(strings.TrimSpace(command), hookMarker)
+}
+
+// readClaudeSettings reads a settings.json, keeping unknown keys intact
+func readClaudeSettings(path string) (map[string]any, error) {
+	settings := make(map[string]any)
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return settings, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(strings.TrimSpace(string(content))) == 0 {
+		return settings, nil
+	}
+	if err := json.Unmarshal(content, &settings); err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", path, err)
+	}
+	return settings, nil
+}
+
+// writeClaudeSettings writes settings.json atomically so Claude never reads a partial file
+func writeClaudeSettings(path string, settings map[string]any) error {
+	content, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(content, '\n'), 0644)
+}
+
+// removeDeckHooks strips the deck's entries from a hooks map
+// Matcher groups left without hooks, and events left without groups, are removed.
+func removeDeckHooks(hooks map[string]any) {
+	for event, value := range hooks {
+		groups, ok := value.([]any)
+		if !ok {
+			continue
+		}
+		var kept []any
+		for _, g := range groups {
+			group, ok := g.(map[string]any)
+			if !ok {
+				kept = append(kept, g)
+				continue
+			}
+			entries, _ := group["hooks"].([]any)
+			var keptEntries []any
+			for _, e := range entries {
+				entry, ok := e.(map[string]any)
+				if cmd, _ := entry["command"].(string); ok && isDeckHook(cmd) {
+					continue
+				}
+				keptEntries = append(keptEntries, e)
+			}
+			if len(keptEntries) == 0 {
+				continue
+			}
+			group["hooks"] = keptEntries
+			kept = append(kept, group)
+		}
+		if len(kept) == 0 {
+			delete(hooks, event)
+		} else {
+			hooks[event] = kept
+		}
+	}
+}
+
+// InstallHooks adds the deck's hook entries to Claude Code's settings in every
+// data root (idempotent)
+func InstallHooks() error {
+	if err := os.MkdirAll(HooksDir(), 0755); err != nil {
+		return err
+	}
+	for _, path := range ClaudeSettingsFiles() {
+		if err := installHooks(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// installHooks adds the deck's hook entries to one settings file
+func installHooks(path string) error {
+	settings, err := readClaudeSettings(path)
+	if err != nil {
+		return err
+	}
+	hooks, _ := settings["hooks"].(map[string]any)
+	if hooks == nil {
+		hooks = make(map[string]any)
+	}
+	removeDeckHooks(hooks)
+
+	command := hookCommand()
+	for _, event := range HookEvents {
+		groups, _ := hooks[event].([]any)
+		hooks[event] = append(groups, map[string]any{
+			"matcher": "",
+			"hooks":   []any{map[string]any{"type": "command", "command": command}},
+		})
+	}
+	settings["hooks"] = hooks
+	return writeClaudeSettings(path, settings)
+}
+
+// UninstallHooks removes the deck's hook entries from every data root's settings,
+// leaving other hooks untouched
+func UninstallHooks() error {
+	for _, path := range ClaudeSettingsFiles() {
+		if err := uninstallHooks(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// uninstallHooks removes the deck's hook entries from one settings file
+func uninstallHooks(path string) error {
+	settings, err := readClaudeSettings(path)
+	if err != nil {
+		return err
+	}
+	hooks, _ := settings["hooks"].(map[string]any)
+	if hooks == nil {
+		return nil
+	}
+	removeDeckHooks(hooks)
+	if len(hooks) == 0 {
+		delete(settings, "hooks")
+	}
+	return writeClaudeSettings(path, settings)
+}
+
+// HooksInstalled returns true if the deck's hooks are present in any data root's settings
+func HooksInstalled() bool {
+	for _, path := range ClaudeSettingsFiles() {
+		if HooksInstalledIn(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// HooksInstalledIn returns true if the deck's hooks are present in a settings file
+func HooksInstalledIn(path string) bool {
+	settings, err := readClaudeSettings(path)
+	if err != nil {
+		return false
+	}
+	hooks, _ := settings["hooks"].(map[string]any)
+	for _, value := range hooks {
+		groups, _ := value.([]any)
+		for _, g := range groups {
+			group, _ := g.(map[string]any)
+			entries, _ := group["hooks"].([]any)
+			for _, e := range entries {
+				entry, _ := e.(map[string]any)
+				if cmd, _ := entry["command"].(string); isDeckHook(cmd) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// HookEvent is an event record written by a hook command
+type HookEvent struct {
+	SessionID        string `json:"session_id"`
+	EventName        string `json:"hook_event_name"`
+	Cwd              string `json:"cwd,omitempty"`
+	TranscriptPath   string `json:"transcript_path,omitempty"`
+	Message          string `json:"message,omitempty"`           // Notification text
+	NotificationType string `json:"notification_type,omitempty"` // e.g. permission_prompt, idle_prompt
+
+	PID int       `json:"-"` // Claude process that ran the hook (from the file name)
+	At  time.Time `json:"-"` // when the record was written
+}
+
+// Status returns the session status implied by the event
+func (e HookEvent) Status() Status {
+	switch e.EventName {
+	case "UserPromptSubmit", "PostToolUse":
+		return StatusRunning
+	case "Notification":
+		if e.NotificationType == "permission_prompt" || strings.Contains(strings.ToLower(e.Message), "permission") {
+			return StatusNeedsPermission
+		}
+		return StatusAwaitingReply
+	case "Stop":
+		return StatusAwaitingReply
+	case "SessionEnd":
+		return StatusIdle
+	default: // SessionStart
+		return StatusWaiting
+	}
+}
+
+// ReadHookEvents reads and removes all event records from the spool directory, oldest first
+func ReadHookEvents() ([]HookEvent, error) {
+	dir := HooksDir()
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var events []HookEvent
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, ".json") {
+			continue // skip temp files still being written
+		}
+		path := filepath.Join(dir, name)
+		info, err := entry.Info()
+		content, readErr := os.ReadFile(path)
+		os.Remove(path)
+		if err != nil || readErr != nil {
+			continue
+		}
+
+		var event HookEvent
+		if err := json.Unmarshal(content, &event); err != nil || event.SessionID == "" {
+			continue
+		}
+		if pid, _, ok := strings.Cut(name, "."); ok {
+			event.PID, _ = strconv.Atoi(pid)
+		}
+		event.At = info.ModTime()
+		events = append(events, event)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].At.Before(events[j].At)
+	})
+	return events, nil
+}
+
+// hookStates holds the latest hook event per Claude session ID
+var (
+	hookStatesMu sync.Mutex
+	hookStates   = make(map[string]HookEvent)
+)
+
+// ProcessHookEvents drains the spool directory into the per-session hook state
+// Returns the number of events processed
+func ProcessHookEvents() int {
+	events, err := ReadHookEvents()
+	if err != nil {
+		return 0
+	}
+	hookStatesMu.Lock()
+	defer hookStatesMu.Unlock()
+	for _, e := range events {
+		hookStates[e.SessionID] = e
+	}
+	return len(events)
+}
+
+// ForgetHookState drops the hook state for a session (e.g. after killing it)
+func ForgetHookState(claudeSessionID string) {
+	hookStatesMu.Lock()
+	defer hookStatesMu.Unlock()
+	delete(hookStates, claudeSessionID)
+}
+
+// hookStatus returns the status reported by hooks for a session, if any
+// detected is the status inferred from the terminal; it's kept when hooks know nothing,
+// or when the session has no tab and the Claude process that sent the last event is gone.
+func hookStatus(claudeSessionID string, detected Status) Status {
+	hookStatesMu.Lock()
+	event, ok := hookStates[claudeSessionID]
+	hookStatesMu.Unlock()
+	if !ok {
+		return detected
+	}
+	if detected == StatusIdle && event.PID > 0 && !processAlive(event.PID) {
+		ForgetHookState(claudeSessionID)
+		return detected
+	}
+	return event.Status()
+}
@@ -0,0 +1,244 @@
+package session
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func readSettingsFile(t *testing.T, path string) map[string]any {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var settings map[string]any
+	if err := json.Unmarshal(content, &settings); err != nil {
+		t.Fatal(err)
+	}
+	return settings
+}
+
+func TestInstallUninstallHooks(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	// Existing settings and a user's own hook must survive
+	existing := `{
+  "model": "opus",
+  "hooks": {
+    "Stop": [{"matcher": "", "hooks": [{"type": "command", "command": "notify-send done"}]}]
+  }
+}`
+	settingsFile := ClaudeSettingsFiles()[0]
+	os.MkdirAll(filepath.Dir(settingsFile), 0755)
+	if err := os.WriteFile(settingsFile, []byte(existing), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if HooksInstalled() {
+		t.Fatal("HooksInstalled() should be false before install")
+	}
+	// Installing twice must not duplicate entries
+	for i := 0; i < 2; i++ {
+		if err := InstallHooks(); err != nil {
+			t.Fatalf("InstallHooks() error = %v", err)
+		}
+	}
+	if !HooksInstalled() {
+		t.Fatal("HooksInstalled() should be true after install")
+	}
+
+	settings := readSettingsFile(t, settingsFile)
+	if settings["model"] != "opus" {
+		t.Errorf("unrelated settings were lost: %v", settings)
+	}
+	hooks := settings["hooks"].(map[string]any)
+	for _, event := range HookEvents {
+		groups, _ := hooks[event].([]any)
+		deck := 0
+		for _, g := range groups {
+			for _, e := range g.(map[string]any)["hooks"].([]any) {
+				if isDeckHook(e.(map[string]any)["command"].(string)) {
+					deck++
+				}
+			}
+		}
+		if deck != 1 {
+			t.Errorf("%s: expected 1 deck hook, got %d", event, deck)
+		}
+	}
+	if stop := hooks["Stop"].([]any); len(stop) != 2 {
+		t.Errorf("Stop: expected user hook + deck hook, got %v", stop)
+	}
+
+	if err := UninstallHooks(); err != nil {
+		t.Fatalf("UninstallHooks() error = %v", err)
+	}
+	if HooksInstalled() {
+		t.Error("HooksInstalled() should be false after uninstall")
+	}
+	settings = readSettingsFile(t, settingsFile)
+	hooks = settings["hooks"].(map[string]any)
+	if len(hooks) != 1 {
+		t.Errorf("expected only the user's Stop hook to remain, got %v", hooks)
+	}
+	stop := hooks["Stop"].([]any)
+	cmd := stop[0].(map[string]any)["hooks"].([]any)[0].(map[string]any)["command"]
+	if len(stop) != 1 || cmd != "notify-send done" {
+		t.Errorf("user hook changed: %v", stop)
+	}
+}
+
+func TestInstallHooksNoSettingsFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if err := InstallHooks(); err != nil {
+		t.Fatalf("InstallHooks() error = %v", err)
+	}
+	if err := UninstallHooks(); err != nil {
+		t.Fatalf("UninstallHooks() error = %v", err)
+	}
+	if settings := readSettingsFile(t, ClaudeSettingsFiles()[0]); len(settings) != 0 {
+		t.Errorf("expected empty settings after uninstall, got %v", settings)
+	}
+}
+
+func TestInstallHooksAllRoots(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	work := filepath.Join(home, ".claude-work")
+	os.MkdirAll(work, 0755)
+	t.Setenv(DataRootsEnv, work+string(filepath.ListSeparator)+filepath.Join(home, ".claude-missing"))
+
+	files := ClaudeSettingsFiles()
+	if len(files) != 2 || files[1] != filepath.Join(work, "settings.json") {
+		t.Fatalf("ClaudeSettingsFiles() = %v, want the default and existing extra root", files)
+	}
+	if err := InstallHooks(); err != nil {
+		t.Fatalf("InstallHooks() error = %v", err)
+	}
+	for _, path := range files {
+		if !HooksInstalledIn(path) {
+			t.Errorf("hooks not installed in %s", path)
+		}
+	}
+
+	// Removing them from one root still reports them installed elsewhere
+	os.Remove(files[0])
+	if !HooksInstalled() {
+		t.Error("HooksInstalled() should be true while any root has them")
+	}
+	if err := UninstallHooks(); err != nil {
+		t.Fatalf("UninstallHooks() error = %v", err)
+	}
+	if HooksInstalled() {
+		t.Error("HooksInstalled() should be false after uninstall")
+	}
+}
+
+func TestHookCommand(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	t.Setenv("HOME", t.TempDir())
+
+	// Run the installed command the way Claude does: event JSON on stdin
+	cmd := exec.Command("sh", "-c", hookCommand())
+	cmd.Stdin = strings.NewReader(`{"session_id":"abc","hook_event_name":"Stop"}`)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("hook command failed: %v: %s", err, out)
+	}
+
+	events, err := ReadHookEvents()
+	if err != nil {
+		t.Fatalf("ReadHookEvents() error = %v", err)
+	}
+	if len(events) != 1 || events[0].SessionID != "abc" || events[0].EventName != "Stop" {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	// $PPID of the hook shell is this test process
+	if events[0].PID != os.Getpid() {
+		t.Errorf("PID = %d, want %d", events[0].PID, os.Getpid())
+	}
+
+	// Records are consumed
+	entries, _ := os.ReadDir(HooksDir())
+	if len(entries) != 0 {
+		t.Errorf("expected spool to be empty, got %d entries", len(entries))
+	}
+}
+
+func TestHookEventStatus(t *testing.T) {
+	tests := []struct {
+		event HookEvent
+		want  Status
+	}{
+		{HookEvent{EventName: "SessionStart"}, StatusWaiting},
+		{HookEvent{EventName: "UserPromptSubmit"}, StatusRunning},
+		{HookEvent{EventName: "PostToolUse"}, StatusRunning},
+		{HookEvent{EventName: "Notification", Message: "Claude needs your permission to use Bash"}, StatusNeedsPermission},
+		{HookEvent{EventName: "Notification", NotificationType: "permission_prompt"}, StatusNeedsPermission},
+		{HookEvent{EventName: "Notification", Message: "Claude is waiting for your input"}, StatusAwaitingReply},
+		{HookEvent{EventName: "Stop"}, StatusAwaitingReply},
+		{HookEvent{EventName: "SessionEnd"}, StatusIdle},
+	}
+	for _, tt := range tests {
+		t.Run(tt.event.EventName+" "+tt.event.Message, func(t *testing.T) {
+			if got := tt.event.Status(); got != tt.want {
+				t.Errorf("Status() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHookStatus(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	os.MkdirAll(HooksDir(), 0755)
+
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(HooksDir(), name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	alive := strconv.Itoa(os.Getpid())
+	write(alive+".aaaaaa.json", `{"session_id":"live","hook_event_name":"Notification","message":"Claude needs your permission to use Bash"}`)
+	write("999999999.bbbbbb.json", `{"session_id":"dead","hook_event_name":"UserPromptSubmit"}`)
+	write(".hook.cccccc", `{"session_id":"partial"`) // still being written
+	write(alive+".dddddd.json", `not json`)
+	t.Cleanup(func() {
+		ForgetHookState("live")
+		ForgetHookState("dead")
+	})
+
+	if n := ProcessHookEvents(); n != 2 {
+		t.Errorf("ProcessHookEvents() = %d, want 2", n)
+	}
+
+	tests := []struct {
+		name     string
+		id       string
+		detected Status
+		want     Status
+	}{
+		{"hook overrides tab status", "live", StatusWaiting, StatusNeedsPermission},
+		{"live process without tab", "live", StatusIdle, StatusNeedsPermission},
+		{"dead process without tab", "dead", StatusIdle, StatusIdle},
+		{"no hook state", "unknown", StatusRunning, StatusRunning},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hookStatus(tt.id, tt.detected); got != tt.want {
+				t.Errorf("hookStatus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	ForgetHookState("live")
+	if got := hookStatus("live", StatusWaiting); got != StatusWaiting {
+		t.Errorf("after ForgetHookState, hookStatus() = %v, want %v", got, StatusWaiting)
+	}
+}
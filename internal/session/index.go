@@ -0,0 +1,195 @@
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// indexVersion is bumped when indexEntry changes meaning; older indexes are discarded
+const indexVersion = 2
+
+// IndexFile returns the path of the discovery index (a cache, safe to delete)
+func IndexFile() string {
+	return filepath.Join(StorageDir(), "index.json")
+}
+
+// indexEntry caches what discovery reads from one JSONL
+// It's valid while the file's size and mtime match.
+type indexEntry struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mtime"`
+	// Scanned is how many bytes were counted (up to the last complete line).
+	// A file that only grew is counted from here on.
+	Scanned int64 `json:"scanned"`
+
+	Cwd          string    `json:"cwd,omitempty"`
+	GitBranch    string    `json:"git_branch,omitempty"`
+	Title        string    `json:"title,omitempty"`
+	HasContent   bool      `json:"has_content,omitempty"`
+	MessageCount int       `json:"messages"`
+	FirstAt      time.Time `json:"first_at,omitempty"`
+	LastAt       time.Time `json:"last_at,omitempty"`
+	Usage        Usage     `json:"usage,omitempty"`
+	// LastMessageID is the ID of the last response counted in Usage. Claude
+	// writes a response's content blocks as separate lines repeating its usage.
+	LastMessageID string `json:"last_message_id,omitempty"`
+}
+
+// discoveryIndex maps JSONL paths to their cached metadata
+type discoveryIndex struct {
+	Version int                    `json:"version"`
+	Files   map[string]*indexEntry `json:"files"`
+
+	mu      sync.Mutex // guards Files and changed (lookup runs on the worker pool)
+	changed bool
+}
+
+// loadIndex reads the discovery index (an empty one if missing, unreadable or outdated)
+func loadIndex() *discoveryIndex {
+	idx := &discoveryIndex{}
+	if content, err := os.ReadFile(IndexFile()); err == nil {
+		json.Unmarshal(content, idx)
+	}
+	if idx.Version != indexVersion || idx.Files == nil {
+		idx = &discoveryIndex{Version: indexVersion, Files: make(map[string]*indexEntry)}
+	}
+	return idx
+}
+
+// save writes the index back if anything changed (errors are ignored - it's a cache)
+func (idx *discoveryIndex) save() {
+	if !idx.changed {
+		return
+	}
+	content, err := json.Marshal(idx)
+	if err != nil {
+		return
+	}
+	if os.MkdirAll(StorageDir(), 0755) == nil && writeFileAtomic(IndexFile(), content, 0644) == nil {
+		idx.changed = false
+	}
+}
+
+// lookup returns up-to-date metadata for a JSONL, re-reading only what changed
+func (idx *discoveryIndex) lookup(path string, info os.FileInfo) *indexEntry {
+	size, mtime := info.Size(), info.ModTime()
+	idx.mu.Lock()
+	cached := idx.Files[path]
+	idx.mu.Unlock()
+	if cached != nil && cached.Size == size && cached.ModTime.Equal(mtime) {
+		return cached
+	}
+
+	// Claude only appends to JSONLs, so a file that grew keeps its counts
+	// and only the new lines are read
+	entry := &indexEntry{}
+	appended := cached != nil && size > cached.Size
+	if appended {
+		*entry = *cached
+		entry.Usage = cached.Usage.clone() // sessions hold on to the cached one
+	}
+	if !appended || cached.Size < sessionHeadSize {
+		head := readSessionHead(path)
+		fileInfo := parseSessionHead(head)
+		entry.Cwd = fileInfo.cwd
+		entry.GitBranch = fileInfo.gitBranch
+		entry.HasContent = entry.HasContent || fileInfo.hasContent
+		entry.Title = titleFromHead(head)
+	}
+	entry.Size = size
+	entry.ModTime = mtime
+	scanEntries(path, entry)
+
+	idx.mu.Lock()
+	idx.Files[path] = entry
+	idx.changed = true
+	idx.mu.Unlock()
+	return entry
+}
+
+// prune drops entries for files that weren't seen
+func (idx *discoveryIndex) prune(seen map[string]bool) {
+	for path := range idx.Files {
+		if !seen[path] {
+			delete(idx.Files, path)
+			idx.changed = true
+		}
+	}
+}
+
+// indexLine is the part of a JSONL entry the index needs
+type indexLine struct {
+	Timestamp string `json:"timestamp"`
+	Message   *struct {
+		ID    string `json:"id"`
+		Role  string `json:"role"`
+		Model string `json:"model"`
+		Usage *struct {
+			InputTokens              int64 `json:"input_tokens"`
+			OutputTokens             int64 `json:"output_tokens"`
+			CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+			CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+		} `json:"usage"`
+	} `json:"message"`
+}
+
+// scanEntries counts messages and tokens and tracks timestamps from
+// entry.Scanned to the last complete line, advancing entry.Scanned
+func scanEntries(path string, entry *indexEntry) {
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	if _, err := file.Seek(entry.Scanned, io.SeekStart); err != nil {
+		return
+	}
+
+	reader := bufio.NewReaderSize(file, 64*1024)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			// A partial last line is still being written - count it next time
+			return
+		}
+		entry.Scanned += int64(len(line))
+
+		var l indexLine
+		if json.Unmarshal(line, &l) != nil {
+			continue
+		}
+		if l.Message != nil && (l.Message.Role == "user" || l.Message.Role == "assistant") {
+			entry.MessageCount++
+			entry.HasContent = true
+		}
+		if l.Timestamp != "" {
+			if t, err := time.Parse(time.RFC3339, l.Timestamp); err == nil {
+				if entry.FirstAt.IsZero() {
+					entry.FirstAt = t
+				}
+				entry.LastAt = t
+			}
+		}
+		if msg := l.Message; msg != nil && msg.Role == "assistant" && msg.Usage != nil &&
+			(msg.ID == "" || msg.ID != entry.LastMessageID) {
+			entry.LastMessageID = msg.ID
+			tokens := Tokens{
+				Input:      msg.Usage.InputTokens,
+				Output:     msg.Usage.OutputTokens,
+				CacheWrite: msg.Usage.CacheCreationInputTokens,
+				CacheRead:  msg.Usage.CacheReadInputTokens,
+			}
+			if tokens.Total() > 0 {
+				if entry.Usage == nil {
+					entry.Usage = make(Usage)
+				}
+				entry.Usage.add(usageMonth(entry.LastAt), msg.Model, tokens)
+			}
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDiscoveryIndex(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	projectDir := filepath.Join(ClaudeProjectsDir(), "-work-api")
+	os.MkdirAll(projectDir, 0755)
+	id := "550e8400-e29b-41d4-a716-446655440000"
+	jsonlPath := filepath.Join(projectDir, id+".jsonl")
+	content := `{"type":"user","cwd":"/work/api","gitBranch":"main","timestamp":"2024-05-01T10:00:00Z","message":{"role":"user","content":"fix the tests\nplease"}}
+{"type":"assistant","timestamp":"2024-05-01T10:01:00Z","message":{"role":"assistant","content":[{"type":"text","text":"done"}]}}
+`
+	os.WriteFile(jsonlPath, []byte(content), 0644)
+
+	discover := func() *Session {
+		t.Helper()
+		sessions, err := DiscoverSessions()
+		if err != nil || len(sessions) != 1 {
+			t.Fatalf("DiscoverSessions() = %d sessions, %v", len(sessions), err)
+		}
+		return sessions[0]
+	}
+
+	s := discover()
+	if s.Title != "fix the tests" || s.MessageCount != 2 || s.GitBranch != "main" || s.ProjectPath != "/work/api" {
+		t.Errorf("unexpected session: title=%q messages=%d branch=%q path=%q", s.Title, s.MessageCount, s.GitBranch, s.ProjectPath)
+	}
+	if want := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC); !s.CreatedAt.Equal(want) {
+		t.Errorf("CreatedAt = %v, want first timestamp %v", s.CreatedAt, want)
+	}
+	if _, err := os.Stat(IndexFile()); err != nil {
+		t.Fatalf("index not written: %v", err)
+	}
+
+	// Unchanged files come from the index without being read
+	index := loadIndex()
+	index.Files[jsonlPath].Title = "cached"
+	index.changed = true
+	index.save()
+	if s := discover(); s.Title != "cached" {
+		t.Errorf("Title = %q, expected the cached value", s.Title)
+	}
+
+	// Appended lines are counted; a partial last line waits for its newline
+	f, _ := os.OpenFile(jsonlPath, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString(`{"type":"user","timestamp":"2024-05-01T11:00:00Z","message":{"role":"user","content":"thanks"}}` + "\n")
+	f.WriteString(`{"type":"assistant","message":{"role":"assi`)
+	f.Close()
+	s = discover()
+	if s.MessageCount != 3 {
+		t.Errorf("MessageCount = %d after append, want 3", s.MessageCount)
+	}
+	entry := loadIndex().Files[jsonlPath]
+	if entry.LastAt.Hour() != 11 {
+		t.Errorf("LastAt = %v, want the appended timestamp", entry.LastAt)
+	}
+	if entry.Scanned >= entry.Size {
+		t.Errorf("Scanned = %d of %d, partial line should not be consumed", entry.Scanned, entry.Size)
+	}
+
+	// Removed files drop out of the index
+	os.Remove(jsonlPath)
+	if _, err := DiscoverSessions(); err != nil {
+		t.Fatal(err)
+	}
+	if len(loadIndex().Files) != 0 {
+		t.Error("expected removed file to be pruned from the index")
+	}
+}
+
+func TestLoadIndexOutdated(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	os.MkdirAll(StorageDir(), 0755)
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"garbage", "{not json"},
+		{"old version", `{"version":0,"files":{"/a.jsonl":{"size":1}}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.WriteFile(IndexFile(), []byte(tt.content), 0644)
+			idx := loadIndex()
+			if idx.Version != indexVersion || len(idx.Files) != 0 {
+				t.Errorf("loadIndex() = %+v, want an empty index", idx)
+			}
+		})
+	}
+}
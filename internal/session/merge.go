@@ -0,0 +1,169 @@
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// mergeStorage three-way merges our in-memory state with sessions.json as another
+// deck left it. base is the file as we last saw it.
+//
+// Each persisted field is merged on its own: a field we didn't change since base
+// takes their value, otherwise ours wins. Sessions and groups added on either side
+// are kept; ones removed on one side are dropped unless the other side changed them.
+// Existing *Session and *Group pointers are updated in place so the UI keeps them.
+func mergeStorage(base, ours, theirs *StorageData) *StorageData {
+	merged := &StorageData{
+		Sessions: mergeSessionLists(base.Sessions, ours.Sessions, theirs.Sessions),
+		Groups:   mergeGroupLists(base.Groups, ours.Groups, theirs.Groups),
+		Settings: &Settings{},
+	}
+	ourSettings := ours.Settings
+	if ourSettings == nil {
+		ourSettings = &Settings{}
+	}
+	mergeValue(base.Settings, ourSettings, theirs.Settings, merged.Settings)
+	return merged
+}
+
+// mergeSessionLists merges sessions by ClaudeSessionID
+func mergeSessionLists(base, ours, theirs []*Session) []*Session {
+	baseByID := make(map[string]*Session)
+	for _, s := range base {
+		baseByID[s.ClaudeSessionID] = s
+	}
+	theirsByID := make(map[string]*Session)
+	for _, s := range theirs {
+		theirsByID[s.ClaudeSessionID] = s
+	}
+
+	var result []*Session
+	seen := make(map[string]bool)
+	for _, s := range ours {
+		id := s.ClaudeSessionID
+		seen[id] = true
+		b, t := baseByID[id], theirsByID[id]
+		switch {
+		case t == nil && b == nil:
+			result = append(result, s) // added by us
+		case t == nil:
+			// Removed by them - keep only if we changed it meanwhile
+			if !fieldsEqual(b, s) {
+				result = append(result, s)
+			}
+		default:
+			merged := Session{}
+			mergeValue(b, s, t, &merged)
+			// Runtime fields aren't persisted - keep ours
+			merged.Status = s.Status
+			merged.JSONLPath = s.JSONLPath
+			merged.MessageCount = s.MessageCount
+			merged.Usage = s.Usage
+			merged.Title = s.Title
+			merged.GitBranch = s.GitBranch
+			merged.DataRoot = s.DataRoot
+			*s = merged
+			result = append(result, s)
+		}
+	}
+	for _, t := range theirs {
+		if seen[t.ClaudeSessionID] {
+			continue
+		}
+		// Added by them (sessions we removed since base stay removed)
+		if _, inBase := baseByID[t.ClaudeSessionID]; !inBase {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// mergeGroupLists merges groups by ID
+func mergeGroupLists(base, ours, theirs []*Group) []*Group {
+	baseByID := make(map[string]*Group)
+	for _, g := range base {
+		baseByID[g.ID] = g
+	}
+	theirsByID := make(map[string]*Group)
+	for _, g := range theirs {
+		theirsByID[g.ID] = g
+	}
+
+	var result []*Group
+	seen := make(map[string]bool)
+	for _, g := range ours {
+		seen[g.ID] = true
+		b, t := baseByID[g.ID], theirsByID[g.ID]
+		switch {
+		case t == nil && b == nil:
+			result = append(result, g)
+		case t == nil:
+			if !fieldsEqual(b, g) {
+				result = append(result, g)
+			}
+		default:
+			merged := Group{}
+			mergeValue(b, g, t, &merged)
+			*g = merged
+			result = append(result, g)
+		}
+	}
+	for _, t := range theirs {
+		if seen[t.ID] {
+			continue
+		}
+		if _, inBase := baseByID[t.ID]; !inBase {
+			result = append(result, t)
+		}
+	}
+	if result == nil {
+		result = make([]*Group, 0)
+	}
+	return result
+}
+
+// mergeValue merges the JSON fields of base, ours and theirs into out (a zero value)
+// A field we left as it was in base takes their value; otherwise ours wins.
+func mergeValue(base, ours, theirs, out interface{}) {
+	b, o, t := jsonFields(base), jsonFields(ours), jsonFields(theirs)
+	keys := make(map[string]bool)
+	for _, fields := range []map[string]json.RawMessage{b, o, t} {
+		for key := range fields {
+			keys[key] = true
+		}
+	}
+
+	merged := make(map[string]json.RawMessage)
+	for key := range keys {
+		source := o
+		bv, inBase := b[key]
+		ov, inOurs := o[key]
+		if inBase == inOurs && bytes.Equal(bv, ov) {
+			source = t // unchanged by us
+		}
+		if value, ok := source[key]; ok {
+			merged[key] = value
+		}
+	}
+
+	data, _ := json.Marshal(merged)
+	json.Unmarshal(data, out)
+}
+
+// jsonFields returns the persisted fields of v keyed by JSON name
+func jsonFields(v interface{}) map[string]json.RawMessage {
+	fields := make(map[string]json.RawMessage)
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fields
+	}
+	json.Unmarshal(data, &fields)
+	return fields
+}
+
+// fieldsEqual returns true if a and b have the same persisted fields
+func fieldsEqual(a, b interface{}) bool {
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return bytes.Equal(ja, jb)
+}
@@ -0,0 +1,85 @@
+package session
+
+import (
+	"testing"
+)
+
+func TestMergeStorage(t *testing.T) {
+	base := &StorageData{
+		Sessions: []*Session{
+			{ClaudeSessionID: "a", Name: "alpha", GroupPath: ""},
+			{ClaudeSessionID: "b", Name: "beta", Renamed: true},
+			{ClaudeSessionID: "c", Name: "gamma"},
+			{ClaudeSessionID: "d", Name: "delta"},
+		},
+		Groups:   []*Group{{ID: "g1", Name: "Work", Path: "Work", Expanded: true}},
+		Settings: &Settings{Theme: "nord"},
+	}
+	// We pinned a and dropped d
+	ourA := &Session{ClaudeSessionID: "a", Name: "alpha", Pinned: true, Status: StatusRunning, JSONLPath: "/a.jsonl"}
+	ours := &StorageData{
+		Sessions: []*Session{
+			ourA,
+			{ClaudeSessionID: "b", Name: "beta", Renamed: true},
+			{ClaudeSessionID: "c", Name: "gamma"},
+		},
+		Groups:   []*Group{{ID: "g1", Name: "Work", Path: "Work", Expanded: false}},
+		Settings: &Settings{Theme: "nord", ResumeOnStartup: true},
+	}
+	// They moved a into Work, reset b's name, removed c, added e and a group, changed theme
+	theirs := &StorageData{
+		Sessions: []*Session{
+			{ClaudeSessionID: "a", Name: "alpha", GroupPath: "Work"},
+			{ClaudeSessionID: "b", Name: "beta"},
+			{ClaudeSessionID: "d", Name: "delta"},
+			{ClaudeSessionID: "e", Name: "epsilon"},
+		},
+		Groups: []*Group{
+			{ID: "g1", Name: "Work", Path: "Work", Expanded: true},
+			{ID: "g2", Name: "Play", Path: "Play"},
+		},
+		Settings: &Settings{Theme: "dracula"},
+	}
+
+	merged := mergeStorage(base, ours, theirs)
+
+	byID := make(map[string]*Session)
+	for _, s := range merged.Sessions {
+		byID[s.ClaudeSessionID] = s
+	}
+	if len(byID) != 3 || byID["a"] == nil || byID["b"] == nil || byID["e"] == nil {
+		t.Fatalf("expected sessions a, b, e; got %v", byID)
+	}
+
+	a := byID["a"]
+	if a != ourA {
+		t.Error("existing session pointers should be updated in place")
+	}
+	if !a.Pinned || a.GroupPath != "Work" {
+		t.Errorf("a should keep our pin and their group: %+v", a)
+	}
+	if a.Status != StatusRunning || a.JSONLPath != "/a.jsonl" {
+		t.Errorf("runtime fields should be kept: %+v", a)
+	}
+	if byID["b"].Renamed {
+		t.Error("b: their cleared Renamed flag should win over our unchanged one")
+	}
+
+	if len(merged.Groups) != 2 || merged.Groups[0].Expanded {
+		t.Errorf("groups: expected our collapse of Work plus their Play, got %+v", merged.Groups)
+	}
+	if merged.Settings.Theme != "dracula" || !merged.Settings.ResumeOnStartup {
+		t.Errorf("settings: expected their theme and our resume flag, got %+v", merged.Settings)
+	}
+}
+
+func TestMergeStorageBothChanged(t *testing.T) {
+	base := &StorageData{Sessions: []*Session{{ClaudeSessionID: "a", Name: "old"}}, Settings: &Settings{}}
+	ours := &StorageData{Sessions: []*Session{{ClaudeSessionID: "a", Name: "mine"}}, Settings: &Settings{}}
+	theirs := &StorageData{Sessions: []*Session{{ClaudeSessionID: "a", Name: "theirs"}}, Settings: &Settings{}}
+
+	merged := mergeStorage(base, ours, theirs)
+	if merged.Sessions[0].Name != "mine" {
+		t.Errorf("conflicting field: ours should win, got %q", merged.Sessions[0].Name)
+	}
+}
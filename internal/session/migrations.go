@@ -0,0 +1,164 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// SchemaVersion is the sessions.json format written by this build
+// Files without a version field are version 1.
+const SchemaVersion = 2
+
+// ErrNewerSchema is returned when sessions.json was written by a newer claude-deck
+var ErrNewerSchema = errors.New("sessions.json was written by a newer version of claude-deck")
+
+// NewerSchemaError reports the version found in a file this build can't read
+type NewerSchemaError struct {
+	Version int
+}
+
+func (e *NewerSchemaError) Error() string {
+	return fmt.Sprintf("%s (schema v%d, this build supports up to v%d) - upgrade claude-deck to use it",
+		ErrNewerSchema, e.Version, SchemaVersion)
+}
+
+func (e *NewerSchemaError) Is(target error) bool {
+	return target == ErrNewerSchema
+}
+
+// migration upgrades the raw JSON document from version `from` to from+1
+// Migrations work on the generic document so they don't depend on today's structs.
+type migration struct {
+	from        int
+	description string
+	migrate     func(doc map[string]interface{}) error
+}
+
+// migrations must cover every version from 1 to SchemaVersion-1, in order
+var migrations = []migration{
+	{1, "give every session a unique, non-zero order", migrateOrders},
+}
+
+// BackupFile returns where the original sessions.json is kept before migrating from version
+func BackupFile(version int) string {
+	return fmt.Sprintf("%s.v%d.bak", StorageFile(), version)
+}
+
+// schemaVersion returns the version of a sessions.json document
+func schemaVersion(doc map[string]interface{}) int {
+	if v, ok := doc["version"].(float64); ok && v >= 1 {
+		return int(v)
+	}
+	return 1
+}
+
+// migrateContent upgrades sessions.json content to SchemaVersion
+// Returns the upgraded content and the version it started from. Content that's
+// already current is returned unchanged; content from a newer build is an error.
+func migrateContent(content []byte) ([]byte, int, error) {
+	if len(content) == 0 {
+		return content, SchemaVersion, nil
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return nil, 0, err
+	}
+
+	from := schemaVersion(doc)
+	if from > SchemaVersion {
+		return nil, from, &NewerSchemaError{Version: from}
+	}
+	if from == SchemaVersion {
+		return content, from, nil
+	}
+
+	for _, m := range migrations {
+		if m.from < from {
+			continue
+		}
+		if err := m.migrate(doc); err != nil {
+			return nil, from, fmt.Errorf("migrating sessions.json from v%d (%s): %w", m.from, m.description, err)
+		}
+		doc["version"] = m.from + 1
+	}
+
+	migrated, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, from, err
+	}
+	return migrated, from, nil
+}
+
+// upgradeStorageFile migrates sessions.json on disk if it's from an older version,
+// keeping a backup of the original. Takes the storage lock.
+func upgradeStorageFile() error {
+	unlock, err := lockStorage(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	content, err := os.ReadFile(StorageFile())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	migrated, from, err := migrateContent(content)
+	if err != nil || from == SchemaVersion {
+		return err
+	}
+
+	// Keep the oldest original if a backup for this version already exists
+	if _, err := os.Stat(BackupFile(from)); os.IsNotExist(err) {
+		if err := writeFileAtomic(BackupFile(from), content, 0644); err != nil {
+			return fmt.Errorf("backing up sessions.json: %w", err)
+		}
+	}
+	return writeFileAtomic(StorageFile(), migrated, 0644)
+}
+
+// migrateOrders (v1 -> v2) renumbers sessions when Order values are missing or
+// duplicated - early versions saved every session with order 0.
+// Most recently accessed sessions come first.
+func migrateOrders(doc map[string]interface{}) error {
+	sessions, _ := doc["sessions"].([]interface{})
+
+	needsReorder := false
+	used := make(map[float64]bool)
+	for _, item := range sessions {
+		s, ok := item.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("invalid session entry")
+		}
+		order, _ := s["order"].(float64)
+		if order == 0 || used[order] {
+			needsReorder = true
+			break
+		}
+		used[order] = true
+	}
+	if !needsReorder {
+		return nil
+	}
+
+	lastAccessed := func(item interface{}) time.Time {
+		s := item.(map[string]interface{})
+		str, _ := s["last_accessed_at"].(string)
+		t, _ := time.Parse(time.RFC3339Nano, str)
+		return t
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return lastAccessed(sessions[i]).After(lastAccessed(sessions[j]))
+	})
+	for i, item := range sessions {
+		item.(map[string]interface{})["order"] = i + 1
+	}
+	doc["sessions"] = sessions
+	return nil
+}
@@ -0,0 +1,174 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrationsContiguous(t *testing.T) {
+	if len(migrations) != SchemaVersion-1 {
+		t.Fatalf("%d migrations for schema v%d, want %d", len(migrations), SchemaVersion, SchemaVersion-1)
+	}
+	for i, m := range migrations {
+		if m.from != i+1 {
+			t.Errorf("migrations[%d].from = %d, want %d", i, m.from, i+1)
+		}
+	}
+}
+
+func TestMigrateContent(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		wantFrom   int
+		wantOrders []int
+		wantErr    error
+	}{
+		{
+			name: "unversioned with zero orders",
+			content: `{"sessions":[
+				{"claude_session_id":"old","order":0,"last_accessed_at":"2024-01-01T00:00:00Z"},
+				{"claude_session_id":"new","order":0,"last_accessed_at":"2024-06-01T00:00:00Z"}],"groups":[]}`,
+			wantFrom:   1,
+			wantOrders: []int{1, 2}, // most recent first
+		},
+		{
+			name:       "unversioned with valid orders",
+			content:    `{"sessions":[{"claude_session_id":"a","order":7},{"claude_session_id":"b","order":3}],"groups":[]}`,
+			wantFrom:   1,
+			wantOrders: []int{7, 3},
+		},
+		{
+			name:       "current version untouched",
+			content:    `{"version":2,"sessions":[{"claude_session_id":"a","order":0}],"groups":[]}`,
+			wantFrom:   2,
+			wantOrders: []int{0},
+		},
+		{
+			name:     "newer version",
+			content:  `{"version":99,"sessions":[],"groups":[]}`,
+			wantFrom: 99,
+			wantErr:  ErrNewerSchema,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrated, from, err := migrateContent([]byte(tt.content))
+			if from != tt.wantFrom {
+				t.Errorf("from = %d, want %d", from, tt.wantFrom)
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("migrateContent() error = %v", err)
+			}
+
+			var data StorageData
+			if err := json.Unmarshal(migrated, &data); err != nil {
+				t.Fatal(err)
+			}
+			if data.Version != SchemaVersion {
+				t.Errorf("version = %d, want %d", data.Version, SchemaVersion)
+			}
+			var orders []int
+			for _, s := range data.Sessions {
+				orders = append(orders, s.Order)
+			}
+			if len(orders) != len(tt.wantOrders) {
+				t.Fatalf("orders = %v, want %v", orders, tt.wantOrders)
+			}
+			for i := range orders {
+				if orders[i] != tt.wantOrders[i] {
+					t.Errorf("orders = %v, want %v", orders, tt.wantOrders)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestUpgradeStorageFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	legacy := `{"sessions":[{"claude_session_id":"a","name":"api","order":0},{"claude_session_id":"b","order":0}],"groups":[]}`
+	os.MkdirAll(StorageDir(), 0755)
+	if err := os.WriteFile(StorageFile(), []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := upgradeStorageFile(); err != nil {
+		t.Fatalf("upgradeStorageFile() error = %v", err)
+	}
+
+	backup, err := os.ReadFile(BackupFile(1))
+	if err != nil {
+		t.Fatalf("expected backup of the v1 file: %v", err)
+	}
+	if string(backup) != legacy {
+		t.Errorf("backup = %s, want original content", backup)
+	}
+
+	data, err := LoadStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.Version != SchemaVersion {
+		t.Errorf("version = %d, want %d", data.Version, SchemaVersion)
+	}
+	if len(data.Sessions) != 2 || data.Sessions[0].Order == data.Sessions[1].Order {
+		t.Errorf("expected two sessions with unique orders, got %+v", data.Sessions)
+	}
+
+	// A second upgrade doesn't replace the original backup
+	os.WriteFile(StorageFile(), []byte(`{"sessions":[],"groups":[]}`), 0644)
+	if err := upgradeStorageFile(); err != nil {
+		t.Fatal(err)
+	}
+	if backup, _ := os.ReadFile(BackupFile(1)); string(backup) != legacy {
+		t.Error("existing backup should be kept")
+	}
+}
+
+func TestNewerSchemaRefused(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	newer := `{"version":99,"sessions":[],"groups":[],"future":true}`
+	os.MkdirAll(StorageDir(), 0755)
+	if err := os.WriteFile(StorageFile(), []byte(newer), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewManager(); !errors.Is(err, ErrNewerSchema) {
+		t.Errorf("NewManager() error = %v, want ErrNewerSchema", err)
+	}
+	if _, err := LoadStorage(); !errors.Is(err, ErrNewerSchema) {
+		t.Errorf("LoadStorage() error = %v, want ErrNewerSchema", err)
+	}
+
+	m := &Manager{Sessions: []*Session{{ClaudeSessionID: "a"}}, Settings: &Settings{}}
+	if err := m.Save(); !errors.Is(err, ErrNewerSchema) {
+		t.Errorf("Save() error = %v, want ErrNewerSchema", err)
+	}
+	if err := SaveStorage(&StorageData{}); !errors.Is(err, ErrNewerSchema) {
+		t.Errorf("SaveStorage() error = %v, want ErrNewerSchema", err)
+	}
+
+	content, _ := os.ReadFile(StorageFile())
+	if string(content) != newer {
+		t.Errorf("file was modified: %s", content)
+	}
+	matches, _ := filepath.Glob(StorageFile() + ".*.bak")
+	if len(matches) != 0 {
+		t.Errorf("unexpected backups: %v", matches)
+	}
+}
@@ -0,0 +1,252 @@
+package session
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// pathCacheVersion is bumped when the cache format changes; older caches are discarded
+const pathCacheVersion = 1
+
+// maxPathMatches bounds how many filesystem matches are collected for one directory
+const maxPathMatches = 8
+
+// PathCacheFile returns the path of the project path cache (a cache, safe to delete)
+func PathCacheFile() string {
+	return filepath.Join(StorageDir(), "paths.json")
+}
+
+// Where a project directory's path came from, most reliable first
+const (
+	PathFromCwd        = "cwd"        // cwd recorded in its JSONLs
+	PathFromCache      = "cache"      // learned earlier (the project may be gone now)
+	PathFromFilesystem = "filesystem" // an existing directory that encodes to the same name
+	PathDecoded        = "decoded"    // DecodeProjectPath guess
+)
+
+// ProjectDir is a Claude project directory and the project path it belongs to
+type ProjectDir struct {
+	Dir        string   // the encoded directory, e.g. ~/.claude/projects/-work-my-app
+	Path       string   // resolved project path
+	Source     string   // how Path was found (PathFromCwd, ...)
+	Candidates []string // every matching path when more than one does (Path is the first)
+}
+
+// Ambiguous reports whether more than one project path maps to the directory
+func (p ProjectDir) Ambiguous() bool {
+	return len(p.Candidates) > 1
+}
+
+// pathCache maps encoded directory names to the project paths learned for them
+type pathCache struct {
+	Version int               `json:"version"`
+	Paths   map[string]string `json:"paths"`
+
+	mu      sync.Mutex
+	changed bool
+}
+
+// loadPathCache reads the path cache (an empty one if missing, unreadable or outdated)
+func loadPathCache() *pathCache {
+	c := &pathCache{}
+	if content, err := os.ReadFile(PathCacheFile()); err == nil {
+		json.Unmarshal(content, c)
+	}
+	if c.Version != pathCacheVersion || c.Paths == nil {
+		c = &pathCache{Version: pathCacheVersion, Paths: make(map[string]string)}
+	}
+	return c
+}
+
+// save writes the cache back if anything changed (errors are ignored - it's a cache)
+func (c *pathCache) save() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.changed {
+		return
+	}
+	content, err := json.Marshal(c)
+	if err != nil {
+		return
+	}
+	if os.MkdirAll(StorageDir(), 0755) == nil && writeFileAtomic(PathCacheFile(), content, 0644) == nil {
+		c.changed = false
+	}
+}
+
+func (c *pathCache) get(name string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.Paths[name]
+}
+
+func (c *pathCache) put(name, path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Paths[name] != path {
+		c.Paths[name] = path
+		c.changed = true
+	}
+}
+
+// guess returns a cheap path for a directory: the cached one, or the decoded name
+func (c *pathCache) guess(dir string) string {
+	name := filepath.Base(dir)
+	if path := c.get(name); path != "" {
+		return path
+	}
+	return DecodeProjectPath(name)
+}
+
+// resolve finds the project path of a directory from the cwds its JSONLs recorded,
+// then the cache, then the filesystem, falling back to DecodeProjectPath.
+// Unambiguous results are remembered in the cache.
+func (c *pathCache) resolve(dir string, cwds []string) ProjectDir {
+	name := filepath.Base(dir)
+	result := ProjectDir{Dir: dir}
+
+	if candidates := distinctPaths(cwds); len(candidates) > 0 {
+		result.Path, result.Source = candidates[0], PathFromCwd
+		if len(candidates) > 1 {
+			result.Candidates = candidates
+		} else {
+			c.put(name, result.Path)
+		}
+		return result
+	}
+
+	if path := c.get(name); path != "" {
+		result.Path, result.Source = path, PathFromCache
+		return result
+	}
+
+	if candidates := matchEncodedPath(name); len(candidates) > 0 {
+		result.Path, result.Source = candidates[0], PathFromFilesystem
+		if len(candidates) > 1 {
+			result.Candidates = candidates
+		} else {
+			c.put(name, result.Path)
+		}
+		return result
+	}
+
+	result.Path, result.Source = DecodeProjectPath(name), PathDecoded
+	return result
+}
+
+// distinctPaths returns the non-empty paths, most frequent first
+func distinctPaths(paths []string) []string {
+	counts := make(map[string]int)
+	var distinct []string
+	for _, p := range paths {
+		if p == "" {
+			continue
+		}
+		if counts[p] == 0 {
+			distinct = append(distinct, p)
+		}
+		counts[p]++
+	}
+	sort.SliceStable(distinct, func(i, j int) bool {
+		return counts[distinct[i]] > counts[distinct[j]]
+	})
+	return distinct
+}
+
+// matchEncodedPath walks the filesystem for directories whose path encodes to name
+// Each step only descends into entries whose encoding is a prefix of what's left,
+// so just the directories along candidate paths are read.
+func matchEncodedPath(name string) []string {
+	if !strings.HasPrefix(name, "-") {
+		return nil
+	}
+	if name == "-" {
+		return []string{"/"}
+	}
+	var matches []string
+	walkEncodedPath("/", name[1:], &matches)
+	sort.Strings(matches)
+	return matches
+}
+
+func walkEncodedPath(dir, rest string, matches *[]string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if len(*matches) >= maxPathMatches {
+			return
+		}
+		encoded := EncodeProjectPath(entry.Name())
+		if rest != encoded && !strings.HasPrefix(rest, encoded+"-") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			continue // follows symlinks, unlike entry.IsDir
+		}
+		if rest == encoded {
+			*matches = append(*matches, path)
+		} else {
+			walkEncodedPath(path, rest[len(encoded)+1:], matches)
+		}
+	}
+}
+
+// ResolveProjectDirs resolves the project path of every project directory in
+// every data root (see DataRoots). Ambiguous ones list all candidates.
+func ResolveProjectDirs() ([]ProjectDir, error) {
+	var files []sessionFile
+	for _, root := range DataRoots() {
+		rootFiles, err := listSessionFiles(root)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, rootFiles...)
+	}
+
+	index := loadIndex()
+	cwds := make(map[string][]string)
+	for _, file := range files {
+		dir := filepath.Dir(file.path)
+		if info, err := os.Stat(file.path); err == nil {
+			cwds[dir] = append(cwds[dir], index.lookup(file.path, info).Cwd)
+		}
+	}
+	index.save()
+
+	var dirs []string
+	for _, projectsDir := range ProjectsDirs() {
+		entries, err := os.ReadDir(projectsDir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				dirs = append(dirs, filepath.Join(projectsDir, entry.Name()))
+			}
+		}
+	}
+
+	cache := loadPathCache()
+	resolved := make([]ProjectDir, 0, len(dirs))
+	for _, dir := range dirs {
+		resolved = append(resolved, cache.resolve(dir, cwds[dir]))
+	}
+	cache.save()
+	return resolved, nil
+}
+
+// JSONLMatchesPath reports whether a session JSONL belongs to a project path:
+// it's in the path's encoded directory, or it recorded the path as its cwd
+func JSONLMatchesPath(jsonlPath, projectPath string) bool {
+	if filepath.Base(filepath.Dir(jsonlPath)) == EncodeProjectPath(projectPath) {
+		return true
+	}
+	return GetProjectPathFromJSONL(jsonlPath) == projectPath
+}
@@ -0,0 +1,107 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMatchEncodedPath(t *testing.T) {
+	base := t.TempDir()
+	for _, dir := range []string{"my.app", "my-app", "web app", "other"} {
+		os.MkdirAll(filepath.Join(base, dir, "src"), 0755)
+	}
+
+	tests := []struct {
+		name string
+		path string // encoded for the lookup
+		want []string
+	}{
+		{"unique with space", filepath.Join(base, "web app", "src"), []string{filepath.Join(base, "web app", "src")}},
+		{"ambiguous", filepath.Join(base, "my.app"), []string{filepath.Join(base, "my-app"), filepath.Join(base, "my.app")}},
+		{"missing", filepath.Join(base, "gone"), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := matchEncodedPath(EncodeProjectPath(tt.path))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("matchEncodedPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathCacheResolve(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	base := t.TempDir()
+	os.MkdirAll(filepath.Join(base, "site.io"), 0755)
+	project := filepath.Join(base, "site.io")
+	dir := filepath.Join(ClaudeProjectsDir(), EncodeProjectPath(project))
+
+	cache := loadPathCache()
+
+	// Ambiguous cwds are reported and not cached
+	got := cache.resolve(dir, []string{"/a/b.c", "/a/b-c", "/a/b-c", ""})
+	if got.Source != PathFromCwd || got.Path != "/a/b-c" || !got.Ambiguous() {
+		t.Errorf("ambiguous cwds resolved to %+v", got)
+	}
+
+	// Filesystem match is remembered
+	got = cache.resolve(dir, nil)
+	if got.Source != PathFromFilesystem || got.Path != project || got.Ambiguous() {
+		t.Errorf("filesystem resolved to %+v", got)
+	}
+	cache.save()
+
+	// ...and still known after the project is gone
+	os.RemoveAll(project)
+	got = loadPathCache().resolve(dir, nil)
+	if got.Source != PathFromCache || got.Path != project {
+		t.Errorf("cache resolved to %+v", got)
+	}
+
+	got = loadPathCache().resolve(filepath.Join(ClaudeProjectsDir(), "-nowhere-at-all"), nil)
+	if got.Source != PathDecoded || got.Path != "/nowhere/at/all" {
+		t.Errorf("unknown directory resolved to %+v", got)
+	}
+}
+
+func TestDiscoverSessionsResolvesMissingCwd(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	project := "/work/my.site"
+	id1 := "550e8400-e29b-41d4-a716-446655440000"
+	id2 := "550e8400-e29b-41d4-a716-446655440001"
+	writeTestSessionFile(t, home, project, id1)
+	dir := filepath.Join(home, ".claude", "projects", EncodeProjectPath(project))
+	if err := os.WriteFile(filepath.Join(dir, id2+".jsonl"), []byte(`{"type":"summary"}`+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions, err := DiscoverSessions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range sessions {
+		if s.ProjectPath != project {
+			t.Errorf("session %s has path %q, want %q", s.ClaudeSessionID, s.ProjectPath, project)
+		}
+	}
+	if got := loadPathCache().get(EncodeProjectPath(project)); got != project {
+		t.Errorf("cached path = %q, want %q", got, project)
+	}
+
+	// New-session matching finds files in directories with dots
+	if got := FindNewestSessionAtPath(project); got == "" {
+		t.Error("FindNewestSessionAtPath found nothing for a path with a dot")
+	}
+	if !JSONLMatchesPath(filepath.Join(dir, id2+".jsonl"), project) {
+		t.Error("JSONLMatchesPath should match by directory name")
+	}
+	if JSONLMatchesPath(filepath.Join(dir, id2+".jsonl"), "/work/other") {
+		t.Error("JSONLMatchesPath matched the wrong project")
+	}
+}
@@ -0,0 +1,50 @@
+package session
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// Workers bounds how many files or git commands are processed at once
+var Workers = min(max(runtime.NumCPU(), 2), 8)
+
+// Parallel calls fn for each i in [0, n) on up to Workers goroutines
+// Results are sent on the returned channel as they finish (not in index order),
+// and the channel is closed once all are done or ctx is cancelled.
+func Parallel[T any](ctx context.Context, n int, fn func(i int) T) <-chan T {
+	results := make(chan T)
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < min(Workers, n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				result := fn(i)
+				select {
+				case results <- result:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+	feed:
+		for i := 0; i < n; i++ {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				break feed
+			}
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
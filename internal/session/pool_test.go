@@ -0,0 +1,66 @@
+package session
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParallel(t *testing.T) {
+	var running, peak atomic.Int32
+	results := Parallel(context.Background(), 50, func(i int) int {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		running.Add(-1)
+		return i
+	})
+
+	seen := make(map[int]bool)
+	for i := range results {
+		seen[i] = true
+	}
+	if len(seen) != 50 {
+		t.Errorf("got %d results, want 50", len(seen))
+	}
+	if p := int(peak.Load()); p > Workers {
+		t.Errorf("%d workers ran at once, limit is %d", p, Workers)
+	}
+}
+
+func TestParallelCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	results := Parallel(ctx, 1000, func(i int) int { return i })
+
+	<-results
+	cancel()
+
+	done := make(chan int)
+	go func() {
+		n := 0
+		for range results {
+			n++
+		}
+		done <- n
+	}()
+	select {
+	case n := <-done:
+		if n >= 999 {
+			t.Errorf("got %d more results after cancel", n)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("results channel not closed after cancel")
+	}
+}
+
+func TestParallelEmpty(t *testing.T) {
+	for range Parallel(context.Background(), 0, func(i int) int { return i }) {
+		t.Error("unexpected result")
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"io"
 	"os"
-	"os/exec"
 	"strings"
 	"time"
 )
@@ -55,17 +54,51 @@ func (m *MessageContent) GetContent() string {
 	return ""
 }
 
+// GetParts returns the content parts of the message
+// String content (plain user messages) is returned as a single text part
+func (m *MessageContent) GetParts() []ContentPart {
+	if len(m.RawContent) == 0 {
+		return nil
+	}
+
+	var strContent string
+	if err := json.Unmarshal(m.RawContent, &strContent); err == nil {
+		return []ContentPart{{Type: "text", Text: strContent}}
+	}
+
+	var parts []ContentPart
+	if err := json.Unmarshal(m.RawContent, &parts); err == nil {
+		return parts
+	}
+	return nil
+}
+
 // ContentPart represents a part of the message content
 type ContentPart struct {
-	Type  string `json:"type"`
-	Text  string `json:"text,omitempty"`
-	Name  string `json:"name,omitempty"`
-	Input any    `json:"input,omitempty"`
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	ID        string          `json:"id,omitempty"`          // tool_use ID
+	Name      string          `json:"name,omitempty"`        // tool name
+	Input     any             `json:"input,omitempty"`       // tool input
+	ToolUseID string          `json:"tool_use_id,omitempty"` // tool_result: ID of the tool_use it answers
+	IsError   bool            `json:"is_error,omitempty"`    // tool_result: tool failed or was rejected
+	Content   json.RawMessage `json:"content,omitempty"`     // tool_result: string or array of parts
 }
 
 // GetPreview reads the last N messages from a session's JSONL file
 // Only reads the tail of the file for performance
 func GetPreview(s *Session) ([]PreviewMessage, error) {
+	return readPreview(s, false)
+}
+
+// GetPreviewWithTools is GetPreview with tool calls: messages list the tools
+// they called, with results attached, and messages that only call tools are kept
+func GetPreviewWithTools(s *Session) ([]PreviewMessage, error) {
+	return readPreview(s, true)
+}
+
+// readPreview reads the last MaxPreviewMessages messages from the tail of a JSONL
+func readPreview(s *Session, withTools bool) ([]PreviewMessage, error) {
 	if s.JSONLPath == "" {
 		return nil, nil
 	}
@@ -109,6 +142,10 @@ func GetPreview(s *Session) ([]PreviewMessage, error) {
 	var messages []PreviewMessage
 	lines := bytes.Split(data, []byte("\n"))
 
+	// Where each tool call is, so its result (in a later user message) can be attached
+	type toolRef struct{ msg, tool int }
+	calls := make(map[string]toolRef)
+
 	for _, line := range lines {
 		if len(line) == 0 {
 			continue
@@ -136,7 +173,22 @@ func GetPreview(s *Session) ([]PreviewMessage, error) {
 
 		// Extract text content (handles both string and array formats)
 		msg.Content = entry.Message.GetContent()
-		if msg.Content == "" {
+
+		if withTools {
+			for _, part := range entry.Message.GetParts() {
+				switch part.Type {
+				case "tool_use":
+					calls[part.ID] = toolRef{len(messages), len(msg.Tools)}
+					msg.Tools = append(msg.Tools, newToolCall(part))
+				case "tool_result":
+					if ref, ok := calls[part.ToolUseID]; ok && ref.msg < len(messages) {
+						messages[ref.msg].Tools[ref.tool].Result = newToolResult(part)
+					}
+				}
+			}
+		}
+
+		if msg.Content == "" && len(msg.Tools) == 0 {
 			continue
 		}
 
@@ -153,6 +205,18 @@ func GetPreview(s *Session) ([]PreviewMessage, error) {
 
 // extractContent extracts readable text from message content
 func extractContent(parts []ContentPart) string {
+	content := messageText(parts)
+
+	// Truncate if too long
+	if len(content) > MaxContentLength {
+		content = content[:MaxContentLength] + "..."
+	}
+
+	return content
+}
+
+// messageText joins the text parts of a message (tool calls and results are skipped)
+func messageText(parts []ContentPart) string {
 	var texts []string
 
 	for _, part := range parts {
@@ -170,14 +234,7 @@ func extractContent(parts []ContentPart) string {
 		}
 	}
 
-	content := strings.Join(texts, "\n")
-
-	// Truncate if too long
-	if len(content) > MaxContentLength {
-		content = content[:MaxContentLength] + "..."
-	}
-
-	return content
+	return strings.Join(texts, "\n")
 }
 
 // GetSessionStats returns statistics about a session
@@ -195,107 +252,6 @@ func GetSessionStats(s *Session) (messageCount int, lastActivity time.Time, err
 	return 0, info.ModTime(), nil
 }
 
-// SearchResult represents a content search match
-type SearchResult struct {
-	Session *Session
-	Snippet string // Context around the match
-	Role    string // user or assistant
-}
-
-// SearchContent searches through session content for a query string
-// Only searches user/assistant text, not tool calls or bash commands
-func SearchContent(sessions []*Session, query string) []SearchResult {
-	if query == "" || len(sessions) == 0 {
-		return nil
-	}
-
-	var results []SearchResult
-
-	for _, s := range sessions {
-		if s.JSONLPath == "" {
-			continue
-		}
-
-		snippet := searchConversationText(s.JSONLPath, query)
-		if snippet != "" {
-			results = append(results, SearchResult{
-				Session: s,
-				Snippet: snippet,
-				Role:    "user",
-			})
-		}
-	}
-
-	return results
-}
-
-// searchConversationText searches only in user/assistant text content (not tool calls)
-func searchConversationText(path, query string) string {
-	// Use rg/grep to find lines with text content containing the query
-	// Pattern: match lines that have "text":" (text content) AND contain the query
-	// This filters out tool_use, tool_result, bash commands etc.
-
-	var cmd *exec.Cmd
-	queryLower := strings.ToLower(query)
-
-	if _, err := exec.LookPath("rg"); err == nil {
-		// rg: search for lines containing both "text":" and the query
-		cmd = exec.Command("rg", "-i", "-m1", query, path)
-	} else {
-		cmd = exec.Command("grep", "-i", "-m1", query, path)
-	}
-
-	output, err := cmd.Output()
-	if err != nil || len(output) == 0 {
-		return ""
-	}
-
-	// Parse the matching line to check if it's text content (not tool/bash)
-	line := string(output)
-
-	// Skip if this is a tool call or bash command
-	if strings.Contains(line, `"type":"tool_use"`) ||
-		strings.Contains(line, `"type":"tool_result"`) ||
-		strings.Contains(line, `"name":"Bash"`) ||
-		strings.Contains(line, `"name":"Read"`) ||
-		strings.Contains(line, `"name":"Write"`) ||
-		strings.Contains(line, `"name":"Edit"`) ||
-		strings.Contains(line, `"name":"Grep"`) ||
-		strings.Contains(line, `"name":"Glob"`) {
-		// Try to find a text-only match
-		return searchTextOnly(path, query)
-	}
-
-	// Extract snippet with context
-	return extractSnippetWithContext(line, queryLower)
-}
-
-// searchTextOnly searches specifically in "text":" fields
-func searchTextOnly(path, query string) string {
-	// Search for query appearing after "text":" pattern
-	var cmd *exec.Cmd
-	pattern := `"text":"[^"]*` + query
-
-	if _, err := exec.LookPath("rg"); err == nil {
-		cmd = exec.Command("rg", "-i", "-m1", "-o", pattern+`[^"]*`, path)
-	} else {
-		cmd = exec.Command("grep", "-i", "-m1", "-oE", pattern+`[^"]*`, path)
-	}
-
-	output, err := cmd.Output()
-	if err != nil || len(output) == 0 {
-		return ""
-	}
-
-	snippet := string(output)
-	// Clean up
-	snippet = strings.TrimPrefix(snippet, `"text":"`)
-	snippet = strings.ReplaceAll(snippet, "\\n", " ")
-	snippet = strings.ReplaceAll(snippet, "\\t", " ")
-
-	return truncateSnippet(snippet, 150)
-}
-
 // extractSnippetWithContext extracts the query match with surrounding context
 func extractSnippetWithContext(line, query string) string {
 	lineLower := strings.ToLower(line)
@@ -332,7 +288,6 @@ func extractSnippetWithContext(line, query string) string {
 	return truncateSnippet(snippet, 150)
 }
 
-
 // truncateSnippet truncates content to maxLen chars
 func truncateSnippet(s string, maxLen int) string {
 	s = strings.ReplaceAll(s, "\n", " ")
@@ -349,22 +304,11 @@ func GetSessionTitle(s *Session) string {
 	if s.JSONLPath == "" {
 		return ""
 	}
+	return titleFromHead(readSessionHead(s.JSONLPath))
+}
 
-	file, err := os.Open(s.JSONLPath)
-	if err != nil {
-		return ""
-	}
-	defer file.Close()
-
-	// Read first 20KB
-	data := make([]byte, 20*1024)
-	n, err := file.Read(data)
-	if err != nil && n == 0 {
-		return ""
-	}
-	data = data[:n]
-
-	// Find first user message
+// titleFromHead returns the first line of the first user message in the start of a JSONL
+func titleFromHead(data []byte) string {
 	lines := bytes.Split(data, []byte("\n"))
 	for _, line := range lines {
 		if len(line) == 0 {
@@ -1,6 +1,7 @@
 package session
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
@@ -399,21 +400,21 @@ func TestGetSessionStatsEmptyPath(t *testing.T) {
 func TestSearchContent(t *testing.T) {
 	t.Run("empty query returns nil", func(t *testing.T) {
 		sessions := []*Session{{JSONLPath: "/some/path"}}
-		results := SearchContent(sessions, "")
+		results := SearchContent(context.Background(), sessions, "")
 		if results != nil {
 			t.Error("expected nil for empty query")
 		}
 	})
 
 	t.Run("empty sessions returns nil", func(t *testing.T) {
-		results := SearchContent([]*Session{}, "test")
+		results := SearchContent(context.Background(), []*Session{}, "test")
 		if results != nil {
 			t.Error("expected nil for empty sessions")
 		}
 	})
 
 	t.Run("nil sessions returns nil", func(t *testing.T) {
-		results := SearchContent(nil, "test")
+		results := SearchContent(context.Background(), nil, "test")
 		if results != nil {
 			t.Error("expected nil for nil sessions")
 		}
@@ -421,7 +422,7 @@ func TestSearchContent(t *testing.T) {
 
 	t.Run("skips sessions without JSONLPath", func(t *testing.T) {
 		sessions := []*Session{{JSONLPath: ""}}
-		results := SearchContent(sessions, "test")
+		results := SearchContent(context.Background(), sessions, "test")
 		if len(results) != 0 {
 			t.Errorf("expected 0 results, got %d", len(results))
 		}
@@ -561,7 +562,7 @@ func TestSearchContentWithMatches(t *testing.T) {
 	}
 
 	sessions := []*Session{{JSONLPath: jsonlPath}}
-	results := SearchContent(sessions, "special keyword")
+	results := SearchContent(context.Background(), sessions, "special keyword")
 
 	if len(results) == 0 {
 		t.Error("expected to find match")
@@ -579,7 +580,7 @@ func TestSearchContentNoMatch(t *testing.T) {
 	}
 
 	sessions := []*Session{{JSONLPath: jsonlPath}}
-	results := SearchContent(sessions, "xyznonexistent123")
+	results := SearchContent(context.Background(), sessions, "xyznonexistent123")
 
 	if len(results) != 0 {
 		t.Errorf("expected no matches, got %d", len(results))
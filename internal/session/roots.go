@@ -0,0 +1,91 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// DataRootsEnv lists extra Claude config directories to discover sessions from
+// (separated like PATH), e.g. one per CLAUDE_CONFIG_DIR in use
+const DataRootsEnv = "CLAUDE_DECK_ROOTS"
+
+var (
+	rootsMu         sync.Mutex
+	configuredRoots []string // from the data_roots setting, set by Manager.Load
+)
+
+// builtinDataRoot returns Claude's default config directory (~/.claude)
+func builtinDataRoot() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".claude")
+}
+
+// DefaultDataRoot returns the Claude config directory this deck runs with:
+// $CLAUDE_CONFIG_DIR, or ~/.claude
+func DefaultDataRoot() string {
+	if dir := os.Getenv("CLAUDE_CONFIG_DIR"); dir != "" {
+		return expandHome(dir)
+	}
+	return builtinDataRoot()
+}
+
+// DataRoots returns every Claude config directory sessions are discovered from:
+// the default root, then $CLAUDE_DECK_ROOTS, then the data_roots setting
+func DataRoots() []string {
+	candidates := []string{DefaultDataRoot()}
+	candidates = append(candidates, filepath.SplitList(os.Getenv(DataRootsEnv))...)
+	rootsMu.Lock()
+	candidates = append(candidates, configuredRoots...)
+	rootsMu.Unlock()
+
+	var roots []string
+	seen := make(map[string]bool)
+	for _, root := range candidates {
+		if root == "" {
+			continue
+		}
+		root = filepath.Clean(expandHome(root))
+		if !seen[root] {
+			seen[root] = true
+			roots = append(roots, root)
+		}
+	}
+	return roots
+}
+
+// setConfiguredRoots sets the data roots from settings
+func setConfiguredRoots(roots []string) {
+	rootsMu.Lock()
+	defer rootsMu.Unlock()
+	configuredRoots = append([]string(nil), roots...)
+}
+
+// ProjectsDirs returns the projects directory of every data root
+func ProjectsDirs() []string {
+	roots := DataRoots()
+	dirs := make([]string, len(roots))
+	for i, root := range roots {
+		dirs[i] = filepath.Join(root, "projects")
+	}
+	return dirs
+}
+
+// ConfigDir returns the CLAUDE_CONFIG_DIR to resume the session with
+// Empty for sessions in ~/.claude, which Claude uses without the variable.
+func (s *Session) ConfigDir() string {
+	if s.DataRoot == "" || filepath.Clean(s.DataRoot) == builtinDataRoot() {
+		return ""
+	}
+	return s.DataRoot
+}
+
+// expandHome replaces a leading ~ with the home directory
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, _ := os.UserHomeDir()
+		return filepath.Join(home, path[1:])
+	}
+	return path
+}
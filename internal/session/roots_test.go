@@ -0,0 +1,112 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDataRoots(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Cleanup(func() { setConfiguredRoots(nil) })
+
+	tests := []struct {
+		name       string
+		configDir  string
+		env        string
+		configured []string
+		want       []string
+	}{
+		{
+			name: "default only",
+			want: []string{filepath.Join(home, ".claude")},
+		},
+		{
+			name:      "CLAUDE_CONFIG_DIR replaces the default",
+			configDir: "/work/claude",
+			want:      []string{"/work/claude"},
+		},
+		{
+			name: "env list",
+			env:  "/a" + string(os.PathListSeparator) + "~/b" + string(os.PathListSeparator),
+			want: []string{filepath.Join(home, ".claude"), "/a", filepath.Join(home, "b")},
+		},
+		{
+			name:       "settings after env, deduped",
+			env:        "/a",
+			configured: []string{"/a/", "~/.claude", "/c"},
+			want:       []string{filepath.Join(home, ".claude"), "/a", "/c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CLAUDE_CONFIG_DIR", tt.configDir)
+			t.Setenv(DataRootsEnv, tt.env)
+			setConfiguredRoots(tt.configured)
+
+			if got := DataRoots(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DataRoots() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSessionConfigDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	tests := []struct {
+		dataRoot string
+		want     string
+	}{
+		{"", ""},
+		{filepath.Join(home, ".claude"), ""},
+		{filepath.Join(home, ".claude") + "/", ""},
+		{"/work/claude", "/work/claude"},
+	}
+
+	for _, tt := range tests {
+		s := &Session{DataRoot: tt.dataRoot}
+		if got := s.ConfigDir(); got != tt.want {
+			t.Errorf("ConfigDir() with root %q = %q, want %q", tt.dataRoot, got, tt.want)
+		}
+	}
+}
+
+func TestDiscoverSessionsMultipleRoots(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("CLAUDE_CONFIG_DIR", "")
+	other := filepath.Join(home, "work-claude")
+	t.Setenv(DataRootsEnv, other+string(os.PathListSeparator)+filepath.Join(home, "missing"))
+
+	id1 := "550e8400-e29b-41d4-a716-446655440000"
+	id2 := "550e8400-e29b-41d4-a716-446655440001"
+	writeTestSessionFile(t, home, "/work/api", id1)
+	dir := filepath.Join(other, "projects", EncodeProjectPath("/work/web"))
+	os.MkdirAll(dir, 0755)
+	content := `{"type":"user","cwd":"/work/web","message":{"role":"user","content":"hi"}}` + "\n"
+	if err := os.WriteFile(filepath.Join(dir, id2+".jsonl"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions, err := DiscoverSessions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := make(map[string]string)
+	for _, s := range sessions {
+		roots[s.ClaudeSessionID] = s.DataRoot
+	}
+	want := map[string]string{id1: filepath.Join(home, ".claude"), id2: other}
+	if !reflect.DeepEqual(roots, want) {
+		t.Errorf("session roots = %v, want %v", roots, want)
+	}
+
+	if got := GetSessionUUIDsAtPath("/work/web"); len(got) != 1 || got[0] != id2 {
+		t.Errorf("GetSessionUUIDsAtPath in second root = %v, want [%s]", got, id2)
+	}
+}
@@ -0,0 +1,399 @@
+package session
+
+import (
+	"context"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// maxSearchHits is how many matching messages a search result keeps per session
+const maxSearchHits = 5
+
+// SearchHit is one matching message of a session
+type SearchHit struct {
+	Offset    int64 // byte offset of the message's JSONL line (see ReadConversation)
+	Index     int   // position among the session's text messages
+	Role      string
+	Timestamp time.Time
+	Snippet   string // context around the match
+	Match     string // the text that matched, lowercase (for highlighting)
+	Score     float64
+}
+
+// SearchResult represents a content search match
+// Snippet and Role are those of the best hit.
+type SearchResult struct {
+	Session *Session
+	Snippet string // Context around the match
+	Role    string // user or assistant
+	Score   float64
+	Hits    []SearchHit // best first
+}
+
+// SearchContent searches through session content for a query string
+// Only searches user/assistant text, not tool calls or bash commands.
+// Results are ranked, best first; nil if ctx is cancelled.
+func SearchContent(ctx context.Context, sessions []*Session, query string) []SearchResult {
+	return contentIndex.Search(ctx, sessions, query)
+}
+
+// UpdateSearchIndex reads what was appended to a JSONL the content index holds
+// Files that haven't been searched yet are left for the first search.
+func UpdateSearchIndex(path string) {
+	contentIndex.mu.RLock()
+	_, indexed := contentIndex.docs[path]
+	contentIndex.mu.RUnlock()
+	if indexed {
+		contentIndex.update(path)
+	}
+}
+
+// contentIndex is the content search index, filled lazily by the first search
+var contentIndex = newSearchIndex()
+
+// searchIndex is an in-memory inverted index over user/assistant message text
+type searchIndex struct {
+	mu    sync.RWMutex
+	docs  map[string]*searchDoc         // by JSONL path
+	terms map[string]map[string][]int32 // term -> JSONL path -> message indexes
+	grams map[string]map[string]bool    // trigram -> terms containing it
+}
+
+// searchDoc is the indexed part of one JSONL
+type searchDoc struct {
+	size     int64 // file size and mtime when last read
+	modTime  time.Time
+	next     int64 // offset after the last complete line read
+	messages []searchMessage
+	terms    map[string]bool // terms with postings for this file
+}
+
+// searchMessage is the text of one indexed message
+type searchMessage struct {
+	offset    int64
+	role      string
+	timestamp time.Time
+	text      string
+}
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{
+		docs:  make(map[string]*searchDoc),
+		terms: make(map[string]map[string][]int32),
+		grams: make(map[string]map[string]bool),
+	}
+}
+
+// update brings a file's entry up to date, reading only appended lines
+// A file that shrank was rewritten and is indexed from scratch; one that's gone is dropped.
+func (idx *searchIndex) update(path string) {
+	for !idx.tryUpdate(path) {
+	}
+}
+
+// tryUpdate reads the lines appended since the entry was last updated. It returns
+// false if another update changed the entry in the meantime, so the lines read
+// may already be indexed and must be read again from the new offset.
+func (idx *searchIndex) tryUpdate(path string) bool {
+	info, err := os.Stat(path)
+	idx.mu.RLock()
+	doc := idx.docs[path]
+	var size, next int64
+	var modTime time.Time
+	if doc != nil {
+		size, modTime, next = doc.size, doc.modTime, doc.next
+	}
+	idx.mu.RUnlock()
+	if err != nil {
+		if doc != nil {
+			idx.mu.Lock()
+			idx.remove(path)
+			idx.mu.Unlock()
+		}
+		return true
+	}
+	if doc != nil && size == info.Size() && modTime.Equal(info.ModTime()) {
+		return true
+	}
+
+	from := int64(0)
+	if doc != nil && info.Size() >= next {
+		from = next
+	}
+	page, err := ReadConversation(path, from, math.MaxInt32)
+	if err != nil {
+		return true
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	// Another update may have got here first (appends change doc in place)
+	if current := idx.docs[path]; current != doc || (doc != nil && doc.next != next) {
+		return false
+	}
+	if from == 0 {
+		if doc != nil {
+			idx.remove(path)
+		}
+		doc = &searchDoc{terms: make(map[string]bool)}
+		idx.docs[path] = doc
+	}
+	doc.size, doc.modTime, doc.next = info.Size(), info.ModTime(), page.Next
+
+	for _, msg := range page.Messages {
+		if msg.Content == "" {
+			continue
+		}
+		n := int32(len(doc.messages))
+		doc.messages = append(doc.messages, searchMessage{
+			offset:    msg.Offset,
+			role:      msg.Role,
+			timestamp: msg.Timestamp,
+			text:      msg.Content,
+		})
+		for _, term := range searchTerms(msg.Content) {
+			postings := idx.terms[term]
+			if postings == nil {
+				postings = make(map[string][]int32)
+				idx.terms[term] = postings
+				idx.addGrams(term)
+			}
+			postings[path] = append(postings[path], n)
+			doc.terms[term] = true
+		}
+	}
+	return true
+}
+
+// remove drops a file's entry and postings (mu must be held)
+func (idx *searchIndex) remove(path string) {
+	doc := idx.docs[path]
+	if doc == nil {
+		return
+	}
+	for term := range doc.terms {
+		delete(idx.terms[term], path)
+		if len(idx.terms[term]) == 0 {
+			delete(idx.terms, term)
+			idx.removeGrams(term)
+		}
+	}
+	delete(idx.docs, path)
+}
+
+// addGrams adds a new term under each of its trigrams (mu must be held)
+func (idx *searchIndex) addGrams(term string) {
+	for i := 0; i+3 <= len(term); i++ {
+		terms := idx.grams[term[i:i+3]]
+		if terms == nil {
+			terms = make(map[string]bool)
+			idx.grams[term[i:i+3]] = terms
+		}
+		terms[term] = true
+	}
+}
+
+// removeGrams drops a term that's no longer indexed from its trigrams (mu must be held)
+func (idx *searchIndex) removeGrams(term string) {
+	for i := 0; i+3 <= len(term); i++ {
+		gram := term[i : i+3]
+		delete(idx.grams[gram], term)
+		if len(idx.grams[gram]) == 0 {
+			delete(idx.grams, gram)
+		}
+	}
+}
+
+// matchingTerms returns the indexed terms containing word (mu must be held)
+// Words of three or more bytes are looked up by their rarest trigram; shorter
+// ones only match whole terms.
+func (idx *searchIndex) matchingTerms(word string) []string {
+	if len(word) < 3 {
+		if idx.terms[word] != nil {
+			return []string{word}
+		}
+		return nil
+	}
+	var rarest map[string]bool
+	for i := 0; i+3 <= len(word); i++ {
+		terms := idx.grams[word[i:i+3]]
+		if len(terms) == 0 {
+			return nil
+		}
+		if rarest == nil || len(terms) < len(rarest) {
+			rarest = terms
+		}
+	}
+	var matches []string
+	for term := range rarest {
+		if strings.Contains(term, word) {
+			matches = append(matches, term)
+		}
+	}
+	return matches
+}
+
+// msgRef identifies a message in the index
+type msgRef struct {
+	path string
+	msg  int32
+}
+
+// Search returns the sessions whose messages contain every word of the query,
+// best first. The query is plain text: words match anywhere in a word
+// ("config" finds "reconfigure", though words under three letters only match
+// whole words), and messages with the whole query as a phrase rank higher. Sessions' files are brought up to date first.
+func (idx *searchIndex) Search(ctx context.Context, sessions []*Session, query string) []SearchResult {
+	queryTerms := searchTerms(query)
+	if len(queryTerms) == 0 || len(sessions) == 0 {
+		return nil
+	}
+
+	bySession := make(map[string]*Session, len(sessions))
+	for _, s := range sessions {
+		if s.JSONLPath != "" {
+			bySession[s.JSONLPath] = s
+		}
+	}
+	paths := make([]string, 0, len(bySession))
+	for path := range bySession {
+		paths = append(paths, path)
+	}
+	for range Parallel(ctx, len(paths), func(i int) struct{} {
+		idx.update(paths[i])
+		return struct{}{}
+	}) {
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	total := 0
+	for path := range bySession {
+		if doc := idx.docs[path]; doc != nil {
+			total += len(doc.messages)
+		}
+	}
+
+	// Messages containing each query word, and how rare the word is
+	var candidates map[msgRef]bool
+	weights := make([]float64, len(queryTerms))
+	for i, qt := range queryTerms {
+		found := make(map[msgRef]bool)
+		for _, term := range idx.matchingTerms(qt) {
+			for path, msgs := range idx.terms[term] {
+				if bySession[path] == nil {
+					continue
+				}
+				for _, m := range msgs {
+					found[msgRef{path, m}] = true
+				}
+			}
+		}
+		if len(found) == 0 {
+			return nil
+		}
+		weights[i] = math.Log(1 + float64(total)/float64(len(found)))
+
+		if candidates == nil {
+			candidates = found
+			continue
+		}
+		for ref := range candidates {
+			if !found[ref] {
+				delete(candidates, ref)
+			}
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	phrase := strings.ToLower(strings.Join(strings.Fields(query), " "))
+	results := make(map[string]*SearchResult)
+	for ref := range candidates {
+		msg := idx.docs[ref.path].messages[ref.msg]
+		lower := strings.ToLower(msg.text)
+		score := 0.0
+		for i, qt := range queryTerms {
+			if tf := strings.Count(lower, qt); tf > 0 {
+				score += weights[i] * (1 + math.Log(float64(tf)))
+			}
+		}
+		needle := queryTerms[0]
+		if strings.Contains(lower, phrase) {
+			needle = phrase
+			if len(queryTerms) > 1 {
+				score *= 2
+			}
+		}
+
+		r := results[ref.path]
+		if r == nil {
+			r = &SearchResult{Session: bySession[ref.path]}
+			results[ref.path] = r
+		}
+		r.Score += score
+		// Snippets are cut from the original text unless lowercasing moved the match
+		text := msg.text
+		if len(text) != len(lower) {
+			text = lower
+		}
+		r.Hits = append(r.Hits, SearchHit{
+			Offset:    msg.offset,
+			Index:     int(ref.msg),
+			Role:      msg.role,
+			Timestamp: msg.timestamp,
+			Snippet:   extractSnippetWithContext(text, needle),
+			Match:     needle,
+			Score:     score,
+		})
+	}
+
+	ranked := make([]SearchResult, 0, len(results))
+	for _, r := range results {
+		sort.Slice(r.Hits, func(i, j int) bool {
+			if r.Hits[i].Score != r.Hits[j].Score {
+				return r.Hits[i].Score > r.Hits[j].Score
+			}
+			return r.Hits[i].Offset < r.Hits[j].Offset
+		})
+		if len(r.Hits) > maxSearchHits {
+			r.Hits = r.Hits[:maxSearchHits]
+		}
+		r.Snippet, r.Role = r.Hits[0].Snippet, r.Hits[0].Role
+		ranked = append(ranked, *r)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].Session.LastAccessedAt.After(ranked[j].Session.LastAccessedAt)
+	})
+	return ranked
+}
+
+// searchTerms splits text into distinct lowercase words
+func searchTerms(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	seen := make(map[string]bool, len(words))
+	terms := words[:0]
+	for _, w := range words {
+		if !seen[w] {
+			seen[w] = true
+			terms = append(terms, w)
+		}
+	}
+	return terms
+}
@@ -0,0 +1,125 @@
+package session
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func writeSearchSession(t *testing.T, dir, name string, messages ...string) *Session {
+	t.Helper()
+	var b strings.Builder
+	for i, text := range messages {
+		role := "user"
+		if i%2 == 1 {
+			role = "assistant"
+		}
+		b.WriteString(`{"type":"` + role + `","message":{"role":"` + role + `","content":[{"type":"text","text":"` + text + `"}]}}` + "\n")
+	}
+	path := filepath.Join(dir, name+".jsonl")
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return &Session{ID: name, JSONLPath: path}
+}
+
+func TestSearchTerms(t *testing.T) {
+	got := searchTerms("Fix the (flaky) test, THE test!")
+	want := []string{"fix", "the", "flaky", "test"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("searchTerms() = %q, want %q", got, want)
+	}
+}
+
+func TestSearchIndex(t *testing.T) {
+	dir := t.TempDir()
+	once := writeSearchSession(t, dir, "once", "the login page is slow", "ok")
+	often := writeSearchSession(t, dir, "often", "login fails", "the login page returns 500", "fix the login page", "done")
+	other := writeSearchSession(t, dir, "other", "unrelated", "nothing here")
+	tool := &Session{ID: "tool", JSONLPath: filepath.Join(dir, "tool.jsonl")}
+	os.WriteFile(tool.JSONLPath, []byte(`{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"grep login page"}}]}}`+"\n"), 0644)
+	sessions := []*Session{once, often, other, tool}
+
+	ctx := context.Background()
+	idx := newSearchIndex()
+	results := idx.Search(ctx, sessions, "login page")
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2 (tool calls aren't searched)", len(results))
+	}
+	if results[0].Session != often || results[1].Session != once {
+		t.Errorf("ranking = %s, %s; want often, once", results[0].Session.ID, results[1].Session.ID)
+	}
+	if len(results[0].Hits) != 2 || results[0].Snippet != results[0].Hits[0].Snippet {
+		t.Errorf("hits = %+v, want the two messages with the phrase", results[0].Hits)
+	}
+	first, second := results[0].Hits[0], results[0].Hits[1]
+	if first.Offset == 0 || first.Index != 1 || first.Role != "assistant" || first.Match != "login page" {
+		t.Errorf("first hit = %+v, want the assistant's message 1 at its JSONL line", first)
+	}
+	if second.Index != 2 || second.Role != "user" || second.Offset <= first.Offset {
+		t.Errorf("second hit = %+v, want the user's message 2", second)
+	}
+
+	// Words match inside longer words; regex metacharacters are plain text
+	if r := idx.Search(ctx, sessions, "relat"); len(r) != 1 || r[0].Session != other {
+		t.Errorf("partial word search = %+v", r)
+	}
+	if r := idx.Search(ctx, sessions, "500 ("); len(r) != 1 || r[0].Session != often {
+		t.Errorf("search with metacharacters = %+v", r)
+	}
+	if r := idx.Search(ctx, sessions, "login unrelated"); r != nil {
+		t.Errorf("every word must match in one message, got %+v", r)
+	}
+	// Words under three letters match whole words only
+	if r := idx.Search(ctx, sessions, "is"); len(r) != 1 || r[0].Session != once {
+		t.Errorf("short word search = %+v", r)
+	}
+
+	// Appended lines are indexed incrementally, rewrites from scratch
+	f, _ := os.OpenFile(other.JSONLPath, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString(`{"type":"user","message":{"role":"user","content":"now the login page too"}}` + "\n")
+	f.Close()
+	if r := idx.Search(ctx, sessions, "login page"); len(r) != 3 {
+		t.Errorf("after append got %d results, want 3", len(r))
+	}
+	writeSearchSession(t, dir, "often", "rewritten")
+	if r := idx.Search(ctx, sessions, "login page"); len(r) != 2 {
+		t.Errorf("after rewrite got %d results, want 2", len(r))
+	}
+	if len(idx.terms["fails"]) != 0 || len(idx.grams["ils"]) != 0 {
+		t.Error("rewritten file's old terms should be dropped")
+	}
+}
+
+func TestSearchIndexConcurrentUpdates(t *testing.T) {
+	dir := t.TempDir()
+	s := writeSearchSession(t, dir, "busy", "first")
+	idx := newSearchIndex()
+	idx.update(s.JSONLPath)
+
+	// Updates racing over the same appended lines index them once
+	f, _ := os.OpenFile(s.JSONLPath, os.O_APPEND|os.O_WRONLY, 0644)
+	for i := 0; i < 2000; i++ {
+		f.WriteString(`{"type":"user","message":{"role":"user","content":"more"}}` + "\n")
+	}
+	f.Close()
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			idx.update(s.JSONLPath)
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if n := len(idx.docs[s.JSONLPath].messages); n != 2001 {
+		t.Errorf("indexed %d messages, want 2001", n)
+	}
+}
@@ -1,6 +1,7 @@
 package session
 
 import (
+	"strings"
 	"time"
 )
 
@@ -11,6 +12,9 @@ const (
 	StatusIdle Status = iota
 	StatusWaiting
 	StatusRunning
+	StatusNeedsPermission // tab open, Claude asked for a tool that hasn't run yet
+	StatusAwaitingReply   // tab open, Claude finished its turn
+	StatusErrored         // tab open, last response was an API error
 )
 
 func (s Status) String() string {
@@ -19,6 +23,12 @@ func (s Status) String() string {
 		return "running"
 	case StatusWaiting:
 		return "waiting"
+	case StatusNeedsPermission:
+		return "needs permission"
+	case StatusAwaitingReply:
+		return "awaiting reply"
+	case StatusErrored:
+		return "errored"
 	default:
 		return "idle"
 	}
@@ -31,11 +41,28 @@ func (s Status) Symbol() string {
 		return "●"
 	case StatusWaiting:
 		return "◎"
+	case StatusNeedsPermission:
+		return "◆"
+	case StatusAwaitingReply:
+		return "◉"
+	case StatusErrored:
+		return "✗"
 	default:
 		return "○"
 	}
 }
 
+// IsActive returns true if the session has an open terminal tab
+func (s Status) IsActive() bool {
+	return s != StatusIdle
+}
+
+// IsWaiting returns true if the session's tab is open but Claude isn't working,
+// whether or not the transcript says why
+func (s Status) IsWaiting() bool {
+	return s.IsActive() && s != StatusRunning
+}
+
 // Session represents a Claude Code session
 type Session struct {
 	ID              string    `json:"id"`
@@ -48,18 +75,28 @@ type Session struct {
 	Pinned          bool      `json:"pinned"`
 	CreatedAt       time.Time `json:"created_at"`
 	LastAccessedAt  time.Time `json:"last_accessed_at"`
-	KittyWindowID   int       `json:"kitty_window_id,omitempty"` // Window ID when opened in kitty
+	KittyWindowID   int       `json:"kitty_window_id,omitempty"` // Terminal window ID when opened (name kept for compatibility)
 
 	// Runtime fields (not persisted)
 	Status       Status `json:"-"`
 	JSONLPath    string `json:"-"`
 	MessageCount int    `json:"-"`
 	Title        string `json:"-"` // Extracted from first user message
+	DataRoot     string `json:"-"` // Claude config directory the JSONL lives in (see DataRoots)
+	Usage        Usage  `json:"-"` // Tokens Claude used, by month and model
 
 	// Git info (from Claude's JSONL - branch at time of session)
 	GitBranch string `json:"-"`
 }
 
+// ShortID returns the abbreviated Claude session ID (pending IDs are kept whole)
+func (s *Session) ShortID() string {
+	if len(s.ClaudeSessionID) > 8 && !strings.HasPrefix(s.ClaudeSessionID, PendingSessionPrefix) {
+		return s.ClaudeSessionID[:8]
+	}
+	return s.ClaudeSessionID
+}
+
 // FolderName returns just the folder name from ProjectPath
 func (s *Session) FolderName() string {
 	if s.ProjectPath == "" {
@@ -121,4 +158,5 @@ type PreviewMessage struct {
 	Role      string
 	Content   string
 	Timestamp time.Time
+	Tools     []ToolCall // tool calls made by the message (GetPreviewWithTools only)
 }
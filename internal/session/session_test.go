@@ -12,6 +12,9 @@ func TestStatusString(t *testing.T) {
 		{StatusIdle, "idle"},
 		{StatusWaiting, "waiting"},
 		{StatusRunning, "running"},
+		{StatusNeedsPermission, "needs permission"},
+		{StatusAwaitingReply, "awaiting reply"},
+		{StatusErrored, "errored"},
 	}
 
 	for _, tt := range tests {
@@ -31,6 +34,9 @@ func TestStatusSymbol(t *testing.T) {
 		{StatusIdle, "○"},
 		{StatusWaiting, "◎"},
 		{StatusRunning, "●"},
+		{StatusNeedsPermission, "◆"},
+		{StatusAwaitingReply, "◉"},
+		{StatusErrored, "✗"},
 	}
 
 	for _, tt := range tests {
@@ -42,6 +48,17 @@ func TestStatusSymbol(t *testing.T) {
 	}
 }
 
+func TestStatusIsActive(t *testing.T) {
+	if StatusIdle.IsActive() {
+		t.Error("idle should not be active")
+	}
+	for _, s := range []Status{StatusWaiting, StatusRunning, StatusNeedsPermission, StatusAwaitingReply, StatusErrored} {
+		if !s.IsActive() {
+			t.Errorf("%s should be active", s)
+		}
+	}
+}
+
 func TestSplitPath(t *testing.T) {
 	tests := []struct {
 		path string
@@ -0,0 +1,227 @@
+package session
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	_ "modernc.org/sqlite" // pure-Go driver, no cgo
+)
+
+// sqliteSchemaVersion is the sessions.db layout version (PRAGMA user_version)
+const sqliteSchemaVersion = 1
+
+// Each record is stored as its JSON encoding, so Session and Group fields can be
+// added without a table migration.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS sessions (
+	claude_session_id TEXT PRIMARY KEY,
+	data TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS "groups" (
+	id TEXT PRIMARY KEY,
+	data TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS settings (
+	key TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);`
+
+// sqliteTables maps each table to its key and value columns
+var sqliteTables = map[string][2]string{
+	"sessions": {"claude_session_id", "data"},
+	"groups":   {"id", "data"},
+	"settings": {"key", "value"},
+}
+
+// SQLiteFile returns the path of the SQLite metadata store
+func SQLiteFile() string {
+	return filepath.Join(StorageDir(), "sessions.db")
+}
+
+// recordKey identifies one row
+type recordKey struct {
+	table string
+	id    string
+}
+
+// sqliteStore keeps metadata in SQLite, one row per session, group and setting
+type sqliteStore struct {
+	db *sql.DB
+
+	// saved holds every row as this store last loaded or wrote it.
+	// Save only writes rows that differ, so changes another deck made to
+	// other rows are kept.
+	saved map[recordKey][]byte
+}
+
+// openSQLiteStore opens (or creates) the store at path
+// A newly created store imports from (normally the current sessions.json).
+func openSQLiteStore(path string, from *StorageData) (*sqliteStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	_, statErr := os.Stat(path)
+	created := os.IsNotExist(statErr)
+
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	s := &sqliteStore{db: db, saved: make(map[recordKey][]byte)}
+
+	if err := s.init(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if created && from != nil {
+		if err := s.Save(from); err != nil {
+			db.Close()
+			for _, f := range []string{path, path + "-wal", path + "-shm"} {
+				os.Remove(f)
+			}
+			return nil, fmt.Errorf("importing sessions.json: %w", err)
+		}
+	}
+	return s, nil
+}
+
+// init creates the tables and checks the layout version
+func (s *sqliteStore) init() error {
+	var version int
+	if err := s.db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return err
+	}
+	if version > sqliteSchemaVersion {
+		return fmt.Errorf("sessions.db was written by a newer version of claude-deck (schema v%d, this build supports up to v%d) - upgrade claude-deck to use it",
+			version, sqliteSchemaVersion)
+	}
+	if _, err := s.db.Exec(sqliteSchema); err != nil {
+		return err
+	}
+	_, err := s.db.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, sqliteSchemaVersion))
+	return err
+}
+
+func (s *sqliteStore) Load() (*StorageData, error) {
+	data := &StorageData{
+		Version:  SchemaVersion,
+		Sessions: make([]*Session, 0),
+		Groups:   make([]*Group, 0),
+		Settings: &Settings{},
+	}
+	saved := make(map[recordKey][]byte)
+	settings := make(map[string]json.RawMessage)
+
+	for table := range sqliteTables {
+		columns := sqliteTables[table]
+		rows, err := s.db.Query(fmt.Sprintf(`SELECT %s, %s FROM "%s" ORDER BY rowid`, columns[0], columns[1], table))
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var id string
+			var content []byte
+			if err := rows.Scan(&id, &content); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			saved[recordKey{table, id}] = content
+
+			switch table {
+			case "sessions":
+				sess := &Session{}
+				err = json.Unmarshal(content, sess)
+				data.Sessions = append(data.Sessions, sess)
+			case "groups":
+				g := &Group{}
+				err = json.Unmarshal(content, g)
+				data.Groups = append(data.Groups, g)
+			case "settings":
+				settings[id] = json.RawMessage(content)
+			}
+			if err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("sessions.db %s %q: %w", table, id, err)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(settings) > 0 {
+		content, _ := json.Marshal(settings)
+		if err := json.Unmarshal(content, data.Settings); err != nil {
+			return nil, fmt.Errorf("sessions.db settings: %w", err)
+		}
+	}
+
+	s.saved = saved
+	return data, nil
+}
+
+// Save writes the sessions, groups and settings that changed since the last Load
+// or Save in one transaction, and deletes the ones that were removed
+func (s *sqliteStore) Save(data *StorageData) error {
+	records := make(map[recordKey][]byte)
+	for _, sess := range data.Sessions {
+		content, err := json.Marshal(sess)
+		if err != nil {
+			return err
+		}
+		records[recordKey{"sessions", sess.ClaudeSessionID}] = content
+	}
+	for _, g := range data.Groups {
+		content, err := json.Marshal(g)
+		if err != nil {
+			return err
+		}
+		records[recordKey{"groups", g.ID}] = content
+	}
+	for key, value := range jsonFields(data.Settings) {
+		records[recordKey{"settings", key}] = value
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for key, content := range records {
+		if prev, ok := s.saved[key]; ok && bytes.Equal(prev, content) {
+			continue
+		}
+		columns := sqliteTables[key.table]
+		query := fmt.Sprintf(`INSERT INTO "%s" (%s, %s) VALUES (?, ?) ON CONFLICT(%s) DO UPDATE SET %s = excluded.%s`,
+			key.table, columns[0], columns[1], columns[0], columns[1], columns[1])
+		if _, err := tx.Exec(query, key.id, string(content)); err != nil {
+			return err
+		}
+	}
+	for key := range s.saved {
+		if _, ok := records[key]; ok {
+			continue
+		}
+		columns := sqliteTables[key.table]
+		if _, err := tx.Exec(fmt.Sprintf(`DELETE FROM "%s" WHERE %s = ?`, key.table, columns[0]), key.id); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	s.saved = records
+	return nil
+}
+
+func (s *sqliteStore) Close() error {
+	return s.db.Close()
+}
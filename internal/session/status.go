@@ -1,36 +1,17 @@
 package session
 
 import (
-	"encoding/json"
 	"os"
-	"os/exec"
 	"sort"
 	"strings"
 	"time"
-)
-
-// kittyWindow represents a window in kitty's JSON output
-type kittyWindow struct {
-	ID      int      `json:"id"`
-	Title   string   `json:"title"`
-	Cmdline []string `json:"cmdline"`
-	Cwd     string   `json:"cwd"`
-}
-
-// kittyTab represents a tab in kitty's JSON output
-type kittyTab struct {
-	Title   string        `json:"title"`
-	Windows []kittyWindow `json:"windows"`
-}
 
-// kittyOSWindow represents an OS window in kitty's JSON output
-type kittyOSWindow struct {
-	Tabs []kittyTab `json:"tabs"`
-}
+	"github.com/hadar/claude-deck/internal/terminal"
+)
 
-// activeSession tracks info about an active session in kitty
+// activeSession tracks info about an active session in the terminal
 type activeSession struct {
-	windowID    int    // unique ID from kitty
+	windowID    int    // unique ID from the terminal backend
 	sessionID   string // from --resume flag, if present
 	projectPath string // cwd of the window
 	tabTitle    string // title of the tab (for name sync)
@@ -64,8 +45,8 @@ func computeStatusesInternal(sessions []*Session, aggressive bool) ([]StatusUpda
 	namesChanged := false
 	anyChanged := false
 
-	// Get active kitty sessions
-	activeSessions := getKittyActiveSessions()
+	// Get active terminal sessions
+	activeSessions := getActiveSessions()
 
 	// Build map of all active window IDs
 	activeWindowIDs := make(map[int]bool)
@@ -105,6 +86,14 @@ func computeStatusesInternal(sessions []*Session, aggressive bool) ([]StatusUpda
 	for _, sw := range sortable {
 		status, tabTitle, windowID, strongMatch := detectSessionStatus(sw.session, activeSessions, matchedWindows)
 
+		// The tab is open but Claude isn't spinning - the transcript tail tells us why
+		if status == StatusWaiting {
+			status = transcriptStatus(sw.session.JSONLPath)
+		}
+
+		// Events pushed by Claude Code hooks are authoritative when installed
+		status = hookStatus(sw.session.ClaudeSessionID, status)
+
 		update := StatusUpdate{
 			SessionID:     sw.session.ClaudeSessionID,
 			Status:        status,
@@ -235,13 +224,27 @@ func ClaimWindowID(sessions []*Session, claimingSession *Session, windowID int)
 	return modified
 }
 
-// GetActiveWindowID returns the kitty window ID for a session if it has an active tab
+// RetitleTab sets the title of a session's open terminal tab, if it has one
+// An empty name lets the tab follow the window's dynamic title again.
+func RetitleTab(s *Session, name string) error {
+	windowID := GetActiveWindowID(s)
+	if windowID == 0 {
+		return nil
+	}
+	if name == "" {
+		return terminal.ResetTabTitle(windowID)
+	}
+	return terminal.SetTabTitle(windowID, name)
+}
+
+// GetActiveWindowID returns the terminal window ID for a session if it has an active tab
 // Returns 0 if no active tab found
 func GetActiveWindowID(s *Session) int {
+	activeSessions := getActiveSessions()
+
 	// First check if we have a stored window ID
 	if s.KittyWindowID > 0 {
 		// Verify it's still active
-		activeSessions := getKittyActiveSessions()
 		for _, active := range activeSessions {
 			if active.windowID == s.KittyWindowID {
 				return s.KittyWindowID
@@ -251,8 +254,6 @@ func GetActiveWindowID(s *Session) int {
 		s.KittyWindowID = 0
 	}
 
-	activeSessions := getKittyActiveSessions()
-
 	// Check for session ID match
 	for _, active := range activeSessions {
 		if active.sessionID != "" && active.sessionID == s.ClaudeSessionID {
@@ -310,76 +311,64 @@ func cleanClaudeTitle(title string) string {
 	return strings.TrimSpace(title)
 }
 
-// getKittyActiveSessions returns info about active claude sessions in kitty
-func getKittyActiveSessions() []activeSession {
+// getActiveSessions returns info about active claude sessions in the terminal backend
+func getActiveSessions() []activeSession {
 	var result []activeSession
 
-	// Try kitty @ ls
-	cmd := exec.Command("kitty", "@", "ls")
-	output, err := cmd.Output()
+	windows, err := terminal.ListWindows()
 	if err != nil {
 		return result
 	}
 
-	var osWindows []kittyOSWindow
-	if err := json.Unmarshal(output, &osWindows); err != nil {
-		return result
-	}
-
 	// Look for claude processes
-	for _, osWin := range osWindows {
-		for _, tab := range osWin.Tabs {
-			for _, win := range tab.Windows {
-				cmdline := strings.Join(win.Cmdline, " ")
-
-				// Check if this window is running claude:
-				// - cmdline contains "claude", OR
-				// - tab/window title has Claude indicator (✳ for unsaved, ⠂⠄⠆⠇⠃⠁ for spinner)
-				isClaudeTab := strings.Contains(cmdline, "claude") ||
-					hasClaudeIndicator(tab.Title) ||
-					hasClaudeIndicator(win.Title)
-
-				if !isClaudeTab {
-					continue
-				}
+	for _, win := range windows {
+		cmdline := strings.Join(win.Cmdline, " ")
 
-				// Use window title for name (more accurate than tab title)
-				title := win.Title
-				if title == "" {
-					title = tab.Title
-				}
+		// Check if this window is running claude:
+		// - cmdline contains "claude", OR
+		// - tab/window title has Claude indicator (✳ for unsaved, ⠂⠄⠆⠇⠃⠁ for spinner)
+		isClaudeTab := strings.Contains(cmdline, "claude") ||
+			hasClaudeIndicator(win.TabTitle) ||
+			hasClaudeIndicator(win.Title)
 
-				active := activeSession{
-					windowID:    win.ID,
-					projectPath: win.Cwd,
-					tabTitle:    title,
-					hasSpinner:  hasSpinnerIndicator(title),
-				}
+		if !isClaudeTab {
+			continue
+		}
 
-				// Extract session ID from --resume flag
-				// Could be direct arg or inside a zsh -c '...' string
-				if idx := strings.Index(cmdline, "--resume "); idx != -1 {
-					rest := cmdline[idx+9:] // skip "--resume "
-					// Extract the session ID (UUID format: 8-4-4-4-12 hex chars)
-					// Stop at any non-UUID character
-					sessionID := ""
-					for _, c := range rest {
-						if (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F') || c == '-' {
-							sessionID += string(c)
-						} else {
-							break
-						}
-					}
-					if sessionID != "" {
-						active.sessionID = sessionID
-					}
-				}
+		// Use window title for name (more accurate than tab title)
+		title := win.Title
+		if title == "" {
+			title = win.TabTitle
+		}
 
-				result = append(result, active)
+		active := activeSession{
+			windowID:    win.ID,
+			projectPath: win.Cwd,
+			tabTitle:    title,
+			hasSpinner:  hasSpinnerIndicator(title),
+		}
+
+		// Extract session ID from --resume flag
+		// Could be direct arg or inside a zsh -c '...' string
+		if idx := strings.Index(cmdline, "--resume "); idx != -1 {
+			rest := cmdline[idx+9:] // skip "--resume "
+			// Extract the session ID (UUID format: 8-4-4-4-12 hex chars)
+			// Stop at any non-UUID character
+			sessionID := ""
+			for _, c := range rest {
+				if (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F') || c == '-' {
+					sessionID += string(c)
+				} else {
+					break
+				}
+			}
+			if sessionID != "" {
+				active.sessionID = sessionID
 			}
 		}
-	}
 
+		result = append(result, active)
+	}
 	// Sort tabs with spinners first (actively working Claude) to match most recent sessions
 	sort.Slice(result, func(i, j int) bool {
 		if result[i].hasSpinner != result[j].hasSpinner {
@@ -394,11 +383,11 @@ func getKittyActiveSessions() []activeSession {
 // detectSessionStatus determines the status of a session
 // Returns (status, tabTitle, windowID, strongMatch) where:
 // - tabTitle is non-empty if matched to an active tab
-// - windowID is the matched kitty window ID (for storing back on session)
+// - windowID is the matched terminal window ID (for storing back on session)
 // - strongMatch is true if we're confident this is the right tab (--resume or stored window ID)
 func detectSessionStatus(s *Session, activeSessions []activeSession, matchedWindows map[int]bool) (Status, string, int, bool) {
-	// Check if session has an active kitty tab
-	hasKittyTab := false
+	// Check if session has an active terminal tab
+	hasTab := false
 	tabTitle := ""
 	matchedWindowID := 0
 	strongMatch := false // true if we're confident about the match (--resume or stored window ID)
@@ -419,7 +408,7 @@ func detectSessionStatus(s *Session, activeSessions []activeSession, matchedWind
 				if active.sessionID != "" && active.sessionID != s.ClaudeSessionID {
 					break // Window was reused for different session
 				}
-				hasKittyTab = true
+				hasTab = true
 				tabTitle = active.tabTitle
 				matchedWindowID = active.windowID
 				strongMatch = true // We trust stored window IDs
@@ -431,14 +420,14 @@ func detectSessionStatus(s *Session, activeSessions []activeSession, matchedWind
 	}
 
 	// 2. Then try to match by session ID from --resume flag (most reliable - ground truth)
-	if !hasKittyTab {
+	if !hasTab {
 		for _, active := range activeSessions {
 			if active.sessionID != "" && active.sessionID == s.ClaudeSessionID {
 				// Skip if already matched to another session (shouldn't happen, but safety check)
 				if matchedWindows[active.windowID] {
 					continue
 				}
-				hasKittyTab = true
+				hasTab = true
 				tabTitle = active.tabTitle
 				matchedWindowID = active.windowID
 				strongMatch = true // --resume is ground truth
@@ -450,7 +439,7 @@ func detectSessionStatus(s *Session, activeSessions []activeSession, matchedWind
 	}
 
 	// 3. For sessions without direct match, check by project path (WEAK match - don't sync names)
-	if !hasKittyTab && s.JSONLPath != "" {
+	if !hasTab && s.JSONLPath != "" {
 		for _, active := range activeSessions {
 			if active.sessionID != "" {
 				continue // Skip tabs that have explicit session IDs
@@ -471,19 +460,20 @@ func detectSessionStatus(s *Session, activeSessions []activeSession, matchedWind
 			// strongMatch stays false - path matching is unreliable
 			matchedWindows[active.windowID] = true
 			matchedWindowID = active.windowID
-			hasKittyTab = true
+			hasTab = true
 			tabTitle = active.tabTitle
 			hasSpinner = active.hasSpinner
 			break
 		}
 	}
 
-	if !hasKittyTab {
+	if !hasTab {
 		return StatusIdle, "", 0, false
 	}
 
 	// Spinner is the only reliable signal for active work
-	// (JSONL mtime is not reliable without polling)
+	// (JSONL mtime is not reliable without polling - the caller refines
+	// non-spinning tabs from the transcript tail instead)
 	if hasSpinner {
 		return StatusRunning, tabTitle, matchedWindowID, strongMatch
 	}
@@ -491,12 +481,13 @@ func detectSessionStatus(s *Session, activeSessions []activeSession, matchedWind
 	return StatusWaiting, tabTitle, matchedWindowID, strongMatch
 }
 
-// FindWindowIDForSession finds the Kitty window ID for a session
+// FindWindowIDForSession finds the terminal window ID for a session
 // Uses same matching logic as detectSessionStatus: stored ID → --resume flag → project path
 func FindWindowIDForSession(s *Session) int {
+	activeSessions := getActiveSessions()
+
 	// 1. Use stored KittyWindowID if available and still exists
 	if s.KittyWindowID > 0 {
-		activeSessions := getKittyActiveSessions()
 		for _, active := range activeSessions {
 			if active.windowID == s.KittyWindowID {
 				return s.KittyWindowID
@@ -505,7 +496,6 @@ func FindWindowIDForSession(s *Session) int {
 	}
 
 	// 2. Search active sessions for matching --resume flag or project path
-	activeSessions := getKittyActiveSessions()
 
 	// Try session ID match first (strongest)
 	for _, active := range activeSessions {
@@ -2,6 +2,8 @@ package session
 
 import (
 	"testing"
+
+	"github.com/hadar/claude-deck/internal/terminal"
 )
 
 func TestHasClaudeIndicator(t *testing.T) {
@@ -305,3 +307,70 @@ func TestClaimWindowID(t *testing.T) {
 		}
 	})
 }
+
+// fakeBackend is a terminal backend that reports a fixed window list
+type fakeBackend struct {
+	windows []terminal.Window
+}
+
+func (f *fakeBackend) Name() string                                 { return "fake" }
+func (f *fakeBackend) Launch(spec terminal.LaunchSpec) (int, error) { return 0, nil }
+func (f *fakeBackend) Focus(windowID int) error                     { return nil }
+func (f *fakeBackend) Close(windowID int) error                     { return nil }
+func (f *fakeBackend) SetTitle(windowID int, title string) error    { return nil }
+func (f *fakeBackend) ResetTitle(windowID int) error                { return nil }
+func (f *fakeBackend) ListWindows() ([]terminal.Window, error)      { return f.windows, nil }
+
+func TestGetActiveSessions(t *testing.T) {
+	terminal.SetBackend(&fakeBackend{windows: []terminal.Window{
+		{ID: 1, Title: "zsh", Cwd: "/tmp", Cmdline: []string{"zsh"}},
+		{ID: 2, Title: "✳ Idle task", Cwd: "/proj/a", Cmdline: []string{"zsh", "-i", "-c", "claude --resume 11111111-2222-3333-4444-555555555555; exec zsh"}},
+		{ID: 3, Title: "⠂ Busy task", Cwd: "/proj/b"},
+	}})
+	defer terminal.SetBackend(nil)
+
+	active := getActiveSessions()
+	if len(active) != 2 {
+		t.Fatalf("expected 2 claude windows, got %d", len(active))
+	}
+	// Spinner windows sort first
+	if active[0].windowID != 3 || !active[0].hasSpinner {
+		t.Errorf("expected spinner window 3 first, got %+v", active[0])
+	}
+	if active[1].sessionID != "11111111-2222-3333-4444-555555555555" {
+		t.Errorf("expected session ID from --resume, got %q", active[1].sessionID)
+	}
+}
+
+func TestComputeStatusesWithBackend(t *testing.T) {
+	terminal.SetBackend(&fakeBackend{windows: []terminal.Window{
+		{ID: 7, Title: "⠂ Working", Cwd: "/proj/a", Cmdline: []string{"claude --resume aaaaaaaa-0000-0000-0000-000000000001"}},
+		{ID: 8, Title: "✳ Done", Cwd: "/proj/b", Cmdline: []string{"claude"}},
+	}})
+	defer terminal.SetBackend(nil)
+
+	sessions := []*Session{
+		{ClaudeSessionID: "aaaaaaaa-0000-0000-0000-000000000001", ProjectPath: "/proj/a"},
+		{ClaudeSessionID: "uuid-b", ProjectPath: "/proj/b", KittyWindowID: 8},
+		{ClaudeSessionID: "uuid-c", ProjectPath: "/proj/c"},
+	}
+
+	updates, activeIDs, _, _ := ComputeStatuses(sessions)
+	if !activeIDs[7] || !activeIDs[8] {
+		t.Errorf("expected windows 7 and 8 active, got %v", activeIDs)
+	}
+
+	got := make(map[string]Status)
+	for _, u := range updates {
+		got[u.SessionID] = u.Status
+	}
+	if got["aaaaaaaa-0000-0000-0000-000000000001"] != StatusRunning {
+		t.Errorf("resumed session = %v, want running", got["aaaaaaaa-0000-0000-0000-000000000001"])
+	}
+	if got["uuid-b"] != StatusWaiting {
+		t.Errorf("uuid-b = %v, want waiting", got["uuid-b"])
+	}
+	if got["uuid-c"] != StatusIdle {
+		t.Errorf("uuid-c = %v, want idle", got["uuid-c"])
+	}
+}
@@ -2,9 +2,13 @@ package session
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
+	"time"
 )
 
 // StorageDir returns the path to our metadata directory
@@ -20,40 +24,95 @@ func StorageFile() string {
 
 // Settings represents user preferences
 type Settings struct {
-	ActiveExpanded       *bool    `json:"active_expanded,omitempty"`      // Active group expanded state
-	InactiveExpanded     *bool    `json:"inactive_expanded,omitempty"`    // Inactive group expanded state
-	Theme                string   `json:"theme,omitempty"`                // Color theme name
-	ResumeOnStartup      bool     `json:"resume_on_startup,omitempty"`    // Restore active sessions on startup
-	LastActiveSessionIDs []string `json:"last_active_sessions,omitempty"` // Session IDs that were active
-	FavoritePaths        []string `json:"favorite_paths,omitempty"`       // User's favorite project paths
+	ActiveExpanded       *bool            `json:"active_expanded,omitempty"`      // Active group expanded state
+	InactiveExpanded     *bool            `json:"inactive_expanded,omitempty"`    // Inactive group expanded state
+	Theme                string           `json:"theme,omitempty"`                // Color theme name
+	ResumeOnStartup      bool             `json:"resume_on_startup,omitempty"`    // Restore active sessions on startup
+	LastActiveSessionIDs []string         `json:"last_active_sessions,omitempty"` // Session IDs that were active
+	FavoritePaths        []string         `json:"favorite_paths,omitempty"`       // User's favorite project paths
+	Terminal             string           `json:"terminal,omitempty"`             // Terminal backend name (empty = auto-detect)
+	Store                string           `json:"store,omitempty"`                // Metadata store: "json" (default) or "sqlite"
+	DataRoots            []string         `json:"data_roots,omitempty"`           // Extra Claude config directories to discover sessions from
+	PreviewTools         bool             `json:"preview_tools,omitempty"`        // Show tool calls and results in the preview
+	SavedQueries         []string         `json:"saved_queries,omitempty"`        // List filters saved for recall, newest first
+	ListUsage            bool             `json:"list_usage,omitempty"`           // Show each session's estimated cost in the list
+	Prices               map[string]Price `json:"prices,omitempty"`               // Per-model prices (USD per million tokens) over DefaultPrices
 }
 
 // StorageData represents the persisted data structure
 type StorageData struct {
+	Version  int        `json:"version"` // Schema version (see SchemaVersion)
 	Sessions []*Session `json:"sessions"`
 	Groups   []*Group   `json:"groups"`
 	Settings *Settings  `json:"settings,omitempty"`
 }
 
+// StorageLockFile returns the path of the advisory lock guarding sessions.json
+func StorageLockFile() string {
+	return filepath.Join(StorageDir(), "sessions.lock")
+}
+
+// lockStorage takes the storage lock (shared for reads, exclusive for load-modify-save)
+// Returns a function that releases it.
+func lockStorage(exclusive bool) (func(), error) {
+	if err := os.MkdirAll(StorageDir(), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(StorageLockFile(), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f, exclusive); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
+
 // LoadStorage loads the persisted session metadata
 func LoadStorage() (*StorageData, error) {
-	data := &StorageData{
-		Sessions: make([]*Session, 0),
-		Groups:   make([]*Group, 0),
-		Settings: &Settings{},
+	unlock, err := lockStorage(false)
+	if err != nil {
+		return nil, err
 	}
+	defer unlock()
 
-	file := StorageFile()
-	content, err := os.ReadFile(file)
+	data, _, err := readStorage()
+	return data, err
+}
+
+// readStorage reads and parses sessions.json (caller holds the lock)
+// Also returns the raw content so callers can detect later modification.
+func readStorage() (*StorageData, []byte, error) {
+	content, err := os.ReadFile(StorageFile())
 	if os.IsNotExist(err) {
-		return data, nil
+		return parseStorage(nil)
 	}
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	return parseStorage(content)
+}
 
-	if err := json.Unmarshal(content, data); err != nil {
-		return nil, err
+// parseStorage parses sessions.json content (nil content = empty storage)
+// Older schemas are migrated in memory; the returned content is the original.
+func parseStorage(content []byte) (*StorageData, []byte, error) {
+	data := &StorageData{
+		Sessions: make([]*Session, 0),
+		Groups:   make([]*Group, 0),
+		Settings: &Settings{},
+	}
+	if len(content) > 0 {
+		migrated, _, err := migrateContent(content)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := json.Unmarshal(migrated, data); err != nil {
+			return nil, nil, err
+		}
 	}
 
 	// Ensure settings exists
@@ -61,22 +120,78 @@ func LoadStorage() (*StorageData, error) {
 		data.Settings = &Settings{}
 	}
 
-	return data, nil
+	return data, content, nil
 }
 
-// SaveStorage persists the session metadata
+// SaveStorage persists the session metadata (atomically, under the storage lock)
 func SaveStorage(data *StorageData) error {
-	dir := StorageDir()
-	if err := os.MkdirAll(dir, 0755); err != nil {
+	unlock, err := lockStorage(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, _, err := readStorage(); errors.Is(err, ErrNewerSchema) {
 		return err
 	}
+	_, err = writeStorage(data)
+	return err
+}
 
-	content, err := json.MarshalIndent(data, "", "  ")
+// writeStorage atomically replaces sessions.json (caller holds the lock)
+// Returns the written content.
+func writeStorage(data *StorageData) ([]byte, error) {
+	versioned := *data
+	versioned.Version = SchemaVersion
+	content, err := json.MarshalIndent(&versioned, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFileAtomic(StorageFile(), content, 0644); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+// writeFileAtomic writes to a temp file in the same directory, fsyncs it and renames
+// it over path, so a crash leaves either the old or the new file - never a partial one
+func writeFileAtomic(path string, content []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
 	if err != nil {
 		return err
 	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // no-op after a successful rename
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
 
-	return os.WriteFile(StorageFile(), content, 0644)
+	// Persist the rename itself
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
 }
 
 // MergeSessions combines discovered sessions with stored metadata
@@ -108,6 +223,10 @@ func MergeSessions(discovered []*Session, stored *StorageData) []*Session {
 			s.JSONLPath = d.JSONLPath
 			s.LastAccessedAt = d.LastAccessedAt
 			s.GitBranch = d.GitBranch
+			s.Title = d.Title
+			s.MessageCount = d.MessageCount
+			s.Usage = d.Usage
+			s.DataRoot = d.DataRoot
 			result = append(result, s)
 			matchedStored[s.ClaudeSessionID] = true
 		} else {
@@ -116,6 +235,9 @@ func MergeSessions(discovered []*Session, stored *StorageData) []*Session {
 		}
 	}
 
+	// Hand pending sessions over to the JSONL that appeared for them
+	adoptPendingSessions(stored.Sessions, newSessions, matchedStored)
+
 	// Preserve pending sessions (have KittyWindowID but no JSONL yet)
 	for _, s := range stored.Sessions {
 		if !matchedStored[s.ClaudeSessionID] && s.KittyWindowID > 0 {
@@ -134,12 +256,12 @@ func MergeSessions(discovered []*Session, stored *StorageData) []*Session {
 		result = append(result, s)
 	}
 
-	// Ensure all sessions have unique, non-zero Order values
-	// This fixes legacy sessions that all have Order=0
+	// Ensure all sessions have unique Order values (legacy files with all-zero
+	// orders are renumbered by the v1 migration, see migrateOrders)
 	needsReorder := false
 	orderUsed := make(map[int]bool)
 	for _, s := range result {
-		if s.Order == 0 || orderUsed[s.Order] {
+		if orderUsed[s.Order] {
 			needsReorder = true
 			break
 		}
@@ -160,12 +282,61 @@ func MergeSessions(discovered []*Session, stored *StorageData) []*Session {
 	return result
 }
 
+// previewMerge merges copies of discovered and stored sessions, leaving the
+// originals to the real merge
+func previewMerge(discovered []*Session, stored *StorageData) []*Session {
+	clone := func(sessions []*Session) []*Session {
+		copies := make([]*Session, len(sessions))
+		for i, s := range sessions {
+			c := *s
+			copies[i] = &c
+		}
+		return copies
+	}
+	return MergeSessions(clone(discovered), &StorageData{Sessions: clone(stored.Sessions)})
+}
+
+// PendingSessionPrefix marks sessions opened before Claude created their JSONL
+const PendingSessionPrefix = "pending-"
+
+// adoptPendingSessions copies the metadata of stored pending sessions (name, window,
+// group) onto newly discovered sessions started at the same path after them.
+// Adopted pending sessions are marked matched so they're dropped.
+func adoptPendingSessions(stored, discovered []*Session, matched map[string]bool) {
+	for _, p := range stored {
+		if !strings.HasPrefix(p.ClaudeSessionID, PendingSessionPrefix) {
+			continue
+		}
+		var newest *Session
+		for _, d := range discovered {
+			if d.ProjectPath != p.ProjectPath || d.LastAccessedAt.Before(p.CreatedAt) || d.KittyWindowID > 0 {
+				continue
+			}
+			if newest == nil || d.LastAccessedAt.After(newest.LastAccessedAt) {
+				newest = d
+			}
+		}
+		if newest == nil {
+			continue
+		}
+		if p.Renamed {
+			newest.Name = p.Name
+			newest.Renamed = true
+		}
+		newest.KittyWindowID = p.KittyWindowID
+		newest.GroupPath = p.GroupPath
+		newest.Pinned = p.Pinned
+		matched[p.ClaudeSessionID] = true
+	}
+}
+
 // Manager handles session and group operations
 type Manager struct {
 	Sessions []*Session
 	Groups   []*Group
 	Settings *Settings
 	storage  *StorageData
+	store    Store // opened by Load; nil means sessions.json
 }
 
 // NewManager creates a new session manager
@@ -179,12 +350,27 @@ func NewManager() (*Manager, error) {
 
 // Load discovers sessions and loads metadata
 func (m *Manager) Load() error {
+	return m.LoadWithProgress(nil)
+}
+
+// LoadWithProgress is Load that reports the sessions found so far while discovery
+// runs. progress gets copies merged with the stored metadata, safe to use from
+// another goroutine; the manager's own sessions are set when loading completes.
+func (m *Manager) LoadWithProgress(progress func([]*Session)) error {
 	// Load stored metadata
-	stored, err := LoadStorage()
+	if m.store == nil {
+		store, err := OpenStore()
+		if err != nil {
+			return err
+		}
+		m.store = store
+	}
+	stored, err := m.store.Load()
 	if err != nil {
 		return err
 	}
 	m.storage = stored
+	setConfiguredRoots(stored.Settings.DataRoots)
 	m.Groups = stored.Groups
 	m.Settings = stored.Settings
 
@@ -198,7 +384,13 @@ func (m *Manager) Load() error {
 	hadSessions := len(stored.Sessions)
 
 	// Discover sessions from Claude's data
-	discovered, err := DiscoverSessions()
+	var report func([]*Session)
+	if progress != nil {
+		report = func(found []*Session) {
+			progress(previewMerge(found, stored))
+		}
+	}
+	discovered, err := discoverSessions(report)
 	if err != nil {
 		return err
 	}
@@ -225,13 +417,36 @@ func (m *Manager) Load() error {
 }
 
 // Save persists the current state
+// Changes another deck made meanwhile are merged in (see Store.Save).
 func (m *Manager) Save() error {
+	if m.store == nil {
+		m.store = &jsonStore{}
+	}
 	data := &StorageData{
 		Sessions: m.Sessions,
 		Groups:   m.Groups,
 		Settings: m.Settings,
 	}
-	return SaveStorage(data)
+	if err := m.store.Save(data); err != nil {
+		return err
+	}
+
+	m.Sessions = data.Sessions
+	m.Groups = data.Groups
+	if m.Settings != nil && data.Settings != nil {
+		*m.Settings = *data.Settings
+	} else {
+		m.Settings = data.Settings
+	}
+	return nil
+}
+
+// Close releases the metadata store
+func (m *Manager) Close() error {
+	if m.store == nil {
+		return nil
+	}
+	return m.store.Close()
 }
 
 // GetTheme returns the theme setting
@@ -300,6 +515,91 @@ func (m *Manager) SetResumeOnStartup(resume bool) error {
 	return m.Save()
 }
 
+// GetPreviewTools returns whether the preview shows tool calls
+func (m *Manager) GetPreviewTools() bool {
+	if m.Settings == nil {
+		return false
+	}
+	return m.Settings.PreviewTools
+}
+
+// SetPreviewTools updates whether the preview shows tool calls
+func (m *Manager) SetPreviewTools(show bool) error {
+	if m.Settings == nil {
+		m.Settings = &Settings{}
+	}
+	m.Settings.PreviewTools = show
+	return m.Save()
+}
+
+// GetListUsage returns whether the list shows each session's cost
+func (m *Manager) GetListUsage() bool {
+	if m.Settings == nil {
+		return false
+	}
+	return m.Settings.ListUsage
+}
+
+// SetListUsage updates whether the list shows each session's cost
+func (m *Manager) SetListUsage(show bool) error {
+	if m.Settings == nil {
+		m.Settings = &Settings{}
+	}
+	m.Settings.ListUsage = show
+	return m.Save()
+}
+
+// GetPrices returns the price table: DefaultPrices with the configured prices over them
+func (m *Manager) GetPrices() map[string]Price {
+	prices := make(map[string]Price, len(DefaultPrices))
+	for model, price := range DefaultPrices {
+		prices[model] = price
+	}
+	if m.Settings != nil {
+		for model, price := range m.Settings.Prices {
+			prices[model] = price
+		}
+	}
+	return prices
+}
+
+// GetSavedQueries returns the saved list filters, newest first
+func (m *Manager) GetSavedQueries() []string {
+	if m.Settings == nil {
+		return nil
+	}
+	return m.Settings.SavedQueries
+}
+
+// SaveQuery adds a list filter to the saved ones (moving it to the front if already saved)
+func (m *Manager) SaveQuery(query string) error {
+	if m.Settings == nil {
+		m.Settings = &Settings{}
+	}
+	queries := []string{query}
+	for _, q := range m.Settings.SavedQueries {
+		if q != query {
+			queries = append(queries, q)
+		}
+	}
+	m.Settings.SavedQueries = queries
+	return m.Save()
+}
+
+// DeleteSavedQuery removes a saved list filter
+func (m *Manager) DeleteSavedQuery(query string) error {
+	if m.Settings == nil {
+		return nil
+	}
+	for i, q := range m.Settings.SavedQueries {
+		if q == query {
+			m.Settings.SavedQueries = append(m.Settings.SavedQueries[:i], m.Settings.SavedQueries[i+1:]...)
+			return m.Save()
+		}
+	}
+	return nil
+}
+
 // GetLastActiveSessionIDs returns the list of previously active session IDs
 func (m *Manager) GetLastActiveSessionIDs() []string {
 	if m.Settings == nil {
@@ -316,6 +616,23 @@ func (m *Manager) SetLastActiveSessionIDs(ids []string) {
 	m.Settings.LastActiveSessionIDs = ids
 }
 
+// GetTerminal returns the configured terminal backend name (empty = auto-detect)
+func (m *Manager) GetTerminal() string {
+	if m.Settings == nil {
+		return ""
+	}
+	return m.Settings.Terminal
+}
+
+// SetTerminal updates the terminal backend setting
+func (m *Manager) SetTerminal(name string) error {
+	if m.Settings == nil {
+		m.Settings = &Settings{}
+	}
+	m.Settings.Terminal = name
+	return m.Save()
+}
+
 // GetFavoritePaths returns the user's favorite paths
 func (m *Manager) GetFavoritePaths() []string {
 	if m.Settings == nil {
@@ -392,6 +709,53 @@ func (m *Manager) FindSession(id string) *Session {
 	return nil
 }
 
+// ResolveSession finds a session by ID, unique Claude session ID prefix or name (case-insensitive)
+func (m *Manager) ResolveSession(query string) (*Session, error) {
+	if query == "" {
+		return nil, errors.New("empty session id")
+	}
+	for _, s := range m.Sessions {
+		if s.ID == query || s.ClaudeSessionID == query {
+			return s, nil
+		}
+	}
+
+	var prefix, named []*Session
+	for _, s := range m.Sessions {
+		if strings.HasPrefix(s.ClaudeSessionID, query) {
+			prefix = append(prefix, s)
+		}
+		if strings.EqualFold(s.Name, query) {
+			named = append(named, s)
+		}
+	}
+	for _, matches := range [][]*Session{prefix, named} {
+		switch len(matches) {
+		case 0:
+			continue
+		case 1:
+			return matches[0], nil
+		default:
+			var ids []string
+			for _, s := range matches {
+				ids = append(ids, fmt.Sprintf("%s (%s)", s.ShortID(), s.Name))
+			}
+			return nil, fmt.Errorf("%q matches %d sessions: %s", query, len(matches), strings.Join(ids, ", "))
+		}
+	}
+	return nil, fmt.Errorf("no session matches %q", query)
+}
+
+// ResolveGroup finds a group by ID, path or name
+func (m *Manager) ResolveGroup(query string) (*Group, error) {
+	for _, g := range m.Groups {
+		if g.Path == query || g.Name == query || g.ID == query {
+			return g, nil
+		}
+	}
+	return nil, fmt.Errorf("no group named %q", query)
+}
+
 // FindGroup finds a group by ID
 func (m *Manager) FindGroup(id string) *Group {
 	for _, g := range m.Groups {
@@ -441,6 +805,29 @@ func (m *Manager) DeleteSession(id string) error {
 	return nil
 }
 
+// AddPendingSession records a session opened in windowID before Claude created its JSONL
+// An empty name gets a placeholder and is replaced by tab title sync later.
+func (m *Manager) AddPendingSession(projectPath, name string, windowID int) (*Session, error) {
+	id := fmt.Sprintf("%s%d", PendingSessionPrefix, windowID)
+	now := time.Now()
+	s := &Session{
+		ID:              id,
+		ClaudeSessionID: id,
+		Name:            name,
+		ProjectPath:     projectPath,
+		KittyWindowID:   windowID,
+		Status:          StatusWaiting,
+		Renamed:         name != "",
+		CreatedAt:       now,
+		LastAccessedAt:  now,
+	}
+	if name == "" {
+		s.Name = filepath.Base(projectPath) + " (new)"
+	}
+	m.Sessions = append(m.Sessions, s)
+	return s, m.Save()
+}
+
 // RemovePendingSession removes a pending session by ID (in-memory only, no save)
 func (m *Manager) RemovePendingSession(id string) {
 	for i, s := range m.Sessions {
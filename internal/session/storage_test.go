@@ -3,6 +3,7 @@ package session
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -73,6 +74,39 @@ func TestMergeSessions(t *testing.T) {
 		}
 	})
 
+	t.Run("pending session is adopted by new session at same path", func(t *testing.T) {
+		discovered := []*Session{
+			{ClaudeSessionID: "uuid-old", ProjectPath: "/p", LastAccessedAt: now.Add(-time.Hour)},
+			{ClaudeSessionID: "uuid-new", ProjectPath: "/p", LastAccessedAt: now.Add(time.Minute)},
+			{ClaudeSessionID: "uuid-other", ProjectPath: "/q", LastAccessedAt: now.Add(time.Minute)},
+		}
+		stored := &StorageData{
+			Sessions: []*Session{
+				{ClaudeSessionID: "pending-7", ProjectPath: "/p", KittyWindowID: 7, Name: "feature", Renamed: true, GroupPath: "work", CreatedAt: now},
+			},
+		}
+
+		result := MergeSessions(discovered, stored)
+
+		if len(result) != 3 {
+			t.Fatalf("expected pending session to be replaced, got %d sessions", len(result))
+		}
+		for _, s := range result {
+			switch s.ClaudeSessionID {
+			case "uuid-new":
+				if s.Name != "feature" || !s.Renamed || s.KittyWindowID != 7 || s.GroupPath != "work" {
+					t.Errorf("pending metadata not adopted: %+v", s)
+				}
+			case "uuid-old", "uuid-other":
+				if s.KittyWindowID != 0 || s.Renamed {
+					t.Errorf("%s should not adopt pending metadata: %+v", s.ClaudeSessionID, s)
+				}
+			default:
+				t.Errorf("unexpected session %s", s.ClaudeSessionID)
+			}
+		}
+	})
+
 	t.Run("sessions without KittyWindowID are not preserved", func(t *testing.T) {
 		discovered := []*Session{}
 		stored := &StorageData{
@@ -115,6 +149,61 @@ func TestManagerFindSession(t *testing.T) {
 	})
 }
 
+func TestManagerResolveSession(t *testing.T) {
+	m := &Manager{
+		Sessions: []*Session{
+			{ID: "550e8400-e29b-41d4-a716-446655440000", ClaudeSessionID: "550e8400-e29b-41d4-a716-446655440000", Name: "api"},
+			{ID: "550e8400-e29b-41d4-a716-446655440001", ClaudeSessionID: "550e8400-e29b-41d4-a716-446655440001", Name: "Web"},
+			{ID: "7c9e6679-7425-40de-944b-e07fc1f90ae7", ClaudeSessionID: "7c9e6679-7425-40de-944b-e07fc1f90ae7", Name: "web"},
+		},
+	}
+
+	tests := []struct {
+		query   string
+		want    string
+		wantErr string
+	}{
+		{"550e8400-e29b-41d4-a716-446655440000", "550e8400-e29b-41d4-a716-446655440000", ""},
+		{"7c9e", "7c9e6679-7425-40de-944b-e07fc1f90ae7", ""},
+		{"API", "550e8400-e29b-41d4-a716-446655440000", ""},
+		{"550e8400", "", "matches 2 sessions"},
+		{"web", "", "matches 2 sessions"},
+		{"missing", "", "no session matches"},
+		{"", "", "empty session id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			s, err := m.ResolveSession(tt.query)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if s.ClaudeSessionID != tt.want {
+				t.Errorf("resolved %s, want %s", s.ClaudeSessionID, tt.want)
+			}
+		})
+	}
+}
+
+func TestManagerResolveGroup(t *testing.T) {
+	m := &Manager{
+		Groups: []*Group{{ID: "grp-1", Name: "Work", Path: "Work"}, {ID: "grp-2", Name: "api", Path: "Work/api"}},
+	}
+	for _, query := range []string{"grp-2", "Work/api", "api"} {
+		if g, err := m.ResolveGroup(query); err != nil || g.ID != "grp-2" {
+			t.Errorf("ResolveGroup(%q) = %v, %v", query, g, err)
+		}
+	}
+	if _, err := m.ResolveGroup("missing"); err == nil {
+		t.Error("ResolveGroup(missing) should fail")
+	}
+}
+
 func TestManagerFindGroup(t *testing.T) {
 	m := &Manager{
 		Groups: []*Group{
@@ -589,6 +678,35 @@ func TestRemovePendingSession(t *testing.T) {
 	}
 }
 
+func TestAddPendingSession(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", oldHome)
+
+	m := &Manager{}
+	s, err := m.AddPendingSession("/work/api", "", 12)
+	if err != nil {
+		t.Fatalf("AddPendingSession() error = %v", err)
+	}
+	if s.ClaudeSessionID != "pending-12" || s.KittyWindowID != 12 || s.Renamed || s.Name != "api (new)" {
+		t.Errorf("unexpected pending session: %+v", s)
+	}
+
+	named, _ := m.AddPendingSession("/work/api", "fix auth", 13)
+	if named.Name != "fix auth" || !named.Renamed {
+		t.Errorf("named pending session should be marked renamed: %+v", named)
+	}
+
+	stored, err := LoadStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored.Sessions) != 2 {
+		t.Errorf("expected 2 saved pending sessions, got %d", len(stored.Sessions))
+	}
+}
+
 func TestSettersWithNilSettings(t *testing.T) {
 	tmpDir := t.TempDir()
 	oldHome := os.Getenv("HOME")
@@ -677,6 +795,23 @@ func TestToggleFavoritePath(t *testing.T) {
 	}
 }
 
+func TestSavedQueries(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	m := &Manager{}
+	m.SaveQuery("status:waiting")
+	m.SaveQuery("project:api")
+	m.SaveQuery("status:waiting")
+	if got := m.GetSavedQueries(); len(got) != 2 || got[0] != "status:waiting" || got[1] != "project:api" {
+		t.Errorf("saved queries = %q, want most recent first without duplicates", got)
+	}
+
+	m.DeleteSavedQuery("status:waiting")
+	if got := m.GetSavedQueries(); len(got) != 1 || got[0] != "project:api" {
+		t.Errorf("after delete = %q", got)
+	}
+}
+
 func TestToggleFavoritePathNilSettings(t *testing.T) {
 	tmpDir := t.TempDir()
 	oldHome := os.Getenv("HOME")
@@ -942,3 +1077,147 @@ func TestMergeSessionsOrderReassignment(t *testing.T) {
 		orders[s.Order] = true
 	}
 }
+
+func TestSaveStorageAtomic(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", oldHome)
+
+	data := &StorageData{Sessions: []*Session{{ID: "s1", Name: "one"}}, Settings: &Settings{}}
+	if err := SaveStorage(data); err != nil {
+		t.Fatalf("SaveStorage() error = %v", err)
+	}
+	data.Sessions[0].Name = "two"
+	if err := SaveStorage(data); err != nil {
+		t.Fatalf("SaveStorage() error = %v", err)
+	}
+
+	loaded, err := LoadStorage()
+	if err != nil || loaded.Sessions[0].Name != "two" {
+		t.Fatalf("LoadStorage() = %+v, %v", loaded, err)
+	}
+	// No temp files are left behind
+	entries, _ := os.ReadDir(StorageDir())
+	for _, e := range entries {
+		if strings.HasSuffix(e.Name(), ".tmp") {
+			t.Errorf("leftover temp file %s", e.Name())
+		}
+	}
+}
+
+func TestSaveStorageConcurrent(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", oldHome)
+
+	done := make(chan error)
+	for i := 0; i < 8; i++ {
+		go func(i int) {
+			var err error
+			for j := 0; j < 20 && err == nil; j++ {
+				err = SaveStorage(&StorageData{Sessions: []*Session{{ID: "s", Order: i*100 + j}}, Settings: &Settings{}})
+				if err == nil {
+					_, err = LoadStorage()
+				}
+			}
+			done <- err
+		}(i)
+	}
+	for i := 0; i < 8; i++ {
+		if err := <-done; err != nil {
+			t.Errorf("concurrent save/load error = %v", err)
+		}
+	}
+}
+
+// writeTestSessionFile creates a discoverable session JSONL under ~/.claude/projects
+func writeTestSessionFile(t *testing.T, home, project, id string) {
+	t.Helper()
+	dir := filepath.Join(home, ".claude", "projects", EncodeProjectPath(project))
+	os.MkdirAll(dir, 0755)
+	content := `{"type":"user","cwd":"` + project + `","message":{"role":"user","content":"hi"}}` + "\n"
+	if err := os.WriteFile(filepath.Join(dir, id+".jsonl"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestManagerSaveMergesOtherInstance(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", oldHome)
+
+	id1 := "550e8400-e29b-41d4-a716-446655440000"
+	id2 := "550e8400-e29b-41d4-a716-446655440001"
+	writeTestSessionFile(t, tmpDir, "/work/api", id1)
+	writeTestSessionFile(t, tmpDir, "/work/web", id2)
+
+	// Two decks open at once
+	deck1, err := NewManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	deck2, err := NewManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Deck 1 files a session into a group
+	if _, err := deck1.CreateGroup("Work", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := deck1.MoveSession(id1, "Work"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Deck 2 (with a stale view) renames the other session and saves
+	if err := deck2.RenameSession(id2, "frontend"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Both changes survive on disk and in deck 2's memory
+	check := func(name string, m *Manager) {
+		t.Helper()
+		if s := m.FindSession(id1); s == nil || s.GroupPath != "Work" {
+			t.Errorf("%s: group assignment lost: %+v", name, s)
+		}
+		if s := m.FindSession(id2); s == nil || s.Name != "frontend" {
+			t.Errorf("%s: rename lost: %+v", name, s)
+		}
+		if len(m.Groups) != 1 {
+			t.Errorf("%s: expected 1 group, got %d", name, len(m.Groups))
+		}
+	}
+	check("deck2", deck2)
+	reloaded, err := NewManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	check("disk", reloaded)
+}
+
+func TestPreviewMergeCopies(t *testing.T) {
+	stored := &StorageData{Sessions: []*Session{{ClaudeSessionID: "a", Name: "api", Order: 3}}}
+	discovered := []*Session{
+		{ID: "a", ClaudeSessionID: "a", JSONLPath: "/a.jsonl"},
+		{ID: "b", ClaudeSessionID: "b", JSONLPath: "/b.jsonl"},
+	}
+
+	preview := previewMerge(discovered, stored)
+	if len(preview) != 2 {
+		t.Fatalf("got %d sessions, want 2", len(preview))
+	}
+	for _, s := range preview {
+		if s == stored.Sessions[0] || s == discovered[0] || s == discovered[1] {
+			t.Error("previewMerge should not return the original sessions")
+		}
+		if s.ClaudeSessionID == "a" && (s.Name != "api" || s.JSONLPath != "/a.jsonl") {
+			t.Errorf("stored metadata not merged: %+v", s)
+		}
+	}
+	if stored.Sessions[0].JSONLPath != "" || discovered[1].Order != 0 {
+		t.Error("originals were modified")
+	}
+}
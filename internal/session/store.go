@@ -0,0 +1,107 @@
+package session
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// Store names for Settings.Store
+const (
+	StoreJSON   = "json"   // sessions.json (default)
+	StoreSQLite = "sqlite" // sessions.db
+)
+
+// Store persists session metadata
+type Store interface {
+	// Load returns the stored sessions, groups and settings
+	Load() (*StorageData, error)
+	// Save persists data. A store may fold in changes made by another deck,
+	// in which case data's Sessions, Groups and Settings are updated.
+	Save(data *StorageData) error
+	Close() error
+}
+
+// OpenStore opens the store selected by the "store" setting in sessions.json
+// sessions.json is upgraded to the current schema first. A new SQLite store
+// imports the existing sessions.json.
+func OpenStore() (Store, error) {
+	if err := upgradeStorageFile(); err != nil {
+		return nil, err
+	}
+	data, err := LoadStorage()
+	if err != nil {
+		return nil, err
+	}
+	switch data.Settings.Store {
+	case "", StoreJSON:
+		return &jsonStore{}, nil
+	case StoreSQLite:
+		return openSQLiteStore(SQLiteFile(), data)
+	default:
+		return nil, fmt.Errorf("unknown store %q in sessions.json (use %q or %q)", data.Settings.Store, StoreJSON, StoreSQLite)
+	}
+}
+
+// jsonStore keeps everything in sessions.json
+type jsonStore struct {
+	// base is sessions.json as last read or written by this store (nil if never).
+	// Save compares it with the file on disk to detect changes by another deck.
+	base []byte
+}
+
+func (j *jsonStore) Load() (*StorageData, error) {
+	unlock, err := lockStorage(false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	data, content, err := readStorage()
+	if err != nil {
+		return nil, err
+	}
+	j.base = content
+	if j.base == nil {
+		j.base = []byte{}
+	}
+	return data, nil
+}
+
+// Save rewrites sessions.json
+// If another deck changed it since this store last read or wrote it, the changes
+// are merged (see mergeStorage) instead of overwritten.
+func (j *jsonStore) Save(data *StorageData) error {
+	unlock, err := lockStorage(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	_, disk, err := readStorage()
+	if errors.Is(err, ErrNewerSchema) {
+		// Never downgrade a file a newer deck wrote
+		return err
+	}
+	if j.base != nil && err == nil && !bytes.Equal(disk, j.base) {
+		base, _, baseErr := parseStorage(j.base)
+		theirs, _, theirsErr := parseStorage(disk)
+		if baseErr == nil && theirsErr == nil {
+			merged := mergeStorage(base, data, theirs)
+			data.Sessions = merged.Sessions
+			data.Groups = merged.Groups
+			data.Settings = merged.Settings
+		}
+	}
+
+	content, err := writeStorage(data)
+	if err != nil {
+		return err
+	}
+	j.base = content
+	return nil
+}
+
+func (j *jsonStore) Close() error {
+	return nil
+}
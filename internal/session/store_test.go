@@ -0,0 +1,196 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenStore(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr bool
+	}{
+		{"no file", "", StoreJSON, false},
+		{"default", `{"sessions":[],"groups":[]}`, StoreJSON, false},
+		{"json", `{"sessions":[],"groups":[],"settings":{"store":"json"}}`, StoreJSON, false},
+		{"sqlite", `{"sessions":[],"groups":[],"settings":{"store":"sqlite"}}`, StoreSQLite, false},
+		{"unknown", `{"sessions":[],"groups":[],"settings":{"store":"redis"}}`, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			if tt.content != "" {
+				os.MkdirAll(StorageDir(), 0755)
+				os.WriteFile(StorageFile(), []byte(tt.content), 0644)
+			}
+
+			store, err := OpenStore()
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("OpenStore() error = %v", err)
+			}
+			defer store.Close()
+
+			got := StoreJSON
+			if _, ok := store.(*sqliteStore); ok {
+				got = StoreSQLite
+			}
+			if got != tt.want {
+				t.Errorf("OpenStore() = %s store, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSQLiteStoreImport(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	legacy := `{"sessions":[{"claude_session_id":"a","name":"api","group_path":"work","order":2,"pinned":true},
+		{"claude_session_id":"b","name":"web","order":1}],
+		"groups":[{"id":"grp-1","name":"work","path":"work","expanded":true}],
+		"settings":{"store":"sqlite","theme":"Nord","favorite_paths":["/src"]}}`
+	os.MkdirAll(StorageDir(), 0755)
+	os.WriteFile(StorageFile(), []byte(legacy), 0644)
+
+	store, err := OpenStore()
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	data, err := store.Load()
+	store.Close()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if len(data.Sessions) != 2 || data.Sessions[0].Name != "api" || !data.Sessions[0].Pinned || data.Sessions[0].GroupPath != "work" {
+		t.Errorf("sessions not imported: %+v", data.Sessions)
+	}
+	if len(data.Groups) != 1 || data.Groups[0].Path != "work" || !data.Groups[0].Expanded {
+		t.Errorf("groups not imported: %+v", data.Groups)
+	}
+	if data.Settings.Theme != "Nord" || len(data.Settings.FavoritePaths) != 1 || data.Settings.Store != StoreSQLite {
+		t.Errorf("settings not imported: %+v", data.Settings)
+	}
+
+	// The import only happens once: later sessions.json edits are ignored
+	os.WriteFile(StorageFile(), []byte(`{"sessions":[],"groups":[],"settings":{"store":"sqlite"}}`), 0644)
+	store, err = OpenStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	data, _ = store.Load()
+	if len(data.Sessions) != 2 {
+		t.Errorf("expected existing sessions.db to be kept, got %d sessions", len(data.Sessions))
+	}
+}
+
+func TestSQLiteStorePerRecordSave(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sessions.db")
+
+	s1, err := openSQLiteStore(path, &StorageData{
+		Sessions: []*Session{{ClaudeSessionID: "a", Name: "api"}, {ClaudeSessionID: "b", Name: "web"}},
+		Groups:   []*Group{{ID: "grp-1", Name: "work", Path: "work"}},
+		Settings: &Settings{Theme: "Nord"},
+	})
+	if err != nil {
+		t.Fatalf("openSQLiteStore() error = %v", err)
+	}
+	defer s1.Close()
+	s2, err := openSQLiteStore(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s2.Close()
+
+	d1, err := s1.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	d2, err := s2.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each deck changes a different record
+	d1.Sessions[0].Name = "backend"
+	d1.Groups = nil
+	if err := s1.Save(d1); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	d2.Sessions[1].Pinned = true
+	d2.Settings.Theme = "Dracula"
+	if err := s2.Save(d2); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got, err := s1.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Sessions) != 2 {
+		t.Fatalf("got %d sessions, want 2", len(got.Sessions))
+	}
+	if got.Sessions[0].Name != "backend" {
+		t.Errorf("first deck's rename lost: %+v", got.Sessions[0])
+	}
+	if !got.Sessions[1].Pinned {
+		t.Errorf("second deck's pin lost: %+v", got.Sessions[1])
+	}
+	if len(got.Groups) != 0 {
+		t.Errorf("deleted group came back: %+v", got.Groups)
+	}
+	if got.Settings.Theme != "Dracula" {
+		t.Errorf("theme = %q, want Dracula", got.Settings.Theme)
+	}
+}
+
+func TestManagerWithSQLiteStore(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	id := "550e8400-e29b-41d4-a716-446655440000"
+	writeTestSessionFile(t, tmpDir, "/work/api", id)
+	settings := `{"version":2,"sessions":[],"groups":[],"settings":{"store":"sqlite"}}`
+	os.MkdirAll(StorageDir(), 0755)
+	os.WriteFile(StorageFile(), []byte(settings), 0644)
+
+	m, err := NewManager()
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	if err := m.RenameSession(id, "api"); err != nil {
+		t.Fatalf("RenameSession() error = %v", err)
+	}
+	if err := m.SetTheme("Nord"); err != nil {
+		t.Fatal(err)
+	}
+	m.Close()
+
+	m, err = NewManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if s := m.FindSession(id); s == nil || s.Name != "api" || !s.Renamed {
+		t.Errorf("rename not persisted: %+v", s)
+	}
+	if m.GetTheme() != "Nord" {
+		t.Errorf("theme = %q, want Nord", m.GetTheme())
+	}
+
+	// sessions.json is left alone
+	if content, _ := os.ReadFile(StorageFile()); string(content) != settings {
+		t.Errorf("sessions.json was modified: %s", content)
+	}
+}
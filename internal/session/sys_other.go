@@ -0,0 +1,20 @@
+//go:build !unix
+
+package session
+
+import "os"
+
+// lockFile is a no-op where flock isn't available; saves are still atomic
+func lockFile(f *os.File, exclusive bool) error {
+	return nil
+}
+
+// unlockFile is a no-op where flock isn't available
+func unlockFile(f *os.File) error {
+	return nil
+}
+
+// processAlive assumes processes are alive where they can't be probed
+func processAlive(pid int) bool {
+	return true
+}
@@ -0,0 +1,131 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// maxToolSummary bounds the length of a tool call or result summary
+const maxToolSummary = 120
+
+// ToolCall is a compact summary of a tool_use part and, once it ran, its result
+type ToolCall struct {
+	ID      string
+	Name    string
+	Summary string // file path, command or pattern - whatever identifies the call
+	Input   any    // raw tool input
+	Result  *ToolResult
+}
+
+// ToolResult summarizes a tool_result part
+type ToolResult struct {
+	IsError bool   // the tool failed (non-zero exit, rejected, ...)
+	Summary string // the first line of output for errors, else a line count
+	Lines   int    // lines of output
+}
+
+// newToolCall summarizes a tool_use part
+func newToolCall(part ContentPart) ToolCall {
+	return ToolCall{
+		ID:      part.ID,
+		Name:    part.Name,
+		Summary: toolSummary(part.Name, part.Input),
+		Input:   part.Input,
+	}
+}
+
+// toolSummary picks the input field that says what a tool call does
+func toolSummary(name string, input any) string {
+	fields, _ := input.(map[string]any)
+	str := func(key string) string {
+		s, _ := fields[key].(string)
+		return s
+	}
+
+	var summary string
+	switch name {
+	case "Read", "Edit", "Write", "MultiEdit":
+		summary = str("file_path")
+	case "NotebookEdit":
+		summary = str("notebook_path")
+	case "Bash":
+		summary = str("command")
+	case "Grep":
+		summary = str("pattern")
+		if path := str("path"); path != "" {
+			summary += " in " + path
+		}
+	case "Glob":
+		summary = str("pattern")
+	case "WebFetch":
+		summary = str("url")
+	case "WebSearch":
+		summary = str("query")
+	case "Task", "Agent":
+		summary = str("description")
+	case "TodoWrite":
+		if todos, ok := fields["todos"].([]any); ok {
+			summary = fmt.Sprintf("%d todos", len(todos))
+		}
+	}
+	return oneLine(summary, maxToolSummary)
+}
+
+// newToolResult summarizes a tool_result part
+func newToolResult(part ContentPart) *ToolResult {
+	output := strings.TrimSpace(toolResultText(part.Content))
+	result := &ToolResult{IsError: part.IsError}
+	if output != "" {
+		result.Lines = strings.Count(output, "\n") + 1
+	}
+
+	switch {
+	case part.IsError:
+		result.Summary = oneLine(output, maxToolSummary)
+		if result.Summary == "" {
+			result.Summary = "failed"
+		}
+	case result.Lines == 0:
+		result.Summary = "no output"
+	case result.Lines == 1:
+		result.Summary = "1 line"
+	default:
+		result.Summary = fmt.Sprintf("%d lines", result.Lines)
+	}
+	return result
+}
+
+// toolResultText returns the text of a tool_result's content (a string or text parts)
+func toolResultText(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var str string
+	if err := json.Unmarshal(raw, &str); err == nil {
+		return str
+	}
+	var parts []ContentPart
+	if err := json.Unmarshal(raw, &parts); err == nil {
+		var texts []string
+		for _, p := range parts {
+			if p.Type == "text" {
+				texts = append(texts, p.Text)
+			}
+		}
+		return strings.Join(texts, "\n")
+	}
+	return ""
+}
+
+// oneLine returns the first line of s, cut to maxLen
+func oneLine(s string, maxLen int) string {
+	s = strings.TrimSpace(s)
+	if idx := strings.IndexByte(s, '\n'); idx >= 0 {
+		s = strings.TrimSpace(s[:idx]) + " …"
+	}
+	if len(s) > maxLen {
+		s = s[:maxLen] + "..."
+	}
+	return s
+}
@@ -0,0 +1,98 @@
+package session
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestToolSummary(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"Read", `{"file_path":"/work/api/main.go"}`, "/work/api/main.go"},
+		{"Edit", `{"file_path":"/work/api/main.go","old_string":"a","new_string":"b"}`, "/work/api/main.go"},
+		{"Bash", `{"command":"go test ./...\ngo vet ./...","description":"Run tests"}`, "go test ./... …"},
+		{"Grep", `{"pattern":"func main","path":"cmd"}`, "func main in cmd"},
+		{"Glob", `{"pattern":"**/*.go"}`, "**/*.go"},
+		{"TodoWrite", `{"todos":[{},{}]}`, "2 todos"},
+		{"Unknown", `{"x":1}`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var input any
+			json.Unmarshal([]byte(tt.input), &input)
+			if got := toolSummary(tt.name, input); got != tt.want {
+				t.Errorf("toolSummary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewToolResult(t *testing.T) {
+	tests := []struct {
+		name    string
+		part    string
+		isError bool
+		want    string
+	}{
+		{"string output", `{"type":"tool_result","content":"a\nb\nc"}`, false, "3 lines"},
+		{"text parts", `{"type":"tool_result","content":[{"type":"text","text":"ok"}]}`, false, "1 line"},
+		{"empty", `{"type":"tool_result","content":""}`, false, "no output"},
+		{"error", `{"type":"tool_result","is_error":true,"content":"Exit code 1\nFAIL"}`, true, "Exit code 1 …"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var part ContentPart
+			if err := json.Unmarshal([]byte(tt.part), &part); err != nil {
+				t.Fatal(err)
+			}
+			got := newToolResult(part)
+			if got.IsError != tt.isError || got.Summary != tt.want {
+				t.Errorf("newToolResult() = %+v, want error %v, summary %q", got, tt.isError, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetPreviewWithTools(t *testing.T) {
+	jsonlPath := filepath.Join(t.TempDir(), "test.jsonl")
+	content := `{"type":"user","message":{"role":"user","content":"Fix the test"}}
+{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"I'll fix that"},{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"go test"}}]}}
+{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","is_error":true,"content":"Exit code 1"}]}}
+{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"t2","name":"Read","input":{"file_path":"/work/a_test.go"}}]}}
+`
+	if err := os.WriteFile(jsonlPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := &Session{JSONLPath: jsonlPath}
+
+	plain, err := GetPreview(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plain) != 2 || plain[1].Tools != nil {
+		t.Errorf("GetPreview() = %+v, want 2 messages without tools", plain)
+	}
+
+	messages, err := GetPreviewWithTools(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 3 {
+		t.Fatalf("got %d messages, want 3 (tool-only message kept, result-only dropped)", len(messages))
+	}
+	bash := messages[1].Tools
+	if len(bash) != 1 || bash[0].Summary != "go test" || bash[0].Result == nil || !bash[0].Result.IsError {
+		t.Errorf("bash call = %+v", bash)
+	}
+	read := messages[2].Tools
+	if len(read) != 1 || read[0].Summary != "/work/a_test.go" || read[0].Result != nil {
+		t.Errorf("read call = %+v, want no result yet", read)
+	}
+}
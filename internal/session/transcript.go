@@ -0,0 +1,187 @@
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxTailBytesForStatus is how much of the end of a JSONL file is read to derive status
+const MaxTailBytesForStatus = 64 * 1024
+
+// tailState is what the end of a transcript says Claude is doing
+type tailState int
+
+const (
+	tailUnknown    tailState = iota
+	tailWorking              // last entry is a user prompt or tool result - Claude is responding
+	tailToolUse              // assistant requested a tool that has no result yet
+	tailReply                // assistant finished with text - waiting for the user
+	tailAPIError             // last assistant entry is an API error
+	tailInterrupted          // user interrupted Claude - waiting for the user
+)
+
+// transcriptEntry is the subset of a JSONL line needed for status detection
+type transcriptEntry struct {
+	Type              string          `json:"type"`
+	Message           *MessageContent `json:"message,omitempty"`
+	IsSidechain       bool            `json:"isSidechain,omitempty"`
+	IsMeta            bool            `json:"isMeta,omitempty"`
+	IsApiErrorMessage bool            `json:"isApiErrorMessage,omitempty"`
+}
+
+// tailCacheEntry remembers the state for a file version (size + mtime)
+type tailCacheEntry struct {
+	size  int64
+	mtime time.Time
+	state tailState
+}
+
+var (
+	tailCacheMu sync.Mutex
+	tailCache   = make(map[string]tailCacheEntry)
+)
+
+// transcriptStatus refines the status of a session with an open tab using the
+// tail of its JSONL transcript. Returns StatusWaiting when the tail is inconclusive.
+func transcriptStatus(jsonlPath string) Status {
+	switch readTailState(jsonlPath) {
+	case tailToolUse:
+		return StatusNeedsPermission
+	case tailReply, tailInterrupted:
+		return StatusAwaitingReply
+	case tailAPIError:
+		return StatusErrored
+	}
+	return StatusWaiting
+}
+
+// readTailState returns the tail state of a JSONL file, cached by size and mtime
+func readTailState(path string) tailState {
+	if path == "" {
+		return tailUnknown
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return tailUnknown
+	}
+
+	tailCacheMu.Lock()
+	cached, ok := tailCache[path]
+	tailCacheMu.Unlock()
+	if ok && cached.size == info.Size() && cached.mtime.Equal(info.ModTime()) {
+		return cached.state
+	}
+
+	data, err := readFileTail(path, MaxTailBytesForStatus)
+	if err != nil {
+		return tailUnknown
+	}
+	state := parseTailState(data)
+
+	tailCacheMu.Lock()
+	tailCache[path] = tailCacheEntry{size: info.Size(), mtime: info.ModTime(), state: state}
+	tailCacheMu.Unlock()
+	return state
+}
+
+// readFileTail reads up to maxBytes from the end of a file, starting at a line boundary
+func readFileTail(path string, maxBytes int64) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if stat.Size() <= maxBytes {
+		return io.ReadAll(file)
+	}
+
+	if _, err := file.Seek(-maxBytes, io.SeekEnd); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	// Skip the partial first line
+	if idx := bytes.IndexByte(data, '\n'); idx >= 0 {
+		data = data[idx+1:]
+	}
+	return data, nil
+}
+
+// parseTailState walks the transcript tail in order and reports the final state.
+// Tool uses are tracked by ID so a tool_use is only "pending" until its tool_result arrives.
+func parseTailState(data []byte) tailState {
+	state := tailUnknown
+	pending := make(map[string]bool)
+
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		var entry transcriptEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			continue // Skip malformed (or still being written) lines
+		}
+		// Sub-agent and meta entries don't say anything about the main conversation
+		if entry.IsSidechain || entry.IsMeta || entry.Message == nil {
+			continue
+		}
+
+		parts := entry.Message.GetParts()
+		switch entry.Type {
+		case "assistant":
+			if entry.IsApiErrorMessage {
+				state = tailAPIError
+				continue
+			}
+			for _, part := range parts {
+				switch part.Type {
+				case "tool_use":
+					if part.ID != "" {
+						pending[part.ID] = true
+					}
+					state = tailToolUse
+				case "text":
+					if strings.TrimSpace(part.Text) != "" {
+						state = tailReply
+					}
+				}
+			}
+
+		case "user":
+			hasToolResult := false
+			for _, part := range parts {
+				if part.Type == "tool_result" {
+					hasToolResult = true
+					delete(pending, part.ToolUseID)
+				}
+			}
+			text := strings.TrimSpace(entry.Message.GetContent())
+			switch {
+			case strings.HasPrefix(text, "[Request interrupted"):
+				state = tailInterrupted
+			case hasToolResult && len(pending) > 0:
+				state = tailToolUse // parallel tool calls still outstanding
+			case hasToolResult || text != "":
+				state = tailWorking
+			}
+		}
+	}
+
+	// A tool_use whose result already arrived means Claude is working on the result
+	if state == tailToolUse && len(pending) == 0 {
+		return tailWorking
+	}
+	return state
+}
@@ -0,0 +1,100 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseTailState(t *testing.T) {
+	const (
+		userPrompt    = `{"type":"user","message":{"role":"user","content":"fix the bug"}}`
+		assistantText = `{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"Done!"}]}}`
+		toolUse1      = `{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"toolu_1","name":"Bash","input":{"command":"ls"}}]}}`
+		toolUse2      = `{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"toolu_2","name":"Read","input":{}}]}}`
+		toolResult1   = `{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"toolu_1","content":"file.go"}]}}`
+		apiError      = `{"type":"assistant","isApiErrorMessage":true,"message":{"role":"assistant","content":[{"type":"text","text":"API Error: 529 overloaded"}]}}`
+		interrupted   = `{"type":"user","message":{"role":"user","content":[{"type":"text","text":"[Request interrupted by user]"}]}}`
+		sidechainText = `{"type":"assistant","isSidechain":true,"message":{"role":"assistant","content":[{"type":"text","text":"sub-agent"}]}}`
+		summary       = `{"type":"summary","summary":"Fixing bug"}`
+	)
+
+	tests := []struct {
+		name  string
+		lines []string
+		want  tailState
+	}{
+		{"empty", nil, tailUnknown},
+		{"prompt only", []string{userPrompt}, tailWorking},
+		{"final text", []string{userPrompt, assistantText}, tailReply},
+		{"pending tool use", []string{userPrompt, toolUse1}, tailToolUse},
+		{"tool answered", []string{userPrompt, toolUse1, toolResult1}, tailWorking},
+		{"parallel tool still pending", []string{userPrompt, toolUse1, toolUse2, toolResult1}, tailToolUse},
+		{"text then tool use", []string{userPrompt, assistantText, toolUse1}, tailToolUse},
+		{"api error", []string{userPrompt, apiError}, tailAPIError},
+		{"error then retry prompt", []string{apiError, userPrompt}, tailWorking},
+		{"interrupted", []string{userPrompt, toolUse1, interrupted}, tailInterrupted},
+		{"sidechain ignored", []string{userPrompt, toolUse1, sidechainText}, tailToolUse},
+		{"summary ignored", []string{userPrompt, assistantText, summary}, tailReply},
+		{"partial last line", []string{userPrompt, assistantText, `{"type":"assistant","mess`}, tailReply},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseTailState([]byte(strings.Join(tt.lines, "\n")))
+			if got != tt.want {
+				t.Errorf("parseTailState() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranscriptStatus(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	write := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(`{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash"}]}}` + "\n")
+	if got := transcriptStatus(path); got != StatusNeedsPermission {
+		t.Errorf("transcriptStatus() = %v, want needs permission", got)
+	}
+
+	// Cache is keyed by size/mtime, so appending a result is picked up
+	write(`{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash"}]}}` + "\n" +
+		`{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1"}]}}` + "\n" +
+		`{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"All done"}]}}` + "\n")
+	if got := transcriptStatus(path); got != StatusAwaitingReply {
+		t.Errorf("transcriptStatus() = %v, want awaiting reply", got)
+	}
+
+	if got := transcriptStatus(filepath.Join(t.TempDir(), "missing.jsonl")); got != StatusWaiting {
+		t.Errorf("transcriptStatus(missing) = %v, want waiting", got)
+	}
+	if got := transcriptStatus(""); got != StatusWaiting {
+		t.Errorf("transcriptStatus(\"\") = %v, want waiting", got)
+	}
+}
+
+func TestReadFileTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "big.jsonl")
+	content := strings.Repeat("a", 100) + "\n" + "line2\n" + "line3\n"
+	os.WriteFile(path, []byte(content), 0644)
+
+	data, err := readFileTail(path, 10)
+	if err != nil {
+		t.Fatalf("readFileTail() error = %v", err)
+	}
+	// Partial first line is dropped
+	if string(data) != "line3\n" {
+		t.Errorf("readFileTail() = %q, want %q", data, "line3\n")
+	}
+
+	data, _ = readFileTail(path, 1000)
+	if string(data) != content {
+		t.Error("readFileTail() should return whole small files")
+	}
+}
@@ -0,0 +1,209 @@
+package session
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// UsageMonthLayout is the format of Usage's month keys
+const UsageMonthLayout = "2006-01"
+
+// Tokens counts the tokens of Claude's responses
+type Tokens struct {
+	Input      int64 `json:"input,omitempty"`
+	Output     int64 `json:"output,omitempty"`
+	CacheWrite int64 `json:"cache_write,omitempty"`
+	CacheRead  int64 `json:"cache_read,omitempty"`
+}
+
+// Total returns all tokens, cached ones included
+func (t Tokens) Total() int64 {
+	return t.Input + t.Output + t.CacheWrite + t.CacheRead
+}
+
+// Add adds other's counts to t
+func (t *Tokens) Add(other Tokens) {
+	t.Input += other.Input
+	t.Output += other.Output
+	t.CacheWrite += other.CacheWrite
+	t.CacheRead += other.CacheRead
+}
+
+// FormatTokens formats a token count compactly (812, 45.2k, 1.3M)
+func FormatTokens(n int64) string {
+	switch {
+	case n >= 1_000_000_000:
+		return fmt.Sprintf("%.1fB", float64(n)/1e9)
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1e6)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1e3)
+	}
+	return fmt.Sprint(n)
+}
+
+// Usage counts tokens by month (UsageMonthLayout, local time) and model
+type Usage map[string]map[string]Tokens
+
+// add counts a response's tokens
+func (u Usage) add(month, model string, t Tokens) {
+	models := u[month]
+	if models == nil {
+		models = make(map[string]Tokens)
+		u[month] = models
+	}
+	total := models[model]
+	total.Add(t)
+	models[model] = total
+}
+
+// Merge adds other's counts to u
+func (u Usage) Merge(other Usage) {
+	for month, models := range other {
+		for model, t := range models {
+			u.add(month, model, t)
+		}
+	}
+}
+
+// clone returns a copy that can be added to without touching u
+func (u Usage) clone() Usage {
+	c := make(Usage, len(u))
+	c.Merge(u)
+	return c
+}
+
+// Month returns the usage of one month ("" for all of it)
+func (u Usage) Month(month string) Usage {
+	if month == "" {
+		return u
+	}
+	if models, ok := u[month]; ok {
+		return Usage{month: models}
+	}
+	return nil
+}
+
+// Tokens returns the total counts over all months and models
+func (u Usage) Tokens() Tokens {
+	var total Tokens
+	for _, models := range u {
+		for _, t := range models {
+			total.Add(t)
+		}
+	}
+	return total
+}
+
+// Cost estimates the cost in USD. priced is false when some tokens were
+// used by a model the price table doesn't know (they count as free).
+func (u Usage) Cost(prices map[string]Price) (cost float64, priced bool) {
+	priced = true
+	for _, models := range u {
+		for model, t := range models {
+			price, ok := PriceFor(prices, model)
+			if !ok {
+				priced = priced && t.Total() == 0
+				continue
+			}
+			cost += price.Cost(t)
+		}
+	}
+	return cost, priced
+}
+
+// Price is what a model's tokens cost, in USD per million
+type Price struct {
+	Input      float64 `json:"input"`
+	Output     float64 `json:"output"`
+	CacheWrite float64 `json:"cache_write"`
+	CacheRead  float64 `json:"cache_read"`
+}
+
+// Cost returns the cost of t in USD
+func (p Price) Cost(t Tokens) float64 {
+	return (float64(t.Input)*p.Input + float64(t.Output)*p.Output +
+		float64(t.CacheWrite)*p.CacheWrite + float64(t.CacheRead)*p.CacheRead) / 1e6
+}
+
+// DefaultPrices are Anthropic's list prices by model name prefix (see PriceFor);
+// Settings.Prices overrides or adds to them
+var DefaultPrices = map[string]Price{
+	"claude-opus-4-5":   {Input: 5, Output: 25, CacheWrite: 6.25, CacheRead: 0.50},
+	"claude-opus-4":     {Input: 15, Output: 75, CacheWrite: 18.75, CacheRead: 1.50},
+	"claude-3-opus":     {Input: 15, Output: 75, CacheWrite: 18.75, CacheRead: 1.50},
+	"claude-sonnet-4":   {Input: 3, Output: 15, CacheWrite: 3.75, CacheRead: 0.30},
+	"claude-3-7-sonnet": {Input: 3, Output: 15, CacheWrite: 3.75, CacheRead: 0.30},
+	"claude-3-5-sonnet": {Input: 3, Output: 15, CacheWrite: 3.75, CacheRead: 0.30},
+	"claude-haiku-4-5":  {Input: 1, Output: 5, CacheWrite: 1.25, CacheRead: 0.10},
+	"claude-3-5-haiku":  {Input: 0.80, Output: 4, CacheWrite: 1, CacheRead: 0.08},
+	"claude-3-haiku":    {Input: 0.25, Output: 1.25, CacheWrite: 0.30, CacheRead: 0.03},
+}
+
+// PriceFor finds a model's price under the longest key that prefixes its name
+// ("claude-opus-4" prices "claude-opus-4-1-20250805")
+func PriceFor(prices map[string]Price, model string) (Price, bool) {
+	var best string
+	found := false
+	for key := range prices {
+		if strings.HasPrefix(model, key) && (!found || len(key) > len(best)) {
+			best, found = key, true
+		}
+	}
+	return prices[best], found
+}
+
+// UsageTotal is the usage of a set of sessions (see RollupUsage)
+type UsageTotal struct {
+	Key      string
+	Sessions int
+	Tokens   Tokens
+	Cost     float64
+	Priced   bool // every model used was in the price table
+}
+
+// RollupUsage totals sessions' usage in a month ("" for all time) by the key
+// key returns (a project, group, ...), most expensive first. Sessions without
+// usage that month are left out.
+func RollupUsage(sessions []*Session, month string, prices map[string]Price, key func(*Session) string) []UsageTotal {
+	byKey := make(map[string]Usage)
+	counts := make(map[string]int)
+	for _, s := range sessions {
+		u := s.Usage.Month(month)
+		if u.Tokens().Total() == 0 {
+			continue
+		}
+		k := key(s)
+		if byKey[k] == nil {
+			byKey[k] = make(Usage)
+		}
+		byKey[k].Merge(u)
+		counts[k]++
+	}
+
+	totals := make([]UsageTotal, 0, len(byKey))
+	for k, u := range byKey {
+		cost, priced := u.Cost(prices)
+		totals = append(totals, UsageTotal{Key: k, Sessions: counts[k], Tokens: u.Tokens(), Cost: cost, Priced: priced})
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].Cost != totals[j].Cost {
+			return totals[i].Cost > totals[j].Cost
+		}
+		if totals[i].Tokens.Total() != totals[j].Tokens.Total() {
+			return totals[i].Tokens.Total() > totals[j].Tokens.Total()
+		}
+		return totals[i].Key < totals[j].Key
+	})
+	return totals
+}
+
+// usageMonth returns the Usage month key of a time ("" if unknown)
+func usageMonth(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format(UsageMonthLayout)
+}
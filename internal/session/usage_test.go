@@ -0,0 +1,104 @@
+package session
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUsageDiscovery(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	projectDir := filepath.Join(ClaudeProjectsDir(), "-work-api")
+	os.MkdirAll(projectDir, 0755)
+	jsonlPath := filepath.Join(projectDir, "550e8400-e29b-41d4-a716-446655440000.jsonl")
+	// The response msg_1 is written as two lines repeating its usage
+	content := `{"type":"user","cwd":"/work/api","timestamp":"2026-09-15T10:00:00Z","message":{"role":"user","content":"hi"}}
+{"type":"assistant","timestamp":"2026-09-15T10:01:00Z","message":{"id":"msg_1","role":"assistant","model":"claude-sonnet-4-5-20250929","content":[{"type":"text","text":"hello"}],"usage":{"input_tokens":100,"output_tokens":50,"cache_creation_input_tokens":1000,"cache_read_input_tokens":2000}}}
+{"type":"assistant","timestamp":"2026-09-15T10:01:01Z","message":{"id":"msg_1","role":"assistant","model":"claude-sonnet-4-5-20250929","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{}}],"usage":{"input_tokens":100,"output_tokens":50,"cache_creation_input_tokens":1000,"cache_read_input_tokens":2000}}}
+{"type":"assistant","timestamp":"2026-09-15T10:02:00Z","message":{"id":"msg_2","role":"assistant","model":"<synthetic>","content":[{"type":"text","text":"No response requested."}],"usage":{"input_tokens":0,"output_tokens":0}}}
+`
+	os.WriteFile(jsonlPath, []byte(content), 0644)
+
+	discover := func() *Session {
+		t.Helper()
+		sessions, err := DiscoverSessions()
+		if err != nil || len(sessions) != 1 {
+			t.Fatalf("DiscoverSessions() = %d sessions, %v", len(sessions), err)
+		}
+		return sessions[0]
+	}
+
+	s := discover()
+	want := Tokens{Input: 100, Output: 50, CacheWrite: 1000, CacheRead: 2000}
+	if got := s.Usage.Tokens(); got != want {
+		t.Errorf("Usage.Tokens() = %+v, want %+v (duplicate lines counted once)", got, want)
+	}
+	if len(s.Usage["2026-09"]) != 1 {
+		t.Errorf("Usage = %v, want only the sonnet response in 2026-09", s.Usage)
+	}
+
+	// Appended responses are added to what was counted, by month
+	f, _ := os.OpenFile(jsonlPath, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString(`{"type":"assistant","timestamp":"2026-10-15T10:00:00Z","message":{"id":"msg_3","role":"assistant","model":"claude-opus-4-1-20250805","content":[],"usage":{"input_tokens":10,"output_tokens":20}}}` + "\n")
+	f.Close()
+	s = discover()
+	if got := s.Usage.Tokens().Total(); got != 3180 {
+		t.Errorf("Usage.Tokens().Total() = %d after append, want 3180", got)
+	}
+	if got := s.Usage.Month("2026-10").Tokens(); got != (Tokens{Input: 10, Output: 20}) {
+		t.Errorf("Usage.Month(2026-10) = %+v", got)
+	}
+}
+
+func TestUsageCost(t *testing.T) {
+	u := make(Usage)
+	u.add("2026-10", "claude-opus-4-1-20250805", Tokens{Input: 1_000_000, Output: 1_000_000})
+	u.add("2026-10", "claude-opus-4-5-20251101", Tokens{CacheRead: 1_000_000})
+
+	cost, priced := u.Cost(DefaultPrices)
+	if !priced || math.Abs(cost-(15+75+0.50)) > 1e-9 {
+		t.Errorf("Cost() = %v, %v; want 90.50 priced by the longest prefix", cost, priced)
+	}
+
+	u.add("2026-10", "some-other-model", Tokens{Output: 10})
+	if _, priced := u.Cost(DefaultPrices); priced {
+		t.Error("Cost() should report the unpriced model")
+	}
+	prices := map[string]Price{"some-other": {Output: 1_000}}
+	for model, price := range DefaultPrices {
+		prices[model] = price
+	}
+	if cost, priced := u.Cost(prices); !priced || math.Abs(cost-(90.50+0.01)) > 1e-9 {
+		t.Errorf("Cost() with a configured price = %v, %v", cost, priced)
+	}
+}
+
+func TestRollupUsage(t *testing.T) {
+	usage := func(month string, input int64) Usage {
+		u := make(Usage)
+		u.add(month, "claude-sonnet-4-5", Tokens{Input: input})
+		return u
+	}
+	sessions := []*Session{
+		{ProjectPath: "/work/api", Usage: usage("2026-10", 1_000_000)},
+		{ProjectPath: "/work/api", Usage: usage("2026-09", 5_000_000)},
+		{ProjectPath: "/work/web", Usage: usage("2026-10", 2_000_000)},
+		{ProjectPath: "/work/cli"},
+	}
+	byProject := func(s *Session) string { return s.ProjectPath }
+
+	totals := RollupUsage(sessions, "2026-10", DefaultPrices, byProject)
+	if len(totals) != 2 || totals[0].Key != "/work/web" || totals[1].Key != "/work/api" {
+		t.Fatalf("RollupUsage(2026-10) = %+v", totals)
+	}
+	if totals[1].Sessions != 1 || math.Abs(totals[1].Cost-3) > 1e-9 {
+		t.Errorf("/work/api in 2026-10 = %+v, want 1 session costing $3", totals[1])
+	}
+
+	totals = RollupUsage(sessions, "", DefaultPrices, byProject)
+	if totals[0].Key != "/work/api" || totals[0].Sessions != 2 || totals[0].Tokens.Input != 6_000_000 {
+		t.Errorf("RollupUsage(all) = %+v", totals)
+	}
+}
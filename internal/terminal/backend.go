@@ -0,0 +1,198 @@
+package terminal
+
+import (
+	"os"
+	"os/exec"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Window describes a terminal window/pane as reported by a backend
+type Window struct {
+	ID       int      // backend-specific window or pane ID
+	Title    string   // window/pane title (Claude writes its status indicator here)
+	TabTitle string   // title of the containing tab, if the backend has tabs
+	Cwd      string   // current working directory
+	Cmdline  []string // command line of the process, if the backend exposes it
+}
+
+// LaunchSpec describes a command to launch in a new terminal tab
+type LaunchSpec struct {
+	Command string // shell command to run (wrapped so the tab stays open afterwards)
+	WorkDir string
+	Title   string // optional tab title
+	// ConfigDir is the session's CLAUDE_CONFIG_DIR (empty for Claude's default).
+	// Command already sets it; backends that build the environment themselves use it too.
+	ConfigDir string
+}
+
+// Backend abstracts the terminal emulator or multiplexer that hosts Claude sessions
+type Backend interface {
+	// Name returns the backend identifier used in settings (e.g. "kitty", "tmux")
+	Name() string
+	// Launch opens a new tab running spec.Command and returns its window ID
+	Launch(spec LaunchSpec) (int, error)
+	// Focus brings an existing window to the front
+	Focus(windowID int) error
+	// Close closes a window
+	Close(windowID int) error
+	// SetTitle sets a fixed tab title for a window
+	SetTitle(windowID int, title string) error
+	// ResetTitle lets the tab title follow the window's dynamic title again
+	ResetTitle(windowID int) error
+	// ListWindows returns all windows known to the backend
+	ListWindows() ([]Window, error)
+}
+
+// backendFactories maps backend names to constructors
+var backendFactories = map[string]func() Backend{
+	"embedded": func() Backend { return sharedEmbedded() },
+	"kitty":    func() Backend { return &kittyBackend{} },
+	"tmux":     func() Backend { return &tmuxBackend{} },
+	"wezterm":  func() Backend { return &wezTermBackend{} },
+	"zellij":   func() Backend { return &zellijBackend{} },
+}
+
+// BackendNames returns the names of all available backends, sorted
+func BackendNames() []string {
+	names := make([]string, 0, len(backendFactories))
+	for name := range backendFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewBackend creates a backend by name. Returns false if the name is unknown.
+func NewBackend(name string) (Backend, bool) {
+	factory, ok := backendFactories[name]
+	if !ok {
+		return nil, false
+	}
+	return factory(), true
+}
+
+// Detect picks a backend for the current environment
+// Prefers the multiplexer the deck itself is running in, then an installed kitty or
+// tmux, and finally runs sessions embedded in the deck itself
+func Detect() Backend {
+	switch {
+	case os.Getenv("TMUX") != "":
+		return &tmuxBackend{}
+	case os.Getenv("ZELLIJ") != "":
+		return &zellijBackend{}
+	case os.Getenv("KITTY_WINDOW_ID") != "" || os.Getenv("KITTY_LISTEN_ON") != "":
+		return &kittyBackend{}
+	case os.Getenv("WEZTERM_PANE") != "":
+		return &wezTermBackend{}
+	}
+	if _, err := exec.LookPath("kitty"); err == nil {
+		return &kittyBackend{}
+	}
+	if _, err := exec.LookPath("tmux"); err == nil {
+		return &tmuxBackend{}
+	}
+	return sharedEmbedded()
+}
+
+var (
+	currentMu sync.Mutex
+	current   Backend
+)
+
+// Current returns the active backend, detecting one on first use
+func Current() Backend {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	if current == nil {
+		current = Detect()
+	}
+	return current
+}
+
+// SetBackend replaces the active backend (nil re-enables auto-detection)
+func SetBackend(b Backend) {
+	currentMu.Lock()
+	current = b
+	currentMu.Unlock()
+	InvalidateWindows()
+}
+
+// UseBackend selects a backend by name, or auto-detects when name is empty or unknown
+func UseBackend(name string) Backend {
+	b, ok := NewBackend(name)
+	if !ok {
+		b = Detect()
+	}
+	SetBackend(b)
+	return b
+}
+
+// snapshotTTL is how long a window listing is shared between callers.
+// Status refreshes fire on every JSONL write, so this caps backend queries
+// (kitty ls, tmux list-panes...) to one per refresh cycle.
+const snapshotTTL = time.Second
+
+var (
+	snapshotMu      sync.Mutex
+	snapshotWindows []Window
+	snapshotBackend Backend
+	snapshotAt      time.Time
+)
+
+// ListWindows returns all windows of the active backend
+// The result is a shared snapshot, reused for snapshotTTL - callers must not modify it.
+func ListWindows() ([]Window, error) {
+	b := Current()
+
+	// Holding the lock while listing makes concurrent callers wait for one query
+	snapshotMu.Lock()
+	defer snapshotMu.Unlock()
+	if snapshotBackend == b && time.Since(snapshotAt) < snapshotTTL {
+		return snapshotWindows, nil
+	}
+	windows, err := b.ListWindows()
+	if err != nil {
+		return nil, err
+	}
+	snapshotWindows = windows
+	snapshotBackend = b
+	snapshotAt = time.Now()
+	return windows, nil
+}
+
+// InvalidateWindows discards the cached window snapshot so the next ListWindows queries the backend
+func InvalidateWindows() {
+	snapshotMu.Lock()
+	defer snapshotMu.Unlock()
+	snapshotWindows = nil
+	snapshotBackend = nil
+}
+
+// CloseWindow closes a window by ID
+func CloseWindow(windowID int) error {
+	if windowID <= 0 {
+		return nil
+	}
+	defer InvalidateWindows()
+	return Current().Close(windowID)
+}
+
+// SetTabTitle renames the tab containing a window
+func SetTabTitle(windowID int, title string) error {
+	if windowID <= 0 || title == "" {
+		return nil
+	}
+	defer InvalidateWindows()
+	return Current().SetTitle(windowID, title)
+}
+
+// ResetTabTitle resets the tab title to use the window's dynamic title
+func ResetTabTitle(windowID int) error {
+	if windowID <= 0 {
+		return nil
+	}
+	defer InvalidateWindows()
+	return Current().ResetTitle(windowID)
+}
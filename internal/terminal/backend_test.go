@@ -0,0 +1,174 @@
+package terminal
+
+import (
+	"testing"
+)
+
+func TestNewBackend(t *testing.T) {
+	for _, name := range BackendNames() {
+		b, ok := NewBackend(name)
+		if !ok {
+			t.Errorf("NewBackend(%q) not found", name)
+			continue
+		}
+		if b.Name() != name {
+			t.Errorf("NewBackend(%q).Name() = %q", name, b.Name())
+		}
+	}
+
+	if _, ok := NewBackend("nonexistent"); ok {
+		t.Error("NewBackend(nonexistent) should return false")
+	}
+}
+
+func TestUseBackend(t *testing.T) {
+	defer SetBackend(nil)
+
+	b := UseBackend("tmux")
+	if b.Name() != "tmux" {
+		t.Errorf("UseBackend(tmux).Name() = %q", b.Name())
+	}
+	if Current() != b {
+		t.Error("Current() should return the selected backend")
+	}
+
+	// Unknown names fall back to detection
+	if b := UseBackend("nonexistent"); b == nil {
+		t.Error("UseBackend with unknown name should auto-detect")
+	}
+}
+
+func TestDetectTmux(t *testing.T) {
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,1234,0")
+	if b := Detect(); b.Name() != "tmux" {
+		t.Errorf("Detect() inside tmux = %q, want tmux", b.Name())
+	}
+}
+
+func TestDetectKitty(t *testing.T) {
+	t.Setenv("TMUX", "")
+	t.Setenv("ZELLIJ", "")
+	t.Setenv("KITTY_WINDOW_ID", "1")
+	if b := Detect(); b.Name() != "kitty" {
+		t.Errorf("Detect() inside kitty = %q, want kitty", b.Name())
+	}
+}
+
+func TestParseKittyLs(t *testing.T) {
+	output := `[{"tabs":[{"title":"✳ my-project","windows":[
+		{"id":3,"title":"⠂ Fixing bug","cwd":"/home/user/project","cmdline":["zsh","-i","-c","claude --resume abc"]},
+		{"id":4,"title":"zsh","cwd":"/tmp","cmdline":["zsh"]}
+	]}]}]`
+
+	windows, err := parseKittyLs([]byte(output))
+	if err != nil {
+		t.Fatalf("parseKittyLs() error = %v", err)
+	}
+	if len(windows) != 2 {
+		t.Fatalf("expected 2 windows, got %d", len(windows))
+	}
+	w := windows[0]
+	if w.ID != 3 || w.Title != "⠂ Fixing bug" || w.TabTitle != "✳ my-project" || w.Cwd != "/home/user/project" {
+		t.Errorf("unexpected window: %+v", w)
+	}
+	if len(w.Cmdline) != 4 {
+		t.Errorf("expected 4 cmdline parts, got %d", len(w.Cmdline))
+	}
+
+	if _, err := parseKittyLs([]byte("not json")); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestParseTmuxPanes(t *testing.T) {
+	output := "%0\tzsh\thost\t/home/user\tzsh\tzsh\n" +
+		"%12\tmy-session\t⠂ Working\t/home/user/project\tzsh -i -c \"cd /home/user/project && claude --resume abc; exec zsh\"\tclaude\n" +
+		"bad line\n" +
+		"%x\ta\tb\tc\td\te\n"
+
+	windows := parseTmuxPanes(output)
+	if len(windows) != 2 {
+		t.Fatalf("expected 2 windows, got %d", len(windows))
+	}
+	if windows[0].ID != 1 {
+		t.Errorf("pane %%0 should map to window ID 1, got %d", windows[0].ID)
+	}
+	w := windows[1]
+	if w.ID != 13 || w.TabTitle != "my-session" || w.Title != "⠂ Working" || w.Cwd != "/home/user/project" {
+		t.Errorf("unexpected window: %+v", w)
+	}
+	if len(w.Cmdline) != 2 || w.Cmdline[1] != "claude" {
+		t.Errorf("unexpected cmdline: %v", w.Cmdline)
+	}
+}
+
+func TestTmuxPaneIDRoundTrip(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int
+	}{
+		{"%0", 1},
+		{"%42", 43},
+		{"42", 0},
+		{"%", 0},
+		{"%-1", 0},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		got := parseTmuxPaneID(tt.input)
+		if got != tt.want {
+			t.Errorf("parseTmuxPaneID(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+		if got > 0 && tmuxTarget(got) != tt.input {
+			t.Errorf("tmuxTarget(%d) = %q, want %q", got, tmuxTarget(got), tt.input)
+		}
+	}
+}
+
+func TestDetectZellij(t *testing.T) {
+	t.Setenv("TMUX", "")
+	t.Setenv("ZELLIJ", "0")
+	if b := Detect(); b.Name() != "zellij" {
+		t.Errorf("Detect() inside zellij = %q, want zellij", b.Name())
+	}
+}
+
+func TestDetectWezTerm(t *testing.T) {
+	t.Setenv("TMUX", "")
+	t.Setenv("ZELLIJ", "")
+	t.Setenv("KITTY_WINDOW_ID", "")
+	t.Setenv("KITTY_LISTEN_ON", "")
+	t.Setenv("WEZTERM_PANE", "3")
+	if b := Detect(); b.Name() != "wezterm" {
+		t.Errorf("Detect() inside wezterm = %q, want wezterm", b.Name())
+	}
+}
+
+// countingBackend counts ListWindows calls
+type countingBackend struct {
+	kittyBackend
+	calls int
+}
+
+func (b *countingBackend) ListWindows() ([]Window, error) {
+	b.calls++
+	return []Window{{ID: 1}}, nil
+}
+
+func TestListWindowsSnapshot(t *testing.T) {
+	b := &countingBackend{}
+	SetBackend(b)
+	defer SetBackend(nil)
+
+	ListWindows()
+	ListWindows()
+	if b.calls != 1 {
+		t.Errorf("expected one backend query for repeated calls, got %d", b.calls)
+	}
+
+	InvalidateWindows()
+	windows, _ := ListWindows()
+	if b.calls != 2 || len(windows) != 1 {
+		t.Errorf("expected a fresh query after invalidation, got %d calls", b.calls)
+	}
+}
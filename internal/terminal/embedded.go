@@ -0,0 +1,394 @@
+package terminal
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/creack/pty"
+	"github.com/hinshun/vt10x"
+)
+
+// Default size for embedded terminals until the UI resizes them
+const (
+	embeddedDefaultCols = 80
+	embeddedDefaultRows = 24
+)
+
+// Glyph attribute bits used by vt10x (not exported by the package)
+const (
+	vtAttrReverse = 1 << iota
+	vtAttrUnderline
+	vtAttrBold
+	vtAttrGfx
+	vtAttrItalic
+)
+
+// embeddedProc is a process running in a pseudo-terminal owned by the deck
+type embeddedProc struct {
+	id       int
+	cmd      *exec.Cmd
+	ptmx     *os.File
+	vt       vt10x.Terminal
+	cmdline  []string
+	cwd      string
+	tabTitle string // title set by SetTitle (the vt title is Claude's own)
+	title    string // last title seen, for change notifications
+}
+
+// EmbeddedBackend runs sessions in pseudo-terminals inside the deck itself.
+// Used when no terminal multiplexer is available. Process liveness comes from the
+// process itself: a window disappears from ListWindows as soon as it exits, and
+// Claude's spinner title is read from the emulated screen.
+type EmbeddedBackend struct {
+	mu     sync.Mutex
+	nextID int
+	procs  map[int]*embeddedProc
+
+	output chan struct{} // signalled (coalesced) when any screen changes
+	state  chan struct{} // signalled when a process exits or its title changes
+}
+
+// NewEmbeddedBackend creates an embedded backend with no running processes
+func NewEmbeddedBackend() *EmbeddedBackend {
+	return &EmbeddedBackend{
+		nextID: 1,
+		procs:  make(map[int]*embeddedProc),
+		output: make(chan struct{}, 1),
+		state:  make(chan struct{}, 1),
+	}
+}
+
+func (b *EmbeddedBackend) Name() string { return "embedded" }
+
+// Launch starts the command in a new pseudo-terminal and returns its window ID
+// The command is not wrapped in an interactive shell: when Claude exits, so does the window.
+func (b *EmbeddedBackend) Launch(spec LaunchSpec) (int, error) {
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+	cmdline := []string{shell, "-c", spec.Command}
+	cmd := exec.Command(cmdline[0], cmdline[1:]...)
+	cmd.Dir = spec.WorkDir
+	cmd.Env = embeddedEnv(spec.ConfigDir)
+
+	ptmx, err := pty.StartWithSize(cmd, &pty.Winsize{Cols: embeddedDefaultCols, Rows: embeddedDefaultRows})
+	if err != nil {
+		return 0, fmt.Errorf("failed to start embedded terminal: %v", err)
+	}
+
+	b.mu.Lock()
+	p := &embeddedProc{
+		id:       b.nextID,
+		cmd:      cmd,
+		ptmx:     ptmx,
+		vt:       vt10x.New(vt10x.WithWriter(ptmx), vt10x.WithSize(embeddedDefaultCols, embeddedDefaultRows)),
+		cmdline:  cmdline,
+		cwd:      spec.WorkDir,
+		tabTitle: spec.Title,
+	}
+	b.nextID++
+	b.procs[p.id] = p
+	b.mu.Unlock()
+
+	go b.readLoop(p)
+	go b.waitLoop(p)
+	return p.id, nil
+}
+
+// embeddedEnv is the environment of an embedded session: the deck's own, except
+// that CLAUDE_CONFIG_DIR is the session's (unset for Claude's default data root)
+func embeddedEnv(configDir string) []string {
+	var env []string
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, "CLAUDE_CONFIG_DIR=") && !strings.HasPrefix(kv, "TERM=") {
+			env = append(env, kv)
+		}
+	}
+	env = append(env, "TERM=xterm-256color")
+	if configDir != "" {
+		env = append(env, "CLAUDE_CONFIG_DIR="+configDir)
+	}
+	return env
+}
+
+// readLoop feeds pty output into the terminal emulator until the pty closes
+func (b *EmbeddedBackend) readLoop(p *embeddedProc) {
+	buf := make([]byte, 32*1024)
+	var pending []byte // incomplete UTF-8 sequence carried over between reads
+	for {
+		n, err := p.ptmx.Read(buf)
+		if n > 0 {
+			data := append(pending, buf[:n]...)
+			written, _ := p.vt.Write(data)
+			pending = append([]byte(nil), data[written:]...)
+
+			p.vt.Lock()
+			title := p.vt.Title()
+			p.vt.Unlock()
+
+			b.mu.Lock()
+			titleChanged := title != p.title
+			p.title = title
+			b.mu.Unlock()
+
+			notify(b.output)
+			if titleChanged {
+				InvalidateWindows()
+				notify(b.state)
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// waitLoop reaps the process and removes its window
+func (b *EmbeddedBackend) waitLoop(p *embeddedProc) {
+	p.cmd.Wait()
+	p.ptmx.Close()
+
+	b.mu.Lock()
+	delete(b.procs, p.id)
+	b.mu.Unlock()
+
+	InvalidateWindows()
+	notify(b.output)
+	notify(b.state)
+}
+
+// notify signals a channel without blocking (pending signals are coalesced)
+func notify(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// Output returns a channel signalled whenever an embedded screen changes
+func (b *EmbeddedBackend) Output() <-chan struct{} { return b.output }
+
+// StateChanges returns a channel signalled when a process exits or changes its title
+func (b *EmbeddedBackend) StateChanges() <-chan struct{} { return b.state }
+
+func (b *EmbeddedBackend) get(windowID int) *embeddedProc {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.procs[windowID]
+}
+
+// Alive returns true if the window's process is still running
+func (b *EmbeddedBackend) Alive(windowID int) bool {
+	return b.get(windowID) != nil
+}
+
+// Focus succeeds if the window is still running (the UI attaches to it)
+func (b *EmbeddedBackend) Focus(windowID int) error {
+	if !b.Alive(windowID) {
+		return fmt.Errorf("embedded window %d not found", windowID)
+	}
+	return nil
+}
+
+// Close hangs up the window's process
+func (b *EmbeddedBackend) Close(windowID int) error {
+	p := b.get(windowID)
+	if p == nil {
+		return nil
+	}
+	return p.cmd.Process.Signal(syscall.SIGHUP)
+}
+
+// CloseAll hangs up every embedded process (used when the deck exits)
+func (b *EmbeddedBackend) CloseAll() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, p := range b.procs {
+		p.cmd.Process.Signal(syscall.SIGHUP)
+	}
+}
+
+// SetTitle sets the tab title reported for a window
+func (b *EmbeddedBackend) SetTitle(windowID int, title string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if p := b.procs[windowID]; p != nil {
+		p.tabTitle = title
+	}
+	return nil
+}
+
+// ResetTitle clears the tab title so the window's own title is used
+func (b *EmbeddedBackend) ResetTitle(windowID int) error {
+	return b.SetTitle(windowID, "")
+}
+
+// ListWindows returns all running embedded processes
+func (b *EmbeddedBackend) ListWindows() ([]Window, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	result := make([]Window, 0, len(b.procs))
+	for _, p := range b.procs {
+		result = append(result, Window{
+			ID:       p.id,
+			Title:    p.title,
+			TabTitle: p.tabTitle,
+			Cwd:      p.cwd,
+			Cmdline:  p.cmdline,
+		})
+	}
+	// Map iteration order is random - keep launch order for stable matching
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+// Write sends input (keystrokes) to a window's process
+func (b *EmbeddedBackend) Write(windowID int, data []byte) error {
+	p := b.get(windowID)
+	if p == nil {
+		return fmt.Errorf("embedded window %d not found", windowID)
+	}
+	_, err := p.ptmx.Write(data)
+	return err
+}
+
+// Resize changes a window's terminal size (no-op if unchanged)
+func (b *EmbeddedBackend) Resize(windowID, cols, rows int) error {
+	p := b.get(windowID)
+	if p == nil || cols <= 0 || rows <= 0 {
+		return nil
+	}
+	p.vt.Lock()
+	c, r := p.vt.Size()
+	p.vt.Unlock()
+	if c == cols && r == rows {
+		return nil
+	}
+	p.vt.Resize(cols, rows)
+	return pty.Setsize(p.ptmx, &pty.Winsize{Cols: uint16(cols), Rows: uint16(rows)})
+}
+
+// AppCursorKeys returns true if the window's program requested application cursor keys
+func (b *EmbeddedBackend) AppCursorKeys(windowID int) bool {
+	p := b.get(windowID)
+	if p == nil {
+		return false
+	}
+	p.vt.Lock()
+	defer p.vt.Unlock()
+	return p.vt.Mode()&vt10x.ModeAppCursor != 0
+}
+
+// Screen renders a window's screen as ANSI-styled text
+// If showCursor is true, the cursor cell is drawn in reverse video.
+func (b *EmbeddedBackend) Screen(windowID int, showCursor bool) (string, bool) {
+	p := b.get(windowID)
+	if p == nil {
+		return "", false
+	}
+	return renderScreen(p.vt, showCursor), true
+}
+
+// renderScreen converts the emulator's cells into lines with SGR escape sequences
+func renderScreen(vt vt10x.View, showCursor bool) string {
+	vt.Lock()
+	defer vt.Unlock()
+
+	cols, rows := vt.Size()
+	cursor := vt.Cursor()
+	showCursor = showCursor && vt.CursorVisible()
+
+	var sb strings.Builder
+	for y := 0; y < rows; y++ {
+		if y > 0 {
+			sb.WriteByte('\n')
+		}
+		lastSGR := ""
+		for x := 0; x < cols; x++ {
+			cell := vt.Cell(x, y)
+			mode := cell.Mode
+			if showCursor && x == cursor.X && y == cursor.Y {
+				mode ^= vtAttrReverse
+			}
+			if sgr := glyphSGR(cell.FG, cell.BG, mode); sgr != lastSGR {
+				sb.WriteString("\x1b[0")
+				sb.WriteString(sgr)
+				sb.WriteByte('m')
+				lastSGR = sgr
+			}
+			ch := cell.Char
+			if ch == 0 {
+				ch = ' '
+			}
+			sb.WriteRune(ch)
+		}
+		if lastSGR != "" {
+			sb.WriteString("\x1b[0m")
+		}
+	}
+	return sb.String()
+}
+
+// glyphSGR returns the SGR parameters (each prefixed with ';') for a cell's style
+func glyphSGR(fg, bg vt10x.Color, mode int16) string {
+	var sb strings.Builder
+	if mode&vtAttrBold != 0 {
+		sb.WriteString(";1")
+	}
+	if mode&vtAttrItalic != 0 {
+		sb.WriteString(";3")
+	}
+	if mode&vtAttrUnderline != 0 {
+		sb.WriteString(";4")
+	}
+	if mode&vtAttrReverse != 0 {
+		sb.WriteString(";7")
+	}
+	sb.WriteString(colorSGR(fg, 30, 90, 38, vt10x.DefaultFG))
+	sb.WriteString(colorSGR(bg, 40, 100, 48, vt10x.DefaultBG))
+	return sb.String()
+}
+
+// colorSGR encodes a vt10x color as SGR parameters
+func colorSGR(c vt10x.Color, base, brightBase, extended int, def vt10x.Color) string {
+	switch {
+	case c == def || c >= 1<<24:
+		return ""
+	case c < 8:
+		return ";" + strconv.Itoa(base+int(c))
+	case c < 16:
+		return ";" + strconv.Itoa(brightBase+int(c)-8)
+	case c < 256:
+		return fmt.Sprintf(";%d;5;%d", extended, c)
+	default:
+		return fmt.Sprintf(";%d;2;%d;%d;%d", extended, (c>>16)&0xff, (c>>8)&0xff, c&0xff)
+	}
+}
+
+var (
+	embeddedOnce     sync.Once
+	embeddedInstance *EmbeddedBackend
+)
+
+// sharedEmbedded returns the process-wide embedded backend
+// There is only one, so re-selecting the backend never orphans running sessions.
+func sharedEmbedded() *EmbeddedBackend {
+	embeddedOnce.Do(func() {
+		embeddedInstance = NewEmbeddedBackend()
+	})
+	return embeddedInstance
+}
+
+// Embedded returns the active backend if it is the embedded one, or nil
+func Embedded() *EmbeddedBackend {
+	b, _ := Current().(*EmbeddedBackend)
+	return b
+}
@@ -0,0 +1,132 @@
+package terminal
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hinshun/vt10x"
+)
+
+func TestRenderScreen(t *testing.T) {
+	vt := vt10x.New(vt10x.WithSize(10, 2))
+	vt.Write([]byte("\x1b[31mred\x1b[0m ok\r\nline2"))
+
+	screen := renderScreen(vt, false)
+	lines := strings.Split(screen, "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+	if !strings.Contains(lines[0], "\x1b[0;31mred") {
+		t.Errorf("expected red SGR before text, got %q", lines[0])
+	}
+	if !strings.Contains(lines[0], "\x1b[0m ok") {
+		t.Errorf("expected reset after red text, got %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "line2") {
+		t.Errorf("expected plain second line, got %q", lines[1])
+	}
+
+	// Cursor sits after "line2" and is drawn in reverse video
+	withCursor := strings.Split(renderScreen(vt, true), "\n")[1]
+	if !strings.Contains(withCursor, "line2\x1b[0;7m ") {
+		t.Errorf("expected reverse-video cursor, got %q", withCursor)
+	}
+}
+
+func TestColorSGR(t *testing.T) {
+	tests := []struct {
+		color vt10x.Color
+		want  string
+	}{
+		{vt10x.DefaultFG, ""},
+		{vt10x.Red, ";31"},
+		{vt10x.LightBlue, ";94"},
+		{200, ";38;5;200"},
+		{vt10x.Color(0x102030), ";38;2;16;32;48"},
+	}
+	for _, tt := range tests {
+		if got := colorSGR(tt.color, 30, 90, 38, vt10x.DefaultFG); got != tt.want {
+			t.Errorf("colorSGR(%d) = %q, want %q", tt.color, got, tt.want)
+		}
+	}
+}
+
+func TestEmbeddedBackendLifecycle(t *testing.T) {
+	t.Setenv("SHELL", "/bin/sh")
+	b := NewEmbeddedBackend()
+
+	id, err := b.Launch(LaunchSpec{
+		Command: `printf '\033]0;\342\240\202 Working\007hello'; sleep 5`,
+		WorkDir: t.TempDir(),
+		Title:   "my-session",
+	})
+	if err != nil {
+		t.Skipf("pty not available: %v", err)
+	}
+	defer b.Close(id)
+
+	// Wait for the title and output to arrive
+	deadline := time.Now().Add(3 * time.Second)
+	var windows []Window
+	for time.Now().Before(deadline) {
+		windows, _ = b.ListWindows()
+		if len(windows) == 1 && windows[0].Title != "" {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if len(windows) != 1 {
+		t.Fatalf("expected 1 window, got %d", len(windows))
+	}
+	w := windows[0]
+	if w.ID != id || w.Title != "⠂ Working" || w.TabTitle != "my-session" {
+		t.Errorf("unexpected window: %+v", w)
+	}
+	if screen, ok := b.Screen(id, false); !ok || !strings.Contains(screen, "hello") {
+		t.Errorf("expected screen to contain output, got %q", screen)
+	}
+	if err := b.Resize(id, 40, 10); err != nil {
+		t.Errorf("Resize() error = %v", err)
+	}
+
+	// Closing the window makes it disappear once the process exits
+	b.Close(id)
+	deadline = time.Now().Add(3 * time.Second)
+	for b.Alive(id) && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if b.Alive(id) {
+		t.Error("window should be gone after Close")
+	}
+	if err := b.Focus(id); err == nil {
+		t.Error("Focus on exited window should fail")
+	}
+}
+
+func TestEmbeddedEnv(t *testing.T) {
+	t.Setenv("CLAUDE_CONFIG_DIR", "/deck/config")
+
+	count := func(env []string, kv string) int {
+		n := 0
+		for _, e := range env {
+			if e == kv || (strings.HasSuffix(kv, "=") && strings.HasPrefix(e, kv)) {
+				n++
+			}
+		}
+		return n
+	}
+
+	env := embeddedEnv("")
+	if n := count(env, "CLAUDE_CONFIG_DIR="); n != 0 {
+		t.Errorf("embeddedEnv(\"\") has %d CLAUDE_CONFIG_DIR entries, want the deck's left out", n)
+	}
+	if n := count(env, "TERM=xterm-256color"); n != 1 {
+		t.Errorf("embeddedEnv(\"\") has %d TERM=xterm-256color entries, want 1", n)
+	}
+
+	env = embeddedEnv("/session/config")
+	if count(env, "CLAUDE_CONFIG_DIR=") != 1 || count(env, "CLAUDE_CONFIG_DIR=/session/config") != 1 {
+		t.Errorf("embeddedEnv(/session/config) should set only the session's config dir")
+	}
+}
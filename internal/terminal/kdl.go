@@ -0,0 +1,211 @@
+package terminal
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// kdlNode is a node in a KDL document (the subset zellij emits for layouts)
+type kdlNode struct {
+	Name     string
+	Args     []string
+	Props    map[string]string
+	Children []*kdlNode
+}
+
+// Child returns the first child with the given name, or nil
+func (n *kdlNode) Child(name string) *kdlNode {
+	for _, c := range n.Children {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// parseKDL parses a KDL document into a list of top-level nodes
+// Supports nodes, string/bare arguments, key=value properties, children blocks
+// and // or /* */ comments. Type annotations and raw strings are not supported.
+func parseKDL(input string) ([]*kdlNode, error) {
+	p := &kdlParser{src: []rune(input)}
+	nodes, err := p.parseNodes(false)
+	if err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+type kdlParser struct {
+	src []rune
+	pos int
+}
+
+func (p *kdlParser) parseNodes(inBlock bool) ([]*kdlNode, error) {
+	var nodes []*kdlNode
+	for {
+		p.skipSpaceAndNewlines()
+		if p.pos >= len(p.src) {
+			if inBlock {
+				return nil, fmt.Errorf("kdl: unexpected end of input, missing '}'")
+			}
+			return nodes, nil
+		}
+		if p.src[p.pos] == '}' {
+			if !inBlock {
+				return nil, fmt.Errorf("kdl: unexpected '}' at offset %d", p.pos)
+			}
+			p.pos++
+			return nodes, nil
+		}
+		node, err := p.parseNode()
+		if err != nil {
+			return nil, err
+		}
+		if node != nil {
+			nodes = append(nodes, node)
+		}
+	}
+}
+
+func (p *kdlParser) parseNode() (*kdlNode, error) {
+	name, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	node := &kdlNode{Name: name, Props: make(map[string]string)}
+
+	for {
+		p.skipInlineSpace()
+		if p.pos >= len(p.src) {
+			return node, nil
+		}
+		switch c := p.src[p.pos]; {
+		case c == '\n' || c == ';':
+			p.pos++
+			return node, nil
+		case c == '}':
+			return node, nil
+		case c == '{':
+			p.pos++
+			children, err := p.parseNodes(true)
+			if err != nil {
+				return nil, err
+			}
+			node.Children = children
+			return node, nil
+		default:
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			if p.pos < len(p.src) && p.src[p.pos] == '=' {
+				p.pos++
+				propValue, err := p.parseValue()
+				if err != nil {
+					return nil, err
+				}
+				node.Props[value] = propValue
+			} else {
+				node.Args = append(node.Args, value)
+			}
+		}
+	}
+}
+
+// parseValue parses a quoted string or a bare identifier/number/keyword
+func (p *kdlParser) parseValue() (string, error) {
+	if p.pos >= len(p.src) {
+		return "", fmt.Errorf("kdl: unexpected end of input")
+	}
+	if p.src[p.pos] == '"' {
+		return p.parseString()
+	}
+	start := p.pos
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if unicode.IsSpace(c) || c == '=' || c == '{' || c == '}' || c == ';' || c == '"' {
+			break
+		}
+		p.pos++
+	}
+	if p.pos == start {
+		return "", fmt.Errorf("kdl: unexpected %q at offset %d", p.src[p.pos], p.pos)
+	}
+	return string(p.src[start:p.pos]), nil
+}
+
+func (p *kdlParser) parseString() (string, error) {
+	p.pos++ // opening quote
+	var sb strings.Builder
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch c {
+		case '"':
+			p.pos++
+			return sb.String(), nil
+		case '\\':
+			p.pos++
+			if p.pos >= len(p.src) {
+				return "", fmt.Errorf("kdl: unterminated escape")
+			}
+			switch e := p.src[p.pos]; e {
+			case 'n':
+				sb.WriteRune('\n')
+			case 't':
+				sb.WriteRune('\t')
+			case 'r':
+				sb.WriteRune('\r')
+			default:
+				sb.WriteRune(e)
+			}
+		default:
+			sb.WriteRune(c)
+		}
+		p.pos++
+	}
+	return "", fmt.Errorf("kdl: unterminated string")
+}
+
+func (p *kdlParser) skipInlineSpace() {
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if c == '/' && p.pos+1 < len(p.src) && (p.src[p.pos+1] == '/' || p.src[p.pos+1] == '*') {
+			p.skipComment()
+			continue
+		}
+		if c == '\\' && p.pos+1 < len(p.src) && p.src[p.pos+1] == '\n' {
+			p.pos += 2 // line continuation
+			continue
+		}
+		if c == '\n' || !unicode.IsSpace(c) {
+			return
+		}
+		p.pos++
+	}
+}
+
+func (p *kdlParser) skipSpaceAndNewlines() {
+	for p.pos < len(p.src) {
+		p.skipInlineSpace()
+		if p.pos < len(p.src) && (p.src[p.pos] == '\n' || p.src[p.pos] == ';') {
+			p.pos++
+			continue
+		}
+		return
+	}
+}
+
+func (p *kdlParser) skipComment() {
+	if p.src[p.pos+1] == '/' {
+		for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+			p.pos++
+		}
+		return
+	}
+	p.pos += 2
+	for p.pos+1 < len(p.src) && !(p.src[p.pos] == '*' && p.src[p.pos+1] == '/') {
+		p.pos++
+	}
+	p.pos += 2
+}
@@ -0,0 +1,171 @@
+package terminal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// kittyWindow represents a window in kitty's JSON output
+type kittyWindow struct {
+	ID      int      `json:"id"`
+	Title   string   `json:"title"`
+	Cmdline []string `json:"cmdline"`
+	Cwd     string   `json:"cwd"`
+}
+
+// kittyTab represents a tab in kitty's JSON output
+type kittyTab struct {
+	Title   string        `json:"title"`
+	Windows []kittyWindow `json:"windows"`
+}
+
+// kittyOSWindow represents an OS window in kitty's JSON output
+type kittyOSWindow struct {
+	Tabs []kittyTab `json:"tabs"`
+}
+
+// kittyBackend drives Kitty through its remote control interface.
+// Commands go straight to the $KITTY_LISTEN_ON socket when available, falling
+// back to spawning `kitty @` otherwise.
+type kittyBackend struct{}
+
+func (b *kittyBackend) Name() string { return "kitty" }
+
+// Launch opens a new tab in Kitty and returns the window ID
+func (b *kittyBackend) Launch(spec LaunchSpec) (int, error) {
+	wrappedCmd := fmt.Sprintf("%s; exec zsh", spec.Command)
+	if c := sharedKittyClient(); c != nil {
+		payload := map[string]interface{}{
+			"type": "tab",
+			"cwd":  spec.WorkDir,
+			"args": []string{"zsh", "-i", "-c", wrappedCmd},
+		}
+		if spec.Title != "" {
+			payload["tab_title"] = spec.Title
+		}
+		data, err := c.Command("launch", payload)
+		if err == nil {
+			var windowID int
+			fmt.Sscanf(kittyDataString(data), "%d", &windowID)
+			return windowID, nil
+		}
+		// Launching again via kitty @ could open a second tab
+		if kittyCommandSent(err) {
+			return 0, err
+		}
+	}
+
+	args := []string{"@", "launch", "--type=tab", "--cwd", spec.WorkDir}
+	if spec.Title != "" {
+		args = append(args, "--tab-title", spec.Title)
+	}
+	args = append(args, "zsh", "-i", "-c", wrappedCmd)
+	cmd := exec.Command("kitty", args...)
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, fmt.Errorf("kitty launch failed: %v", err)
+	}
+	var windowID int
+	fmt.Sscanf(strings.TrimSpace(string(output)), "%d", &windowID)
+	return windowID, nil
+}
+
+// Focus focuses an existing kitty window by ID
+func (b *kittyBackend) Focus(windowID int) error {
+	if handled, err := b.socketCommand("focus-window", map[string]interface{}{"match": fmt.Sprintf("id:%d", windowID)}); handled {
+		return err
+	}
+	cmd := exec.Command("kitty", "@", "focus-window", "--match", fmt.Sprintf("id:%d", windowID))
+	return cmd.Run()
+}
+
+// Close closes a kitty window by ID
+func (b *kittyBackend) Close(windowID int) error {
+	if windowID <= 0 {
+		return nil
+	}
+	if handled, err := b.socketCommand("close-window", map[string]interface{}{"match": fmt.Sprintf("id:%d", windowID)}); handled {
+		return err
+	}
+	cmd := exec.Command("kitty", "@", "close-window", "--match", fmt.Sprintf("id:%d", windowID))
+	return cmd.Run()
+}
+
+// SetTitle renames a kitty tab by window ID
+func (b *kittyBackend) SetTitle(windowID int, title string) error {
+	if windowID <= 0 || title == "" {
+		return nil
+	}
+	if handled, err := b.socketCommand("set-tab-title", map[string]interface{}{"match": fmt.Sprintf("window_id:%d", windowID), "title": title}); handled {
+		return err
+	}
+	cmd := exec.Command("kitty", "@", "set-tab-title", "--match", fmt.Sprintf("window_id:%d", windowID), title)
+	return cmd.Run()
+}
+
+// ResetTitle resets the tab title to use the window's dynamic title
+func (b *kittyBackend) ResetTitle(windowID int) error {
+	if windowID <= 0 {
+		return nil
+	}
+	// An empty title makes kitty go back to the window's dynamic title
+	if handled, err := b.socketCommand("set-tab-title", map[string]interface{}{"match": fmt.Sprintf("window_id:%d", windowID), "title": ""}); handled {
+		return err
+	}
+	cmd := exec.Command("kitty", "@", "set-tab-title", "--match", fmt.Sprintf("window_id:%d", windowID))
+	return cmd.Run()
+}
+
+// ListWindows returns all kitty windows via ls
+func (b *kittyBackend) ListWindows() ([]Window, error) {
+	if c := sharedKittyClient(); c != nil {
+		if data, err := c.Command("ls", nil); err == nil {
+			return parseKittyLs([]byte(kittyDataString(data)))
+		}
+	}
+	output, err := exec.Command("kitty", "@", "ls").Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseKittyLs(output)
+}
+
+// socketCommand runs a command over the remote control socket
+// Returns handled=false if the command couldn't be sent, so callers fall back to kitty @
+func (b *kittyBackend) socketCommand(cmd string, payload interface{}) (handled bool, err error) {
+	c := sharedKittyClient()
+	if c == nil {
+		return false, nil
+	}
+	_, err = c.Command(cmd, payload)
+	if err != nil && !kittyCommandSent(err) {
+		return false, nil
+	}
+	return true, err
+}
+
+// parseKittyLs flattens kitty's OS window → tab → window tree into a window list
+func parseKittyLs(output []byte) ([]Window, error) {
+	var osWindows []kittyOSWindow
+	if err := json.Unmarshal(output, &osWindows); err != nil {
+		return nil, err
+	}
+
+	var result []Window
+	for _, osWin := range osWindows {
+		for _, tab := range osWin.Tabs {
+			for _, win := range tab.Windows {
+				result = append(result, Window{
+					ID:       win.ID,
+					Title:    win.Title,
+					TabTitle: tab.Title,
+					Cwd:      win.Cwd,
+					Cmdline:  win.Cmdline,
+				})
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,223 @@
+package terminal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kitty remote control messages are JSON wrapped in a DCS escape sequence
+const (
+	kittyRCPrefix = "\x1bP@kitty-cmd"
+	kittyRCSuffix = "\x1b\\"
+)
+
+// kittyRCVersion is the protocol version sent with each command
+var kittyRCVersion = []int{0, 26, 0}
+
+// kittyRCTimeout bounds a single command round trip
+const kittyRCTimeout = 2 * time.Second
+
+// kittyRequest is a remote control command
+type kittyRequest struct {
+	Cmd        string      `json:"cmd"`
+	Version    []int       `json:"version"`
+	NoResponse bool        `json:"no_response,omitempty"`
+	Payload    interface{} `json:"payload,omitempty"`
+}
+
+// kittyResponse is kitty's reply to a command
+type kittyResponse struct {
+	OK    bool            `json:"ok"`
+	Data  json.RawMessage `json:"data"`
+	Error string          `json:"error"`
+}
+
+// kittyError is an error reported by kitty itself (as opposed to a transport failure)
+type kittyError struct {
+	cmd string
+	msg string
+}
+
+func (e *kittyError) Error() string {
+	return fmt.Sprintf("kitty %s failed: %s", e.cmd, e.msg)
+}
+
+// kittyUnansweredError is a transport failure after a command was written:
+// kitty may have run it, so it must not be sent again (by retrying or via kitty @)
+type kittyUnansweredError struct {
+	cmd string
+	err error
+}
+
+func (e *kittyUnansweredError) Error() string {
+	return fmt.Sprintf("kitty %s: no response: %v", e.cmd, e.err)
+}
+
+func (e *kittyUnansweredError) Unwrap() error { return e.err }
+
+// kittyCommandSent reports whether a Command error means kitty got the command
+func kittyCommandSent(err error) bool {
+	switch err.(type) {
+	case *kittyError, *kittyUnansweredError:
+		return true
+	}
+	return false
+}
+
+// kittyClient talks to kitty's listen_on socket directly, keeping one connection
+// open and redialing if kitty closed it
+type kittyClient struct {
+	mu      sync.Mutex
+	network string
+	address string
+	conn    net.Conn
+	reader  *bufio.Reader
+}
+
+var (
+	kittyClientMu     sync.Mutex
+	kittyClientShared *kittyClient
+)
+
+// sharedKittyClient returns the client for $KITTY_LISTEN_ON, or nil if it isn't set
+// All callers share one client (and so one connection) per socket address.
+func sharedKittyClient() *kittyClient {
+	network, address, ok := parseKittyListenOn(os.Getenv("KITTY_LISTEN_ON"))
+	if !ok {
+		return nil
+	}
+	kittyClientMu.Lock()
+	defer kittyClientMu.Unlock()
+	if c := kittyClientShared; c != nil && c.network == network && c.address == address {
+		return c
+	}
+	if kittyClientShared != nil {
+		kittyClientShared.Close()
+	}
+	kittyClientShared = &kittyClient{network: network, address: address}
+	return kittyClientShared
+}
+
+// parseKittyListenOn parses a listen_on address like unix:/tmp/kitty-123,
+// unix:@abstract or tcp:localhost:12345
+func parseKittyListenOn(listenOn string) (network, address string, ok bool) {
+	network, address, found := strings.Cut(listenOn, ":")
+	if !found || address == "" {
+		return "", "", false
+	}
+	switch network {
+	case "unix", "tcp":
+		return network, address, true
+	}
+	return "", "", false
+}
+
+// Command sends a command and returns the response data
+func (c *kittyClient) Command(cmd string, payload interface{}) (json.RawMessage, error) {
+	msg, err := json.Marshal(kittyRequest{Cmd: cmd, Version: kittyRCVersion, Payload: payload})
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// A reused connection may have been closed by kitty - if writing to it
+	// fails, nothing was sent, so retry once on a fresh one
+	reused := c.conn != nil
+	resp, written, err := c.roundTrip(msg)
+	if err != nil && reused && !written {
+		c.closeLocked()
+		resp, written, err = c.roundTrip(msg)
+	}
+	if err != nil {
+		c.closeLocked()
+		if written {
+			return nil, &kittyUnansweredError{cmd: cmd, err: err}
+		}
+		return nil, err
+	}
+	if !resp.OK {
+		return nil, &kittyError{cmd: cmd, msg: resp.Error}
+	}
+	return resp.Data, nil
+}
+
+// roundTrip writes one command and reads one response (caller holds c.mu)
+// written reports whether the command went out, even if no response came back.
+func (c *kittyClient) roundTrip(msg []byte) (resp *kittyResponse, written bool, err error) {
+	if c.conn == nil {
+		conn, err := net.DialTimeout(c.network, c.address, kittyRCTimeout)
+		if err != nil {
+			return nil, false, err
+		}
+		c.conn = conn
+		c.reader = bufio.NewReader(conn)
+	}
+	c.conn.SetDeadline(time.Now().Add(kittyRCTimeout))
+
+	if _, err := c.conn.Write([]byte(kittyRCPrefix + string(msg) + kittyRCSuffix)); err != nil {
+		return nil, false, err
+	}
+	raw, err := readKittyMessage(c.reader)
+	if err != nil {
+		return nil, true, err
+	}
+	resp = &kittyResponse{}
+	if err := json.Unmarshal(raw, resp); err != nil {
+		return nil, true, fmt.Errorf("invalid kitty response: %v", err)
+	}
+	return resp, true, nil
+}
+
+// readKittyMessage reads one DCS-wrapped message and returns its JSON body
+func readKittyMessage(r *bufio.Reader) ([]byte, error) {
+	var buf []byte
+	for {
+		chunk, err := r.ReadBytes('\\')
+		buf = append(buf, chunk...)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.HasSuffix(buf, []byte(kittyRCSuffix)) {
+			break
+		}
+	}
+	start := bytes.Index(buf, []byte(kittyRCPrefix))
+	if start == -1 {
+		return nil, fmt.Errorf("invalid kitty response: missing header")
+	}
+	return buf[start+len(kittyRCPrefix) : len(buf)-len(kittyRCSuffix)], nil
+}
+
+// Close closes the connection (the next command redials)
+func (c *kittyClient) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closeLocked()
+}
+
+func (c *kittyClient) closeLocked() {
+	if c.conn != nil {
+		c.conn.Close()
+		c.conn = nil
+		c.reader = nil
+	}
+}
+
+// kittyDataString decodes response data that kitty sends as a JSON string
+// (ls output and launched window IDs are strings holding the real value)
+func kittyDataString(data json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		return s
+	}
+	return strings.TrimSpace(string(data))
+}
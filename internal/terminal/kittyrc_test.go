@@ -0,0 +1,210 @@
+package terminal
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// fakeKitty serves kitty's remote control protocol on a unix socket
+type fakeKitty struct {
+	listener    net.Listener
+	mu          sync.Mutex
+	accepts     int
+	commands    []kittyRequest
+	closeAfter1 bool   // close each connection after one response (like older kitty versions)
+	noReply     string // command to drop the connection on without responding
+	closed      chan struct{}
+}
+
+func newFakeKitty(t *testing.T) *fakeKitty {
+	dir, err := os.MkdirTemp("", "kitty-rc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	socket := filepath.Join(dir, "kitty.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets not available: %v", err)
+	}
+	f := &fakeKitty{listener: l, closed: make(chan struct{}, 16)}
+	t.Cleanup(func() { l.Close() })
+	t.Setenv("KITTY_LISTEN_ON", "unix:"+socket)
+	t.Cleanup(func() {
+		if c := sharedKittyClient(); c != nil {
+			c.Close()
+		}
+	})
+	go f.serve()
+	return f
+}
+
+func (f *fakeKitty) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		f.mu.Lock()
+		f.accepts++
+		f.mu.Unlock()
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeKitty) handle(conn net.Conn) {
+	defer func() {
+		conn.Close()
+		f.closed <- struct{}{}
+	}()
+	r := bufio.NewReader(conn)
+	for {
+		raw, err := readKittyMessage(r)
+		if err != nil {
+			return
+		}
+		var req kittyRequest
+		json.Unmarshal(raw, &req)
+		f.mu.Lock()
+		f.commands = append(f.commands, req)
+		f.mu.Unlock()
+		if req.Cmd == f.noReply {
+			return
+		}
+
+		var resp kittyResponse
+		switch req.Cmd {
+		case "ls":
+			ls := `[{"tabs":[{"title":"✳ proj","windows":[{"id":7,"title":"⠂ Working","cwd":"/p","cmdline":["claude"]}]}]}]`
+			data, _ := json.Marshal(ls)
+			resp = kittyResponse{OK: true, Data: data}
+		case "launch":
+			resp = kittyResponse{OK: true, Data: json.RawMessage(`"42"`)}
+		case "close-window":
+			resp = kittyResponse{OK: false, Error: "No matching windows"}
+		default:
+			resp = kittyResponse{OK: true}
+		}
+		out, _ := json.Marshal(resp)
+		conn.Write([]byte(kittyRCPrefix + string(out) + kittyRCSuffix))
+		if f.closeAfter1 {
+			return
+		}
+	}
+}
+
+func TestKittySocketBackend(t *testing.T) {
+	f := newFakeKitty(t)
+	b := &kittyBackend{}
+
+	windows, err := b.ListWindows()
+	if err != nil {
+		t.Fatalf("ListWindows() error = %v", err)
+	}
+	if len(windows) != 1 || windows[0].ID != 7 || windows[0].Title != "⠂ Working" || windows[0].TabTitle != "✳ proj" {
+		t.Errorf("unexpected windows: %+v", windows)
+	}
+
+	id, err := b.Launch(LaunchSpec{Command: "claude", WorkDir: "/p", Title: "my tab"})
+	if err != nil || id != 42 {
+		t.Errorf("Launch() = %d, %v; want 42, nil", id, err)
+	}
+	if err := b.SetTitle(42, "renamed"); err != nil {
+		t.Errorf("SetTitle() error = %v", err)
+	}
+	// Errors reported by kitty are returned rather than retried via kitty @
+	if err := b.Close(99); err == nil {
+		t.Error("Close() should return kitty's error")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.accepts != 1 {
+		t.Errorf("expected all commands on one connection, got %d connections", f.accepts)
+	}
+	if len(f.commands) != 4 {
+		t.Fatalf("expected 4 commands, got %d", len(f.commands))
+	}
+	launch, _ := f.commands[1].Payload.(map[string]interface{})
+	if f.commands[1].Cmd != "launch" || launch["tab_title"] != "my tab" || launch["type"] != "tab" {
+		t.Errorf("unexpected launch command: %+v", f.commands[1])
+	}
+}
+
+func TestKittySocketReconnect(t *testing.T) {
+	f := newFakeKitty(t)
+	f.closeAfter1 = true
+	b := &kittyBackend{}
+
+	for i := 0; i < 3; i++ {
+		if err := b.Focus(1); err != nil {
+			t.Fatalf("Focus() #%d error = %v", i, err)
+		}
+		<-f.closed // the next command finds the connection closed
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.commands) != 3 || f.accepts != 3 {
+		t.Errorf("expected 3 commands on 3 connections, got %d on %d", len(f.commands), f.accepts)
+	}
+}
+
+func TestKittySocketNoResponse(t *testing.T) {
+	f := newFakeKitty(t)
+	f.noReply = "launch"
+	b := &kittyBackend{}
+
+	// Reuse the connection first, so a retry would be tempting
+	if err := b.Focus(1); err != nil {
+		t.Fatalf("Focus() error = %v", err)
+	}
+	_, err := b.Launch(LaunchSpec{Command: "claude", WorkDir: "/p"})
+	var unanswered *kittyUnansweredError
+	if !errors.As(err, &unanswered) {
+		t.Fatalf("Launch() error = %v, want the unanswered launch reported", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.commands) != 2 || f.accepts != 1 {
+		t.Errorf("launch should be sent once, not retried: got %d commands on %d connections", len(f.commands), f.accepts)
+	}
+}
+
+func TestParseKittyListenOn(t *testing.T) {
+	tests := []struct {
+		input   string
+		network string
+		address string
+		ok      bool
+	}{
+		{"unix:/tmp/kitty-123", "unix", "/tmp/kitty-123", true},
+		{"unix:@kitty", "unix", "@kitty", true},
+		{"tcp:localhost:5000", "tcp", "localhost:5000", true},
+		{"fd:3", "", "", false},
+		{"unix:", "", "", false},
+		{"", "", "", false},
+	}
+	for _, tt := range tests {
+		network, address, ok := parseKittyListenOn(tt.input)
+		if network != tt.network || address != tt.address || ok != tt.ok {
+			t.Errorf("parseKittyListenOn(%q) = %q, %q, %v", tt.input, network, address, ok)
+		}
+	}
+}
+
+func TestKittyDataString(t *testing.T) {
+	if got := kittyDataString(json.RawMessage(`"12"`)); got != "12" {
+		t.Errorf("kittyDataString(string) = %q", got)
+	}
+	if got := kittyDataString(json.RawMessage(`12`)); got != "12" {
+		t.Errorf("kittyDataString(number) = %q", got)
+	}
+}
@@ -2,79 +2,46 @@ package terminal
 
 import (
 	"fmt"
-	"os/exec"
-	"strings"
+	"os"
 )
 
-// OpenSession opens a Claude session in a new kitty tab, or focuses existing tab
-// Returns the kitty window ID
-func OpenSession(projectPath, sessionID string, activeWindowID int, tabTitle string) (int, error) {
+// OpenSession opens a Claude session in a new terminal tab, or focuses existing tab
+// configDir is the session's CLAUDE_CONFIG_DIR (empty for Claude's default).
+// Returns the backend window ID
+func OpenSession(projectPath, sessionID, configDir string, activeWindowID int, tabTitle string) (int, error) {
+	backend := Current()
+
 	// If session already has an active tab, focus it instead of opening new one
 	if activeWindowID > 0 {
-		if err := focusKittyWindow(activeWindowID); err == nil {
+		if err := backend.Focus(activeWindowID); err == nil {
 			return activeWindowID, nil
 		}
 		// Fall through to open new tab if focus fails
 	}
 
-	claudeCmd := fmt.Sprintf("cd %q && claude --resume %s", projectPath, sessionID)
-	return openInKitty(claudeCmd, projectPath, tabTitle)
+	claudeCmd := claudeCommand(projectPath, configDir, "--resume "+sessionID)
+	defer InvalidateWindows()
+	return backend.Launch(LaunchSpec{Command: claudeCmd, WorkDir: projectPath, Title: tabTitle, ConfigDir: configDir})
 }
 
-// NewSession opens a new Claude session in a new kitty tab
-// Returns the kitty window ID
+// NewSession opens a new Claude session in a new terminal tab
+// It runs with the deck's own CLAUDE_CONFIG_DIR, if any.
+// Returns the backend window ID
 func NewSession(projectPath string, tabTitle string) (int, error) {
-	claudeCmd := fmt.Sprintf("cd %q && claude", projectPath)
-	return openInKitty(claudeCmd, projectPath, tabTitle)
-}
-
-// openInKitty opens a new tab in Kitty and returns the window ID
-func openInKitty(command string, workDir string, tabTitle string) (int, error) {
-	wrappedCmd := fmt.Sprintf("%s; exec zsh", command)
-	args := []string{"@", "launch", "--type=tab", "--cwd", workDir}
-	if tabTitle != "" {
-		args = append(args, "--tab-title", tabTitle)
-	}
-	args = append(args, "zsh", "-i", "-c", wrappedCmd)
-	cmd := exec.Command("kitty", args...)
-	output, err := cmd.Output()
-	if err != nil {
-		return 0, fmt.Errorf("kitty launch failed: %v", err)
-	}
-	var windowID int
-	fmt.Sscanf(strings.TrimSpace(string(output)), "%d", &windowID)
-	return windowID, nil
-}
-
-// focusKittyWindow focuses an existing kitty window by ID
-func focusKittyWindow(windowID int) error {
-	cmd := exec.Command("kitty", "@", "focus-window", "--match", fmt.Sprintf("id:%d", windowID))
-	return cmd.Run()
-}
-
-// CloseKittyWindow closes a kitty window by ID
-func CloseKittyWindow(windowID int) error {
-	if windowID <= 0 {
-		return nil
-	}
-	cmd := exec.Command("kitty", "@", "close-window", "--match", fmt.Sprintf("id:%d", windowID))
-	return cmd.Run()
+	configDir := os.Getenv("CLAUDE_CONFIG_DIR")
+	claudeCmd := claudeCommand(projectPath, configDir, "")
+	defer InvalidateWindows()
+	return Current().Launch(LaunchSpec{Command: claudeCmd, WorkDir: projectPath, Title: tabTitle, ConfigDir: configDir})
 }
 
-// SetKittyTabTitle renames a kitty tab by window ID
-func SetKittyTabTitle(windowID int, title string) error {
-	if windowID <= 0 || title == "" {
-		return nil
+// claudeCommand builds the shell command that starts claude in projectPath
+func claudeCommand(projectPath, configDir, args string) string {
+	cmd := "claude"
+	if configDir != "" {
+		cmd = fmt.Sprintf("CLAUDE_CONFIG_DIR=%q claude", configDir)
 	}
-	cmd := exec.Command("kitty", "@", "set-tab-title", "--match", fmt.Sprintf("window_id:%d", windowID), title)
-	return cmd.Run()
-}
-
-// ResetKittyTabTitle resets the tab title to use the window's dynamic title
-func ResetKittyTabTitle(windowID int) error {
-	if windowID <= 0 {
-		return nil
+	if args != "" {
+		cmd += " " + args
 	}
-	cmd := exec.Command("kitty", "@", "set-tab-title", "--match", fmt.Sprintf("window_id:%d", windowID))
-	return cmd.Run()
+	return fmt.Sprintf("cd %q && %s", projectPath, cmd)
 }
@@ -0,0 +1,124 @@
+package terminal
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// tmuxListFormat is the list-panes format: pane ID, window name, pane title, cwd,
+// start command and current command, tab-separated
+const tmuxListFormat = "#{pane_id}\t#{window_name}\t#{pane_title}\t#{pane_current_path}\t#{pane_start_command}\t#{pane_current_command}"
+
+// tmuxBackend drives tmux. Window IDs are tmux pane numbers plus one,
+// since tmux numbers panes from %0 and a window ID of 0 means "no window".
+type tmuxBackend struct{}
+
+func (b *tmuxBackend) Name() string { return "tmux" }
+
+// Launch opens a new tmux window and returns its window ID
+func (b *tmuxBackend) Launch(spec LaunchSpec) (int, error) {
+	wrappedCmd := fmt.Sprintf("%s; exec zsh", spec.Command)
+	args := []string{"new-window", "-P", "-F", "#{pane_id}", "-c", spec.WorkDir}
+	if spec.Title != "" {
+		args = append(args, "-n", spec.Title)
+	}
+	args = append(args, "zsh", "-i", "-c", wrappedCmd)
+	output, err := exec.Command("tmux", args...).Output()
+	if err != nil {
+		return 0, fmt.Errorf("tmux new-window failed: %v", err)
+	}
+	return parseTmuxPaneID(strings.TrimSpace(string(output))), nil
+}
+
+// Focus selects the window and pane containing a pane ID
+func (b *tmuxBackend) Focus(windowID int) error {
+	target := tmuxTarget(windowID)
+	if err := exec.Command("tmux", "select-window", "-t", target).Run(); err != nil {
+		return err
+	}
+	return exec.Command("tmux", "select-pane", "-t", target).Run()
+}
+
+// Close kills a tmux pane
+func (b *tmuxBackend) Close(windowID int) error {
+	if windowID <= 0 {
+		return nil
+	}
+	return exec.Command("tmux", "kill-pane", "-t", tmuxTarget(windowID)).Run()
+}
+
+// SetTitle renames the tmux window containing a pane
+func (b *tmuxBackend) SetTitle(windowID int, title string) error {
+	if windowID <= 0 || title == "" {
+		return nil
+	}
+	return exec.Command("tmux", "rename-window", "-t", tmuxTarget(windowID), title).Run()
+}
+
+// ResetTitle turns automatic window renaming back on
+func (b *tmuxBackend) ResetTitle(windowID int) error {
+	if windowID <= 0 {
+		return nil
+	}
+	return exec.Command("tmux", "set-window-option", "-t", tmuxTarget(windowID), "automatic-rename", "on").Run()
+}
+
+// ListWindows returns every pane in every tmux session
+func (b *tmuxBackend) ListWindows() ([]Window, error) {
+	output, err := exec.Command("tmux", "list-panes", "-a", "-F", tmuxListFormat).Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseTmuxPanes(string(output)), nil
+}
+
+// parseTmuxPanes parses list-panes output produced with tmuxListFormat
+func parseTmuxPanes(output string) []Window {
+	var result []Window
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) < 6 {
+			continue
+		}
+		id := parseTmuxPaneID(fields[0])
+		if id <= 0 {
+			continue
+		}
+		var cmdline []string
+		for _, c := range fields[4:6] {
+			if c != "" {
+				cmdline = append(cmdline, c)
+			}
+		}
+		result = append(result, Window{
+			ID:       id,
+			TabTitle: fields[1],
+			Title:    fields[2],
+			Cwd:      fields[3],
+			Cmdline:  cmdline,
+		})
+	}
+	return result
+}
+
+// parseTmuxPaneID converts a pane ID like "%12" to a window ID (13). Returns 0 if invalid.
+func parseTmuxPaneID(s string) int {
+	if !strings.HasPrefix(s, "%") {
+		return 0
+	}
+	n, err := strconv.Atoi(s[1:])
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n + 1
+}
+
+// tmuxTarget converts a window ID back into a tmux pane target
+func tmuxTarget(windowID int) string {
+	return fmt.Sprintf("%%%d", windowID-1)
+}
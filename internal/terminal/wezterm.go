@@ -0,0 +1,120 @@
+package terminal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// wezTermPane represents a pane in `wezterm cli list --format json` output
+type wezTermPane struct {
+	WindowID int    `json:"window_id"`
+	TabID    int    `json:"tab_id"`
+	PaneID   int    `json:"pane_id"`
+	Title    string `json:"title"`
+	TabTitle string `json:"tab_title"`
+	Cwd      string `json:"cwd"`
+}
+
+// wezTermBackend drives WezTerm through `wezterm cli`. Window IDs are pane IDs
+// plus one, since WezTerm numbers panes from 0.
+// WezTerm doesn't report command lines, so sessions are matched by stored
+// window ID, title indicators and cwd.
+type wezTermBackend struct{}
+
+func (b *wezTermBackend) Name() string { return "wezterm" }
+
+// Launch spawns a new WezTerm tab and returns its window ID
+func (b *wezTermBackend) Launch(spec LaunchSpec) (int, error) {
+	wrappedCmd := fmt.Sprintf("%s; exec zsh", spec.Command)
+	output, err := exec.Command("wezterm", "cli", "spawn", "--cwd", spec.WorkDir, "--", "zsh", "-i", "-c", wrappedCmd).Output()
+	if err != nil {
+		return 0, fmt.Errorf("wezterm spawn failed: %v", err)
+	}
+	paneID, err := strconv.Atoi(strings.TrimSpace(string(output)))
+	if err != nil {
+		return 0, nil
+	}
+	windowID := paneID + 1
+	if spec.Title != "" {
+		b.SetTitle(windowID, spec.Title)
+	}
+	return windowID, nil
+}
+
+// Focus activates a pane (and its tab)
+func (b *wezTermBackend) Focus(windowID int) error {
+	return exec.Command("wezterm", "cli", "activate-pane", "--pane-id", wezTermPaneID(windowID)).Run()
+}
+
+// Close kills a pane
+func (b *wezTermBackend) Close(windowID int) error {
+	if windowID <= 0 {
+		return nil
+	}
+	return exec.Command("wezterm", "cli", "kill-pane", "--pane-id", wezTermPaneID(windowID)).Run()
+}
+
+// SetTitle sets the title of the tab containing a pane
+func (b *wezTermBackend) SetTitle(windowID int, title string) error {
+	if windowID <= 0 || title == "" {
+		return nil
+	}
+	return exec.Command("wezterm", "cli", "set-tab-title", "--pane-id", wezTermPaneID(windowID), title).Run()
+}
+
+// ResetTitle clears the tab title so it follows the pane title again
+func (b *wezTermBackend) ResetTitle(windowID int) error {
+	if windowID <= 0 {
+		return nil
+	}
+	return exec.Command("wezterm", "cli", "set-tab-title", "--pane-id", wezTermPaneID(windowID), "").Run()
+}
+
+// ListWindows returns all WezTerm panes
+func (b *wezTermBackend) ListWindows() ([]Window, error) {
+	output, err := exec.Command("wezterm", "cli", "list", "--format", "json").Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseWezTermList(output)
+}
+
+// parseWezTermList converts `wezterm cli list --format json` output into windows
+func parseWezTermList(output []byte) ([]Window, error) {
+	var panes []wezTermPane
+	if err := json.Unmarshal(output, &panes); err != nil {
+		return nil, err
+	}
+
+	result := make([]Window, 0, len(panes))
+	for _, p := range panes {
+		result = append(result, Window{
+			ID:       p.PaneID + 1,
+			Title:    p.Title, // Claude's spinner/indicator lives in the pane title
+			TabTitle: p.TabTitle,
+			Cwd:      wezTermCwdPath(p.Cwd),
+		})
+	}
+	return result, nil
+}
+
+// wezTermCwdPath converts WezTerm's file://host/path cwd URL into a plain path
+func wezTermCwdPath(cwd string) string {
+	if !strings.HasPrefix(cwd, "file://") {
+		return cwd
+	}
+	u, err := url.Parse(cwd)
+	if err != nil {
+		return cwd
+	}
+	return strings.TrimSuffix(u.Path, "/")
+}
+
+// wezTermPaneID converts a window ID back into a WezTerm pane ID argument
+func wezTermPaneID(windowID int) string {
+	return strconv.Itoa(windowID - 1)
+}
@@ -0,0 +1,59 @@
+package terminal
+
+import (
+	"testing"
+)
+
+func TestParseWezTermList(t *testing.T) {
+	output := `[
+	  {"window_id":0,"tab_id":0,"pane_id":0,"workspace":"default","title":"zsh","tab_title":"","cwd":"file://laptop/home/user/"},
+	  {"window_id":0,"tab_id":1,"pane_id":4,"workspace":"default","title":"⠂ Refactoring parser","tab_title":"api work","cwd":"file://laptop/home/user/my%20project"}
+	]`
+
+	windows, err := parseWezTermList([]byte(output))
+	if err != nil {
+		t.Fatalf("parseWezTermList() error = %v", err)
+	}
+	if len(windows) != 2 {
+		t.Fatalf("expected 2 windows, got %d", len(windows))
+	}
+	if windows[0].ID != 1 || windows[0].Cwd != "/home/user" {
+		t.Errorf("unexpected first window: %+v", windows[0])
+	}
+
+	w := windows[1]
+	if w.ID != 5 || w.TabTitle != "api work" || w.Cwd != "/home/user/my project" {
+		t.Errorf("unexpected second window: %+v", w)
+	}
+	// Pane title must be preserved verbatim so spinner detection works
+	if w.Title != "⠂ Refactoring parser" {
+		t.Errorf("pane title = %q, want spinner title", w.Title)
+	}
+
+	if _, err := parseWezTermList([]byte("{}")); err == nil {
+		t.Error("expected error for non-array JSON")
+	}
+}
+
+func TestWezTermCwdPath(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"file://host/home/user/project/", "/home/user/project"},
+		{"file:///tmp", "/tmp"},
+		{"/already/a/path", "/already/a/path"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := wezTermCwdPath(tt.input); got != tt.want {
+			t.Errorf("wezTermCwdPath(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestWezTermPaneID(t *testing.T) {
+	if got := wezTermPaneID(1); got != "0" {
+		t.Errorf("wezTermPaneID(1) = %q, want 0", got)
+	}
+}
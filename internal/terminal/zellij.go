@@ -0,0 +1,309 @@
+package terminal
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Zellij window IDs pack a pane's tab and pane position (both 1-based) with a
+// fingerprint of its cwd and command, all from `zellij action dump-layout`:
+//
+//	ID = fingerprint<<(tabBits+paneBits) | tab<<paneBits | pane
+//
+// The result fits in 31 bits. Panes beyond the limits get no ID.
+const (
+	zellijPaneBits        = 9  // up to 511 panes per tab
+	zellijTabBits         = 10 // up to 1023 tabs
+	zellijFingerprintBits = 12
+)
+
+// zellijBackend drives Zellij through `zellij action`.
+// Zellij has no stable pane IDs on the CLI, so window IDs encode the tab and
+// pane position from `zellij action dump-layout`. Positions shift when tabs are
+// reordered or closed, so the ID's fingerprint is checked against the pane now
+// at that position before acting on it; a mismatch is reported as not found
+// and --resume matching covers the gap.
+//
+// dump-layout doesn't carry the title a program sets on its pane (a pane's
+// `name` is only one given by the user or a layout), so windows have no Title
+// and Claude's spinner can't be seen: a zellij session's status comes from
+// hooks and the transcript tail alone.
+type zellijBackend struct{}
+
+func (b *zellijBackend) Name() string { return "zellij" }
+
+// Launch opens a new zellij tab running the command and returns its window ID
+func (b *zellijBackend) Launch(spec LaunchSpec) (int, error) {
+	layout, err := writeZellijLayout(spec)
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(layout)
+
+	args := []string{"action", "new-tab", "--layout", layout, "--cwd", spec.WorkDir}
+	if spec.Title != "" {
+		args = append(args, "--name", spec.Title)
+	}
+	if err := exec.Command("zellij", args...).Run(); err != nil {
+		return 0, fmt.Errorf("zellij new-tab failed: %v", err)
+	}
+
+	// The new tab is focused - find it in the layout to get its window ID
+	tabs, err := zellijDumpLayout()
+	if err != nil {
+		return 0, fmt.Errorf("zellij dump-layout after new-tab failed: %v", err)
+	}
+	for _, tab := range tabs {
+		if tab.focused && len(tab.panes) > 0 {
+			if tab.panes[0].ID == 0 {
+				return 0, fmt.Errorf("zellij tab is beyond the %d tabs claude-deck can track", 1<<zellijTabBits-1)
+			}
+			return tab.panes[0].ID, nil
+		}
+	}
+	return 0, fmt.Errorf("zellij new tab not found in layout")
+}
+
+// Focus switches to the tab containing a window
+func (b *zellijBackend) Focus(windowID int) error {
+	tabs, err := zellijDumpLayout()
+	if err != nil {
+		return err
+	}
+	tab, err := zellijTabOf(tabs, windowID)
+	if err != nil {
+		return err
+	}
+	return exec.Command("zellij", "action", "go-to-tab", strconv.Itoa(tab)).Run()
+}
+
+// Close closes the tab containing a window
+// Only single-pane tabs are closed, since zellij can't target a pane by position
+func (b *zellijBackend) Close(windowID int) error {
+	if windowID <= 0 {
+		return nil
+	}
+	tabs, err := zellijDumpLayout()
+	if err != nil {
+		return err
+	}
+	tab, err := zellijTabOf(tabs, windowID)
+	if err != nil {
+		return err
+	}
+	if len(tabs[tab-1].panes) > 1 {
+		return fmt.Errorf("zellij tab %d has multiple panes, not closing", tab)
+	}
+	if err := exec.Command("zellij", "action", "go-to-tab", strconv.Itoa(tab)).Run(); err != nil {
+		return err
+	}
+	return exec.Command("zellij", "action", "close-tab").Run()
+}
+
+// SetTitle renames the tab containing a window, then returns focus to the previous tab
+func (b *zellijBackend) SetTitle(windowID int, title string) error {
+	if windowID <= 0 || title == "" {
+		return nil
+	}
+	return b.withTabFocused(windowID, "rename-tab", title)
+}
+
+// ResetTitle undoes a manual tab rename
+func (b *zellijBackend) ResetTitle(windowID int) error {
+	if windowID <= 0 {
+		return nil
+	}
+	return b.withTabFocused(windowID, "undo-rename-tab")
+}
+
+// withTabFocused runs a tab action against a window's tab and restores focus afterwards
+func (b *zellijBackend) withTabFocused(windowID int, action ...string) error {
+	tabs, err := zellijDumpLayout()
+	if err != nil {
+		return err
+	}
+	tab, err := zellijTabOf(tabs, windowID)
+	if err != nil {
+		return err
+	}
+	previous := 0
+	for i, t := range tabs {
+		if t.focused {
+			previous = i + 1
+		}
+	}
+	if err := exec.Command("zellij", "action", "go-to-tab", strconv.Itoa(tab)).Run(); err != nil {
+		return err
+	}
+	err = exec.Command("zellij", append([]string{"action"}, action...)...).Run()
+	if previous > 0 && previous != tab {
+		exec.Command("zellij", "action", "go-to-tab", strconv.Itoa(previous)).Run()
+	}
+	return err
+}
+
+// ListWindows returns every terminal pane in the current zellij session
+func (b *zellijBackend) ListWindows() ([]Window, error) {
+	tabs, err := zellijDumpLayout()
+	if err != nil {
+		return nil, err
+	}
+	var result []Window
+	for _, tab := range tabs {
+		result = append(result, tab.panes...)
+	}
+	return result, nil
+}
+
+// zellijTab is a tab from zellij's layout dump
+type zellijTab struct {
+	name    string
+	focused bool
+	panes   []Window
+}
+
+// zellijDumpLayout runs `zellij action dump-layout` and parses its tabs
+func zellijDumpLayout() ([]zellijTab, error) {
+	output, err := exec.Command("zellij", "action", "dump-layout").Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseZellijLayout(string(output))
+}
+
+// parseZellijLayout extracts tabs and their terminal panes from a KDL layout dump
+func parseZellijLayout(layout string) ([]zellijTab, error) {
+	nodes, err := parseKDL(layout)
+	if err != nil {
+		return nil, err
+	}
+
+	// The dump is wrapped in a single `layout { ... }` node
+	root := &kdlNode{Children: nodes}
+	if l := root.Child("layout"); l != nil {
+		root = l
+	}
+	rootCwd := ""
+	if c := root.Child("cwd"); c != nil && len(c.Args) > 0 {
+		rootCwd = c.Args[0]
+	}
+
+	var tabs []zellijTab
+	for _, node := range root.Children {
+		if node.Name != "tab" {
+			continue
+		}
+		tabPos := len(tabs) + 1
+		tab := zellijTab{
+			name:    node.Props["name"],
+			focused: node.Props["focus"] == "true",
+		}
+		tabCwd := joinZellijCwd(rootCwd, node.Props["cwd"])
+
+		var leaves []*kdlNode
+		collectZellijPanes(node, &leaves)
+		for i, pane := range leaves {
+			var cmdline []string
+			if cmd := pane.Props["command"]; cmd != "" {
+				cmdline = append(cmdline, cmd)
+				if args := pane.Child("args"); args != nil {
+					cmdline = append(cmdline, args.Args...)
+				}
+			}
+			cwd := joinZellijCwd(tabCwd, pane.Props["cwd"])
+			tab.panes = append(tab.panes, Window{
+				ID:       zellijWindowID(tabPos, i+1, cwd, cmdline),
+				TabTitle: tab.name,
+				Cwd:      cwd,
+				Cmdline:  cmdline,
+			})
+		}
+		tabs = append(tabs, tab)
+	}
+	return tabs, nil
+}
+
+// collectZellijPanes collects leaf terminal panes (skipping plugin panes like tab-bar)
+func collectZellijPanes(node *kdlNode, leaves *[]*kdlNode) {
+	hasChildPane := false
+	for _, c := range node.Children {
+		if c.Name == "pane" {
+			hasChildPane = true
+			collectZellijPanes(c, leaves)
+		}
+	}
+	if node.Name == "pane" && !hasChildPane && node.Child("plugin") == nil {
+		*leaves = append(*leaves, node)
+	}
+}
+
+// joinZellijCwd resolves a pane cwd, which zellij writes relative to its parent's cwd
+func joinZellijCwd(base, cwd string) string {
+	if cwd == "" {
+		return base
+	}
+	if filepath.IsAbs(cwd) || base == "" {
+		return cwd
+	}
+	return filepath.Join(base, cwd)
+}
+
+// zellijWindowID builds the window ID of a pane (0 if its position is out of range)
+func zellijWindowID(tab, pane int, cwd string, cmdline []string) int {
+	if tab >= 1<<zellijTabBits || pane >= 1<<zellijPaneBits {
+		return 0
+	}
+	h := fnv.New32a()
+	h.Write([]byte(cwd))
+	for _, arg := range cmdline {
+		h.Write([]byte{0})
+		h.Write([]byte(arg))
+	}
+	fingerprint := int(h.Sum32() & (1<<zellijFingerprintBits - 1))
+	return fingerprint<<(zellijTabBits+zellijPaneBits) | tab<<zellijPaneBits | pane
+}
+
+// splitZellijID splits a window ID into 1-based tab and pane positions
+func splitZellijID(windowID int) (tab, pane int) {
+	return windowID >> zellijPaneBits & (1<<zellijTabBits - 1), windowID & (1<<zellijPaneBits - 1)
+}
+
+// zellijTabOf returns the 1-based position of a window's tab, checking that the
+// pane at the window's position is still the same one
+func zellijTabOf(tabs []zellijTab, windowID int) (int, error) {
+	tab, pane := splitZellijID(windowID)
+	if windowID > 0 && tab >= 1 && tab <= len(tabs) && pane >= 1 && pane <= len(tabs[tab-1].panes) &&
+		tabs[tab-1].panes[pane-1].ID == windowID {
+		return tab, nil
+	}
+	return 0, fmt.Errorf("zellij window %d not found", windowID)
+}
+
+// writeZellijLayout writes a single-pane layout that runs the launch command
+func writeZellijLayout(spec LaunchSpec) (string, error) {
+	f, err := os.CreateTemp("", "claude-deck-*.kdl")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	wrappedCmd := fmt.Sprintf("%s; exec zsh", spec.Command)
+	name := ""
+	if spec.Title != "" {
+		name = fmt.Sprintf(" name=%s", kdlQuote(spec.Title))
+	}
+	fmt.Fprintf(f, "layout {\n    pane command=\"zsh\" cwd=%s%s {\n        args \"-i\" \"-c\" %s\n    }\n}\n",
+		kdlQuote(spec.WorkDir), name, kdlQuote(wrappedCmd))
+	return f.Name(), nil
+}
+
+// kdlQuote quotes a string for use in a KDL document
+func kdlQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + r.Replace(s) + `"`
+}
@@ -0,0 +1,187 @@
+package terminal
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// sampleZellijLayout is written in the format of `zellij action dump-layout`;
+// the Claude pane carries a user-set name, which isn't its live title
+const sampleZellijLayout = `layout {
+    cwd "/home/user"
+    tab name="Tab #1" hide_floating_panes=true {
+        pane size=1 borderless=true {
+            plugin location="zellij:tab-bar"
+        }
+        pane split_direction="vertical" {
+            pane command="nvim" cwd="code/app"
+            pane cwd="/tmp"
+        }
+        pane size=2 borderless=true {
+            plugin location="zellij:status-bar"
+        }
+    }
+    tab name="Fix login" focus=true {
+        pane command="zsh" cwd="code/api" name="claude" focus=true {
+            args "-i" "-c" "cd \"/home/user/code/api\" && claude --resume abc; exec zsh"
+        }
+    }
+    new_tab_template {
+        pane
+    }
+}
+`
+
+func TestParseZellijLayout(t *testing.T) {
+	tabs, err := parseZellijLayout(sampleZellijLayout)
+	if err != nil {
+		t.Fatalf("parseZellijLayout() error = %v", err)
+	}
+	if len(tabs) != 2 {
+		t.Fatalf("expected 2 tabs, got %d", len(tabs))
+	}
+
+	// First tab: plugin panes skipped, nested panes flattened
+	first := tabs[0]
+	if first.focused {
+		t.Error("first tab should not be focused")
+	}
+	if len(first.panes) != 2 {
+		t.Fatalf("expected 2 terminal panes in first tab, got %d", len(first.panes))
+	}
+	if tab, pane := splitZellijID(first.panes[0].ID); tab != 1 || pane != 1 || first.panes[0].Cwd != "/home/user/code/app" {
+		t.Errorf("unexpected first pane: %+v", first.panes[0])
+	}
+	if tab, pane := splitZellijID(first.panes[1].ID); tab != 1 || pane != 2 || first.panes[1].Cwd != "/tmp" {
+		t.Errorf("unexpected second pane: %+v", first.panes[1])
+	}
+
+	second := tabs[1]
+	if !second.focused {
+		t.Error("second tab should be focused")
+	}
+	w := second.panes[0]
+	// The pane's name isn't taken for the title Claude sets (it's not in the dump)
+	if tab, pane := splitZellijID(w.ID); tab != 2 || pane != 1 || w.Title != "" || w.TabTitle != "Fix login" || w.Cwd != "/home/user/code/api" {
+		t.Errorf("unexpected claude pane: %+v", w)
+	}
+	cmdline := strings.Join(w.Cmdline, " ")
+	if !strings.Contains(cmdline, `claude --resume abc`) {
+		t.Errorf("cmdline should contain resume command, got %q", cmdline)
+	}
+}
+
+func TestParseKDL(t *testing.T) {
+	nodes, err := parseKDL(`// comment
+node "arg one" key="value" 42 /* inline */ {
+    child; other "x\"y"
+}
+`)
+	if err != nil {
+		t.Fatalf("parseKDL() error = %v", err)
+	}
+	if len(nodes) != 1 {
+		t.Fatalf("expected 1 node, got %d", len(nodes))
+	}
+	n := nodes[0]
+	if n.Name != "node" || len(n.Args) != 2 || n.Args[0] != "arg one" || n.Args[1] != "42" {
+		t.Errorf("unexpected node: %+v", n)
+	}
+	if n.Props["key"] != "value" {
+		t.Errorf("expected key=value, got %q", n.Props["key"])
+	}
+	if len(n.Children) != 2 || n.Child("other").Args[0] != `x"y` {
+		t.Errorf("unexpected children: %+v", n.Children)
+	}
+
+	for _, bad := range []string{`node {`, `}`, `node "unterminated`} {
+		if _, err := parseKDL(bad); err == nil {
+			t.Errorf("parseKDL(%q) should fail", bad)
+		}
+	}
+}
+
+func TestWriteZellijLayout(t *testing.T) {
+	path, err := writeZellijLayout(LaunchSpec{
+		Command: `cd "/home/user/my project" && claude`,
+		WorkDir: "/home/user/my project",
+		Title:   "My \"quoted\" title",
+	})
+	if err != nil {
+		t.Fatalf("writeZellijLayout() error = %v", err)
+	}
+	defer os.Remove(path)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tabs, err := parseZellijLayout(strings.Replace(string(content), "layout {", "layout {\ntab {", 1) + "}")
+	if err != nil {
+		t.Fatalf("generated layout doesn't parse: %v\n%s", err, content)
+	}
+	w := tabs[0].panes[0]
+	if w.Cwd != "/home/user/my project" {
+		t.Errorf("unexpected pane: %+v", w)
+	}
+	nodes, _ := parseKDL(string(content))
+	if name := nodes[0].Child("pane").Props["name"]; name != `My "quoted" title` {
+		t.Errorf("pane name = %q", name)
+	}
+	if got := w.Cmdline[len(w.Cmdline)-1]; got != `cd "/home/user/my project" && claude; exec zsh` {
+		t.Errorf("unexpected command: %q", got)
+	}
+}
+
+func TestZellijWindowID(t *testing.T) {
+	cmdline := []string{"zsh", "-i", "-c", "claude --resume abc"}
+	id := zellijWindowID(2, 3, "/work/api", cmdline)
+	if tab, pane := splitZellijID(id); tab != 2 || pane != 3 {
+		t.Errorf("splitZellijID(%d) = (%d, %d), want (2, 3)", id, tab, pane)
+	}
+	if id <= 0 || id >= 1<<31 {
+		t.Errorf("window ID %d should fit in 31 bits", id)
+	}
+	if other := zellijWindowID(2, 3, "/work/web", cmdline); other == id {
+		t.Error("panes with different cwds at the same position should get different IDs")
+	}
+	if zellijWindowID(2, 1<<zellijPaneBits, "/work/api", cmdline) != 0 {
+		t.Error("a pane position beyond the limit should get no ID")
+	}
+	if a, b := zellijWindowID(1, 100, "", nil), zellijWindowID(2, 0, "", nil); a == b {
+		t.Error("pane 100 of tab 1 should not collide with tab 2")
+	}
+}
+
+func TestZellijTabOf(t *testing.T) {
+	tabs, err := parseZellijLayout(sampleZellijLayout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claude := tabs[1].panes[0].ID
+	if tab, err := zellijTabOf(tabs, claude); err != nil || tab != 2 {
+		t.Errorf("zellijTabOf() = %d, %v; want tab 2", tab, err)
+	}
+
+	// Once the first tab is closed, the claude tab moves into its slot: neither
+	// the closed pane's ID nor the claude pane's old ID points at anything
+	start := strings.Index(sampleZellijLayout, "    tab name=\"Tab #1\"")
+	end := strings.Index(sampleZellijLayout, "    tab name=\"Fix login\"")
+	after, err := parseZellijLayout(sampleZellijLayout[:start] + sampleZellijLayout[end:])
+	if err != nil || len(after) != 1 {
+		t.Fatalf("layout after close: %d tabs, %v", len(after), err)
+	}
+	if _, err := zellijTabOf(after, tabs[0].panes[0].ID); err == nil {
+		t.Error("the closed tab's pane should not be found")
+	}
+	if _, err := zellijTabOf(after, claude); err == nil {
+		t.Error("an ID from before the tab moved should not be found")
+	}
+	if tab, err := zellijTabOf(after, after[0].panes[0].ID); err != nil || tab != 1 {
+		t.Errorf("the moved tab's new ID should be found, got %d, %v", tab, err)
+	}
+	if _, err := zellijTabOf(tabs, 0); err == nil {
+		t.Error("ID 0 should not be found")
+	}
+}
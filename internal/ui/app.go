@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,6 +12,8 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/fsnotify/fsnotify"
+	"github.com/hadar/claude-deck/internal/control"
+	"github.com/hadar/claude-deck/internal/query"
 	"github.com/hadar/claude-deck/internal/session"
 	"github.com/hadar/claude-deck/internal/terminal"
 )
@@ -39,6 +42,8 @@ type App struct {
 	dialog  *DialogModel
 	keys    ListKeyMap
 
+	transcript *TranscriptModel // full-screen transcript viewer (V)
+
 	focus      Focus
 	width      int
 	height     int
@@ -59,6 +64,15 @@ type App struct {
 	showTheme bool // true when theme selection overlay is visible
 	themeCursor  int  // cursor position in theme selection
 
+	// Saved filter queries overlay (F)
+	showQueries bool
+	queryCursor int
+
+	// Usage report overlay (U)
+	showUsage  bool
+	usageMonth string // UsageMonthLayout, "" for all time
+	usageBy    string // "project" or "group"
+
 	// New session dialog
 	showNewSession        bool     // true when new session dialog is visible
 	newSessionPaths       []string // list of paths to show (favorites + recent)
@@ -72,10 +86,17 @@ type App struct {
 	// Pending new session - waiting to be matched by window ID or file watcher
 	pendingRenamePath    string
 	pendingRenameName    string
-	pendingRenameWindowID int // kitty window ID for matching
+	pendingRenameWindowID int // terminal window ID for matching
 
 	// Skip next status save to avoid race condition with rename
 	skipNextStatusSave bool
+
+	// Embedded terminal mode - keys go to this window while attached
+	attachedWindow int
+	zoomed         bool // attached window is shown full screen
+
+	// Local control socket for external tools (nil if another deck owns it)
+	control *control.Server
 }
 
 // NewApp creates a new application instance
@@ -89,6 +110,7 @@ func NewApp() (*App, error) {
 		preview:    NewPreviewModel(),
 		search:     NewSearchModel(),
 		dialog:     NewDialogModel(),
+		transcript: NewTranscriptModel(),
 		keys:       DefaultListKeyMap(),
 		focus:      FocusList,
 		horizontal: true,
@@ -106,6 +128,7 @@ func NewApp() (*App, error) {
 
 // Init initializes the application
 func (a *App) Init() tea.Cmd {
+	a.startControl()
 	// Set terminal title and load sessions
 	return tea.Batch(
 		tea.SetWindowTitle("Claude Deck"),
@@ -113,30 +136,103 @@ func (a *App) Init() tea.Cmd {
 	)
 }
 
+// shutdown releases watchers, embedded sessions and the control socket before quitting
+func (a *App) shutdown() {
+	a.quitting = true
+	if a.watcher != nil {
+		a.watcher.Close()
+	}
+	if eb := terminal.Embedded(); eb != nil {
+		eb.CloseAll()
+	}
+	if a.control != nil {
+		a.control.Close()
+	}
+	a.manager.Close()
+}
+
 // loadSessions loads session data in the background
+// The list fills in progressively as discovery finds sessions (sessionsProgressMsg).
+// Loading uses its own manager, copied into a.manager when it's done, so the
+// main loop never shares sessions with the loader.
 func (a *App) loadSessions() tea.Cmd {
-	return func() tea.Msg {
-		err := a.manager.Load()
+	updates := make(chan tea.Msg, 1)
+	go func() {
+		defer close(updates)
+		m := &session.Manager{}
+		err := m.LoadWithProgress(func(sessions []*session.Session) {
+			select {
+			case updates <- sessionsProgressMsg{sessions: sessions}:
+			default: // UI hasn't caught up with the last batch - skip this one
+			}
+		})
 		if err == nil {
+			// Select terminal backend from settings (auto-detect if unset)
+			terminal.UseBackend(m.GetTerminal())
+			// Pick up hook events written while the deck wasn't running
+			session.ProcessHookEvents()
 			// Use aggressive mode on startup to sync names even for path-only matches
-			if session.RefreshStatusesAggressive(a.manager.Sessions) {
-				a.manager.Save() // Persist tab title names and window IDs
+			if session.RefreshStatusesAggressive(m.Sessions) {
+				m.Save() // Persist tab title names and window IDs
 			}
-			// Refresh git branches (one git call per unique path)
-			session.RefreshGitBranches(a.manager.Sessions)
 		}
-		return sessionsLoadedMsg{err: err}
+		updates <- sessionsLoadedMsg{manager: m, err: err}
+	}()
+	return waitForLoad(updates)
+}
+
+// waitForLoad waits for the next loading update
+func waitForLoad(updates <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-updates
+		if !ok {
+			return nil
+		}
+		if progress, ok := msg.(sessionsProgressMsg); ok {
+			progress.next = updates
+			return progress
+		}
+		return msg
+	}
+}
+
+// streamGitBranches refreshes git branches on the worker pool (one git call per
+// unique path), applying each as it arrives
+func (a *App) streamGitBranches() tea.Cmd {
+	return waitForGitBranch(session.GitBranches(context.Background(), a.manager.Sessions))
+}
+
+func waitForGitBranch(branches <-chan session.GitBranch) tea.Cmd {
+	return func() tea.Msg {
+		b, ok := <-branches
+		if !ok {
+			return nil
+		}
+		return gitBranchMsg{branch: b, next: branches}
 	}
 }
 
 type statusRefreshedMsg struct {
 	updates         []session.StatusUpdate
-	activeWindowIDs map[int]bool // all active kitty window IDs
+	activeWindowIDs map[int]bool // all active terminal window IDs
 	noChanges       bool         // true if nothing changed - skip re-render
 }
 type clearStatusMsg struct{}
 type sessionsLoadedMsg struct {
-	err error
+	manager *session.Manager
+	err     error
+}
+
+// sessionsProgressMsg carries the sessions found so far while loading
+type sessionsProgressMsg struct {
+	sessions []*session.Session
+	next     <-chan tea.Msg
+}
+
+// gitBranchMsg carries one project path's current branch
+type gitBranchMsg struct {
+	branch session.GitBranch
+	next   <-chan session.GitBranch
 }
 
 // setStatus sets a status message and returns a command to clear it after 2 seconds
@@ -156,23 +252,40 @@ func (a *App) refreshStatusesAsync() tea.Cmd {
 	}
 }
 
+// processHookEvents drains the hook spool and recomputes statuses with the new
+// hook state, off the Update loop
+func (a *App) processHookEvents() tea.Cmd {
+	refresh := a.refreshStatusesAsync()
+	return func() tea.Msg {
+		session.ProcessHookEvents()
+		return refresh()
+	}
+}
+
 // watchFiles sets up file watching for session changes
 func (a *App) watchFiles() tea.Cmd {
 	return func() tea.Msg {
-		projectsDir := session.ClaudeProjectsDir()
-
-		// Watch the top-level projects directory for new project dirs
-		if err := a.watcher.Add(projectsDir); err != nil {
-			return nil
+		// Watch the hook spool directory for status events
+		hooksDir := session.HooksDir()
+		if os.MkdirAll(hooksDir, 0755) == nil {
+			a.watcher.Add(hooksDir)
 		}
 
-		// Watch all existing project subdirectories for new/changed JSONL files
-		entries, err := os.ReadDir(projectsDir)
-		if err == nil {
-			for _, entry := range entries {
-				if entry.IsDir() {
-					subdir := filepath.Join(projectsDir, entry.Name())
-					a.watcher.Add(subdir)
+		for _, projectsDir := range session.ProjectsDirs() {
+			// Watch the top-level projects directory for new project dirs
+			// (a data root without one yet is skipped)
+			if err := a.watcher.Add(projectsDir); err != nil {
+				continue
+			}
+
+			// Watch all existing project subdirectories for new/changed JSONL files
+			entries, err := os.ReadDir(projectsDir)
+			if err == nil {
+				for _, entry := range entries {
+					if entry.IsDir() {
+						subdir := filepath.Join(projectsDir, entry.Name())
+						a.watcher.Add(subdir)
+					}
 				}
 			}
 		}
@@ -183,6 +296,11 @@ func (a *App) watchFiles() tea.Cmd {
 				if !ok {
 					return nil
 				}
+				// Hook event record moved into the spool = status change
+				if event.Op&fsnotify.Create == fsnotify.Create && filepath.Dir(event.Name) == hooksDir &&
+					strings.HasSuffix(event.Name, ".json") && !strings.HasPrefix(filepath.Base(event.Name), ".") {
+					return hookEventMsg{}
+				}
 				// New JSONL file created = new session
 				if event.Op&fsnotify.Create == fsnotify.Create && strings.HasSuffix(event.Name, ".jsonl") {
 					return newSessionFileMsg{path: event.Name}
@@ -215,28 +333,35 @@ type newSessionFileMsg struct {
 	path string
 }
 
+// hookEventMsg is sent when a Claude Code hook writes an event record
+type hookEventMsg struct{}
+
 // Update handles messages
 func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	// Handle global keys first
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		// While attached to an embedded session, keys (including Ctrl+C) go to Claude
+		if a.attachedWindow > 0 {
+			return a.updateAttached(msg)
+		}
+
 		// Quit on Ctrl+C or q (when not in dialog/search)
 		if msg.String() == "ctrl+c" {
-			a.quitting = true
-			if a.watcher != nil {
-				a.watcher.Close()
-			}
+			a.shutdown()
 			return a, tea.Quit
 		}
 
-		if msg.String() == "Q" && !a.dialog.IsOpen() && !a.search.IsActive() {
-			a.quitting = true
-			if a.watcher != nil {
-				a.watcher.Close()
-			}
+		if msg.String() == "Q" && !a.dialog.IsOpen() && !a.search.IsActive() && !a.transcript.IsSearching() {
+			a.shutdown()
 			return a, tea.Quit
 		}
 
+		// The list is only a preview until loading completes
+		if a.loading {
+			return a, nil
+		}
+
 	case tea.WindowSizeMsg:
 		a.width = msg.Width
 		a.height = msg.Height
@@ -248,6 +373,12 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return a, a.refreshStatusesAsync()
 
 	case tea.MouseMsg:
+		if a.loading {
+			return a, nil
+		}
+		if a.transcript.IsOpen() {
+			return a.updateTranscript(msg)
+		}
 		return a.handleMouse(msg)
 
 	case statusRefreshedMsg:
@@ -259,12 +390,13 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Apply updates to session objects (modifies Status field in place)
 		// No need to update pointers - sessions haven't been reloaded
 		changed, needsSave := session.ApplyStatusUpdates(a.manager.Sessions, msg.updates)
+		a.broadcastStatusChanges(msg.updates)
 
 		// Remove pending sessions whose windows no longer exist
 		if msg.activeWindowIDs != nil {
 			var remainingSessions []*session.Session
 			for _, s := range a.manager.Sessions {
-				isPending := strings.HasPrefix(s.ClaudeSessionID, "pending-")
+				isPending := strings.HasPrefix(s.ClaudeSessionID, session.PendingSessionPrefix)
 				windowGone := s.KittyWindowID > 0 && !msg.activeWindowIDs[s.KittyWindowID]
 				if isPending && windowGone {
 					// Pending session's window was closed - remove it
@@ -336,12 +468,25 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.statusMsg = ""
 		return a, nil
 
+	case sessionsProgressMsg:
+		if !a.loading {
+			return a, nil
+		}
+		a.manager.Sessions = msg.sessions
+		a.list.Refresh()
+		return a, waitForLoad(msg.next)
+
+	case gitBranchMsg:
+		session.ApplyGitBranch(a.manager.Sessions, msg.branch)
+		return a, waitForGitBranch(msg.next)
+
 	case sessionsLoadedMsg:
 		a.loading = false
 		if msg.err != nil {
 			a.err = msg.err
 			return a, nil
 		}
+		*a.manager = *msg.manager
 		a.list.ReloadExpansionState()
 		a.list.Refresh()
 		// Apply theme from settings
@@ -350,6 +495,11 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		// Start other background tasks
 		var cmds []tea.Cmd
+		if cmd := a.preview.SetShowTools(a.manager.GetPreviewTools()); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+		a.preview.SetPrices(a.manager.GetPrices())
+		a.list.SetShowUsage(a.manager.GetListUsage(), a.manager.GetPrices())
 		if item := a.list.SelectedItem(); item != nil && !item.IsGroup() {
 			if cmd := a.preview.SetSession(item.Session); cmd != nil {
 				cmds = append(cmds, cmd)
@@ -358,10 +508,18 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if a.watcher != nil {
 			cmds = append(cmds, a.watchFiles())
 		}
+		if cmd := a.watchEmbedded(); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+		// Serve control requests only once sessions are loaded
+		if cmd := a.watchControl(); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
 		// Restore previously active sessions if enabled
 		if cmd := a.restoreSessions(); cmd != nil {
 			cmds = append(cmds, cmd)
 		}
+		cmds = append(cmds, a.streamGitBranches())
 		return a, tea.Batch(cmds...)
 
 
@@ -381,11 +539,31 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				cmds = append(cmds, a.preview.Refresh())
 			}
 		}
+		cmds = append(cmds, a.transcript.Refresh(msg.path))
+
+		// Keep content search current without re-reading the whole file
+		path := msg.path
+		cmds = append(cmds, func() tea.Msg {
+			session.UpdateSearchIndex(path)
+			return nil
+		})
 
 		// Trigger async status refresh
 		cmds = append(cmds, a.refreshStatusesAsync())
 		return a, tea.Batch(cmds...)
 
+	case controlRequestMsg:
+		cmds := []tea.Cmd{a.handleControl(msg.req), a.watchControl()}
+		return a, tea.Batch(cmds...)
+
+	case hookEventMsg:
+		var cmds []tea.Cmd
+		if a.watcher != nil {
+			cmds = append(cmds, a.watchFiles())
+		}
+		cmds = append(cmds, a.processHookEvents())
+		return a, tea.Batch(cmds...)
+
 	case newSessionFileMsg:
 		// Build commands - always restart watcher
 		var cmds []tea.Cmd
@@ -398,7 +576,7 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Check if we have a pending new session waiting for this file
 		if a.pendingRenameWindowID > 0 {
 			// Remove the pending session before reloading
-			pendingID := fmt.Sprintf("pending-%d", a.pendingRenameWindowID)
+			pendingID := fmt.Sprintf("%s%d", session.PendingSessionPrefix, a.pendingRenameWindowID)
 			a.manager.RemovePendingSession(pendingID)
 		}
 
@@ -407,9 +585,7 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// Match pending session to discovered one
 		if a.pendingRenamePath != "" {
-			newProjectPath := session.ProjectPathFromJSONL(msg.path)
-
-			if newProjectPath == a.pendingRenamePath {
+			if session.JSONLMatchesPath(msg.path, a.pendingRenamePath) {
 				newest := session.FindNewestSessionAtPath(a.pendingRenamePath)
 				if newest != "" {
 					a.skipNextStatusSave = true
@@ -442,15 +618,34 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, a.setStatus(statusMsg))
 		return a, tea.Batch(cmds...)
 
+	case TranscriptPageMsg:
+		return a, a.transcript.HandlePage(msg)
+
 	case PreviewLoadedMsg:
 		a.preview.HandleLoaded(msg)
 		return a, nil
 
+	case embeddedOutputMsg:
+		// Screen changed - returning re-renders the view; detach if the process exited
+		if eb := terminal.Embedded(); a.attachedWindow > 0 && (eb == nil || !eb.Alive(a.attachedWindow)) {
+			a.detach()
+		}
+		return a, a.watchEmbedded()
+
+	case embeddedStateMsg:
+		// Process exited or Claude's title changed - statuses come straight from the PTY
+		return a, tea.Batch(a.watchEmbedded(), a.refreshStatusesAsync())
+
 	case ContentSearchResultsMsg:
 		a.list.HandleContentSearchResults(msg)
 		return a, a.updateSelectedPreview()
 	}
 
+	// Handle transcript viewer
+	if a.transcript.IsOpen() {
+		return a.updateTranscript(msg)
+	}
+
 	// Handle help overlay
 	if a.showHelp {
 		if msg, ok := msg.(tea.KeyMsg); ok {
@@ -490,6 +685,16 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return a, nil
 	}
 
+	// Handle saved queries overlay
+	if a.showQueries {
+		return a.updateSavedQueries(msg)
+	}
+
+	// Handle usage report overlay
+	if a.showUsage {
+		return a.updateUsage(msg)
+	}
+
 	// Handle new session dialog
 	if a.showNewSession {
 		return a.updateNewSessionDialog(msg)
@@ -534,6 +739,15 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return a.updateNormal(msg)
 }
 
+// updateTranscript handles keys and the mouse wheel in the transcript viewer
+func (a *App) updateTranscript(msg tea.Msg) (tea.Model, tea.Cmd) {
+	cmd, closed := a.transcript.Update(msg)
+	if closed {
+		a.transcript.Close()
+	}
+	return a, cmd
+}
+
 // handleMouse processes mouse events
 func (a *App) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 	// Y offset: header (1) + border (1) + table header (1) = 3
@@ -612,14 +826,7 @@ func (a *App) handleDialogConfirm() (tea.Model, tea.Cmd) {
 	switch a.dialog.Type() {
 	case DialogRename:
 		if name := a.dialog.Value(); name != "" {
-			a.manager.RenameSession(a.dialog.TargetID(), name)
-			// Update kitty tab title if session has active window
-			if s := a.manager.FindSession(a.dialog.TargetID()); s != nil {
-				if windowID := session.GetActiveWindowID(s); windowID > 0 {
-					terminal.SetKittyTabTitle(windowID, name)
-				}
-			}
-			a.list.Refresh()
+			a.renameSession(a.dialog.TargetID(), name)
 			a.dialog.Close()
 			return a, a.setStatus("Session renamed")
 		}
@@ -676,10 +883,20 @@ func (a *App) updateSearch(msg tea.Msg) (tea.Model, tea.Cmd) {
 		switch msg.String() {
 		case "enter":
 			a.list.ConfirmSearch()
-			return a.handleOpen()
+			return a.handleOpen(false)
 		case "esc":
 			a.list.CancelSearch()
 			return a, nil
+		case "ctrl+s":
+			q := strings.TrimSpace(a.list.Filter())
+			if q == "" {
+				return a, nil
+			}
+			if _, err := query.Parse(q); err != nil {
+				return a, a.setStatus("Can't save filter: " + err.Error())
+			}
+			a.manager.SaveQuery(q)
+			return a, a.setStatus("Saved filter " + q)
 		case "up", "down", "ctrl+p", "ctrl+n":
 			a.list.HandleSearchKey(msg.String())
 			return a, a.updateSelectedPreview()
@@ -702,7 +919,7 @@ func (a *App) updateContentSearch(msg tea.Msg) (tea.Model, tea.Cmd) {
 			a.list.ConfirmContentSearch()
 			// Open the captured item
 			if item != nil && !item.IsGroup() {
-				windowID, err := terminal.OpenSession(item.Session.ProjectPath, item.Session.ClaudeSessionID, session.GetActiveWindowID(item.Session), item.Session.Name)
+				windowID, err := terminal.OpenSession(item.Session.ProjectPath, item.Session.ClaudeSessionID, item.Session.ConfigDir(), session.GetActiveWindowID(item.Session), item.Session.Name)
 				if err != nil {
 					return a, a.setStatus("Error: " + err.Error())
 				}
@@ -722,6 +939,20 @@ func (a *App) updateContentSearch(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "esc":
 			a.list.CancelContentSearch()
 			return a, nil
+		case "tab":
+			a.preview.NextHit(1)
+			return a, nil
+		case "shift+tab":
+			a.preview.NextHit(-1)
+			return a, nil
+		case "ctrl+o":
+			// Show the hit in context; the search is still there when the viewer closes
+			item := a.list.SelectedItem()
+			hit := a.preview.CurrentHit()
+			if item == nil || item.IsGroup() || hit == nil {
+				return a, nil
+			}
+			return a, a.transcript.OpenAt(item.Session, a.preview.ShowTools(), hit.Offset, hit.Match)
 		case "up", "down", "ctrl+p", "ctrl+n":
 			a.list.HandleContentSearchKey(msg.String())
 			return a, a.updateSelectedPreview()
@@ -749,18 +980,7 @@ func (a *App) updateRename(msg tea.Msg) (tea.Model, tea.Cmd) {
 						a.manager.RenameGroup(id, newName)
 					}
 				} else {
-					a.manager.RenameSession(id, newName) // empty name resets to dynamic
-					// Update kitty tab title if session has active window
-					if s := a.manager.FindSession(id); s != nil {
-						if windowID := session.GetActiveWindowID(s); windowID > 0 {
-							if newName != "" {
-								terminal.SetKittyTabTitle(windowID, newName)
-							} else {
-								// Reset to dynamic window title
-								terminal.ResetKittyTabTitle(windowID)
-							}
-						}
-					}
+					a.renameSession(id, newName)
 				}
 				a.list.Refresh()
 				if newName == "" {
@@ -780,6 +1000,19 @@ func (a *App) updateRename(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return a, nil
 }
 
+// renameSession renames a session and its terminal tab, if it has one open
+// An empty name resets both to the dynamic title.
+func (a *App) renameSession(id, name string) error {
+	if err := a.manager.RenameSession(id, name); err != nil {
+		return err
+	}
+	if s := a.manager.FindSession(id); s != nil {
+		session.RetitleTab(s, name)
+	}
+	a.list.Refresh()
+	return nil
+}
+
 // updateMoving handles moving mode - navigate with arrows, enter to confirm
 func (a *App) updateMoving(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
@@ -896,11 +1129,54 @@ func (a *App) updateNormal(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 
 		case key.Matches(msg, a.keys.Enter):
-			return a.handleOpen()
+			return a.handleOpen(false)
+
+		case key.Matches(msg, a.keys.Zoom):
+			// Zoom only applies to sessions running embedded in the deck
+			if terminal.Embedded() != nil {
+				return a.handleOpen(true)
+			}
+
+		case key.Matches(msg, a.keys.Transcript):
+			if item := a.list.SelectedItem(); item != nil && !item.IsGroup() && item.Session.JSONLPath != "" {
+				return a, a.transcript.Open(item.Session, a.preview.ShowTools())
+			}
+
+		case key.Matches(msg, a.keys.Tools):
+			show := !a.preview.ShowTools()
+			a.manager.SetPreviewTools(show)
+			cmd := a.preview.SetShowTools(show)
+			if show {
+				return a, tea.Batch(cmd, a.setStatus("Showing tool calls in preview"))
+			}
+			return a, tea.Batch(cmd, a.setStatus("Hiding tool calls in preview"))
 
 		case key.Matches(msg, a.keys.Search):
 			a.list.StartSearch()
 
+		case key.Matches(msg, a.keys.SavedQueries):
+			if len(a.manager.GetSavedQueries()) == 0 {
+				return a, a.setStatus("No saved filters - press Ctrl+S while filtering with / to save one")
+			}
+			a.showQueries = true
+			a.queryCursor = 0
+			return a, nil
+
+		case key.Matches(msg, a.keys.Usage):
+			a.showUsage = true
+			a.usageMonth = time.Now().Format(session.UsageMonthLayout)
+			a.usageBy = "project"
+			return a, nil
+
+		case key.Matches(msg, a.keys.UsageColumn):
+			show := !a.list.ShowUsage()
+			a.manager.SetListUsage(show)
+			a.list.SetShowUsage(show, a.manager.GetPrices())
+			if show {
+				return a, a.setStatus("Showing estimated cost in the list")
+			}
+			return a, a.setStatus("Hiding estimated cost in the list")
+
 		case key.Matches(msg, a.keys.ContentSearch):
 			a.list.StartContentSearch()
 
@@ -927,10 +1203,11 @@ func (a *App) updateNormal(msg tea.Msg) (tea.Model, tea.Cmd) {
 				// Find window ID using all matching strategies (stored ID, --resume flag, project path)
 				windowID := session.FindWindowIDForSession(item.Session)
 				if windowID > 0 {
-					terminal.CloseKittyWindow(windowID)
+					terminal.CloseWindow(windowID)
 				}
 				item.Session.KittyWindowID = 0
 				item.Session.Status = session.StatusIdle
+				session.ForgetHookState(item.Session.ClaudeSessionID)
 				// Update last_active_sessions so killed session won't be resumed
 				a.trackActiveSessions()
 				a.manager.Save()
@@ -986,6 +1263,19 @@ func (a *App) updateNormal(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return a, a.setStatus("Resume on startup: disabled")
 
+		case key.Matches(msg, a.keys.Hooks):
+			// Toggle Claude Code hooks for push-based status
+			if session.HooksInstalled() {
+				if err := session.UninstallHooks(); err != nil {
+					return a, a.setStatus("Failed to remove hooks: " + err.Error())
+				}
+				return a, a.setStatus("Claude hooks removed")
+			}
+			if err := session.InstallHooks(); err != nil {
+				return a, a.setStatus("Failed to install hooks: " + err.Error())
+			}
+			return a, a.setStatus("Claude hooks installed (applies to new Claude sessions)")
+
 		case key.Matches(msg, a.keys.NewSession):
 			// Open new session dialog
 			a.showNewSession = true
@@ -1004,7 +1294,8 @@ func (a *App) updateNormal(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return a, nil
 
 		case msg.String() == "ctrl+r":
-			// Manual refresh
+			// Manual refresh - query the terminal again instead of using the cached snapshot
+			terminal.InvalidateWindows()
 			a.manager.Load()
 			if session.RefreshStatuses(a.manager.Sessions) {
 				a.manager.Save()
@@ -1029,7 +1320,8 @@ func (a *App) updateNormal(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 // handleOpen opens the selected session
-func (a *App) handleOpen() (tea.Model, tea.Cmd) {
+// zoomed attaches full screen when sessions run embedded in the deck
+func (a *App) handleOpen(zoomed bool) (tea.Model, tea.Cmd) {
 	item := a.list.SelectedItem()
 	if item == nil {
 		return a, nil
@@ -1041,21 +1333,29 @@ func (a *App) handleOpen() (tea.Model, tea.Cmd) {
 		return a, nil
 	}
 
-	// Open session in new terminal tab
-	windowID, err := terminal.OpenSession(item.Session.ProjectPath, item.Session.ClaudeSessionID, session.GetActiveWindowID(item.Session), item.Session.Name)
+	return a.openSession(item.Session, zoomed)
+}
+
+// openSession opens a session in a new terminal tab (or focuses its tab)
+func (a *App) openSession(s *session.Session, zoomed bool) (tea.Model, tea.Cmd) {
+	windowID, err := terminal.OpenSession(s.ProjectPath, s.ClaudeSessionID, s.ConfigDir(), session.GetActiveWindowID(s), s.Name)
 	if err != nil {
 		return a, a.setStatus("Error: " + err.Error())
 	}
 	// Store window ID for reliable tab matching (and clear from other sessions)
 	if windowID > 0 {
-		session.ClaimWindowID(a.manager.Sessions, item.Session, windowID)
+		session.ClaimWindowID(a.manager.Sessions, s, windowID)
 		a.manager.Save()
 	}
 	// Immediately mark session as active (will be refined by next status tick)
-	if item.Session.Status == session.StatusIdle {
-		item.Session.Status = session.StatusWaiting
+	if s.Status == session.StatusIdle {
+		s.Status = session.StatusWaiting
 		a.list.Refresh() // Move to Active group
 	}
+	// Embedded sessions run inside the deck - attach to it in the preview pane
+	if terminal.Embedded() != nil && windowID > 0 {
+		return a, a.attach(windowID, zoomed)
+	}
 	return a, a.setStatus("Opened in new tab")
 }
 
@@ -1063,11 +1363,12 @@ func (a *App) handleOpen() (tea.Model, tea.Cmd) {
 func (a *App) updateSelectedPreview() tea.Cmd {
 	item := a.list.SelectedItem()
 	if item == nil || item.IsGroup() {
-		a.preview.SetSearchSnippet("")
+		a.preview.SetSearchHits(nil)
 		return a.preview.SetSession(nil)
 	}
-	// Pass search snippet if in content search mode
-	a.preview.SetSearchSnippet(a.list.GetSearchSnippet())
+	// Pass search hits if in content search mode
+	a.preview.SetSearchHits(a.list.GetSearchHits())
+	a.resizeEmbedded()
 	return a.preview.SetSession(item.Session)
 }
 
@@ -1097,6 +1398,8 @@ func (a *App) updateLayout() {
 		a.list.SetSize(a.listWidth, listH-2)
 		a.preview.SetSize(a.previewWidth, previewH-2)
 	}
+	a.transcript.SetSize(a.width, a.height)
+	a.resizeEmbedded()
 }
 
 // View renders the application with fixed layout
@@ -1105,7 +1408,7 @@ func (a *App) View() string {
 		return ""
 	}
 
-	if a.loading {
+	if a.loading && len(a.manager.Sessions) == 0 {
 		// Show loading screen with spinner, centered
 		loadingText := titleStyle.Render("Claude Deck") + "\n\n⏳ Loading sessions..."
 		if a.width > 0 && a.height > 0 {
@@ -1122,14 +1425,38 @@ func (a *App) View() string {
 		return titleStyle.Render("Claude Deck") + "\n\n  Error: " + a.err.Error()
 	}
 
+	// Transcript viewer takes over the whole screen
+	if a.transcript.IsOpen() {
+		return a.transcript.View()
+	}
+
+	// Zoomed embedded session takes over the whole screen
+	if a.zoomed {
+		if view, ok := a.renderZoomed(); ok {
+			return view
+		}
+	}
+
 	// Header with session count
 	sessionCount := len(a.manager.Sessions)
-	header := titleStyle.Render("Claude Deck") + "  " + helpStyle.Render(fmt.Sprintf("(%d sessions)", sessionCount))
+	countText := fmt.Sprintf("(%d sessions)", sessionCount)
+	if a.loading {
+		countText = fmt.Sprintf("(%d sessions, loading...)", sessionCount)
+	}
+	header := titleStyle.Render("Claude Deck") + "  " + helpStyle.Render(countText)
 	headerPadded := header + strings.Repeat(" ", max(0, a.width-lipgloss.Width(header)))
 
 	// Render panel contents
 	listContent := a.list.View()
 	previewContent := a.preview.View()
+	if item := a.list.SelectedItem(); item != nil && !item.IsGroup() {
+		// Sessions running embedded in the deck show their live terminal instead
+		if windowID := embeddedWindowID(item.Session); windowID > 0 {
+			if screen, ok := renderEmbedded(windowID, a.preview.width, a.preview.height, windowID == a.attachedWindow); ok {
+				previewContent = screen
+			}
+		}
+	}
 
 	var mainContent string
 	contentHeight := a.height - 4
@@ -1173,6 +1500,11 @@ func (a *App) View() string {
 
 	// Status bar - help on left, messages on right
 	helpText := "Enter:open  N:new  /,?:search  R:rename  K:kill  P:pin  H:help  Q:quit"
+	if a.attachedWindow > 0 {
+		helpText = "Attached - keys go to Claude  ctrl+]:detach  ctrl+\\:zoom"
+	} else if a.list.IsContentSearching() {
+		helpText = "Enter:open  Tab/⇧Tab:next/prev hit  Ctrl+O:show in transcript  Esc:cancel"
+	}
 	var statusLine string
 	if a.statusMsg != "" {
 		gap := a.width - len(helpText) - len(a.statusMsg) - 4
@@ -1207,6 +1539,12 @@ func (a *App) View() string {
 	if a.showHelp {
 		return a.renderHelp()
 	}
+	if a.showQueries {
+		return a.renderSavedQueries()
+	}
+	if a.showUsage {
+		return a.renderUsage()
+	}
 	if a.showTheme {
 		return a.renderThemeSelect()
 	}
@@ -1276,7 +1614,7 @@ func (a *App) restoreSessions() tea.Cmd {
 		// Capture session for closure
 		sess := s
 		cmds = append(cmds, func() tea.Msg {
-			terminal.OpenSession(sess.ProjectPath, sess.ClaudeSessionID, 0, sess.Name)
+			terminal.OpenSession(sess.ProjectPath, sess.ClaudeSessionID, sess.ConfigDir(), 0, sess.Name)
 			return nil
 		})
 	}
@@ -1307,17 +1645,27 @@ func (a *App) renderHelp() string {
 │    D        Delete group              │
 │    M        Move session to group     │
 │    P        Pin/unpin session         │
+│    Z        Zoom embedded session     │
+│    V        View full transcript      │
 │                                       │
 │  Search                               │
-│    /        Search by name            │
+│    /        Fuzzy search / query      │
 │    ?        Search in content         │
+│      Tab    Next hit in session       │
+│      Ctrl+O Show hit in transcript    │
+│    F        Recall a saved filter     │
+│    Ctrl+S   Save filter (while in /)  │
 │                                       │
 │  Settings                             │
 │    L        Toggle layout (|| / =)    │
 │    C        Select color theme        │
 │    S        Toggle resume on startup  │
+│    I        Toggle Claude Code hooks  │
+│    t        Toggle preview tool calls │
+│    $        Toggle cost column        │
 │                                       │
 │  Other                                │
+│    U        Token usage and cost      │
 │    Ctrl+R   Refresh status/names      │
 │    H        Show this help            │
 │    Q        Quit                      │
@@ -1379,6 +1727,185 @@ func (a *App) renderThemeSelect() string {
 	return lipgloss.Place(a.width, a.height, lipgloss.Center, lipgloss.Center, box)
 }
 
+// updateSavedQueries handles keys in the saved queries overlay
+func (a *App) updateSavedQueries(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return a, nil
+	}
+	queries := a.manager.GetSavedQueries()
+	switch keyMsg.String() {
+	case "esc", "F":
+		a.showQueries = false
+	case "up", "k":
+		if a.queryCursor > 0 {
+			a.queryCursor--
+		}
+	case "down", "j":
+		if a.queryCursor < len(queries)-1 {
+			a.queryCursor++
+		}
+	case "enter":
+		// Recall into the search line so it can be tweaked or cleared with Esc
+		a.showQueries = false
+		a.list.StartSearch()
+		a.list.ApplyQuery(queries[a.queryCursor])
+		return a, a.updateSelectedPreview()
+	case "d", "delete", "backspace":
+		a.manager.DeleteSavedQuery(queries[a.queryCursor])
+		if len(a.manager.GetSavedQueries()) == 0 {
+			a.showQueries = false
+		}
+		a.queryCursor = min(a.queryCursor, max(0, len(a.manager.GetSavedQueries())-1))
+	}
+	return a, nil
+}
+
+// renderSavedQueries renders the saved filter queries overlay
+func (a *App) renderSavedQueries() string {
+	innerWidth := min(max(40, a.width/2), a.width-4)
+	border := strings.Repeat("─", innerWidth)
+	pad := func(s string) string {
+		if w := lipgloss.Width(s); w < innerWidth {
+			return s + strings.Repeat(" ", innerWidth-w)
+		}
+		return s
+	}
+
+	var lines []string
+	lines = append(lines, "╭"+border+"╮")
+	lines = append(lines, "│"+pad(lipgloss.PlaceHorizontal(innerWidth, lipgloss.Center, "Saved Filters"))+"│")
+	lines = append(lines, "├"+border+"┤")
+	for i, q := range a.manager.GetSavedQueries() {
+		content := "  " + q
+		if i == a.queryCursor {
+			content = "> " + q
+		}
+		content = pad(truncateString(content, innerWidth))
+		if i == a.queryCursor {
+			content = selectedItemStyle.Render(content)
+		}
+		lines = append(lines, "│"+content+"│")
+	}
+	lines = append(lines, "├"+border+"┤")
+	lines = append(lines, "│"+pad(" ↑↓:navigate Enter:apply d:delete Esc:close")+"│")
+	lines = append(lines, "╰"+border+"╯")
+
+	box := strings.Join(lines, "\n")
+	return lipgloss.Place(a.width, a.height, lipgloss.Center, lipgloss.Center, box)
+}
+
+// updateUsage handles keys in the usage report overlay
+func (a *App) updateUsage(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return a, nil
+	}
+	switch keyMsg.String() {
+	case "esc", "U":
+		a.showUsage = false
+	case "tab":
+		if a.usageBy == "project" {
+			a.usageBy = "group"
+		} else {
+			a.usageBy = "project"
+		}
+	case "left", "right":
+		// Step a month; from all time, start at the current month
+		month, err := time.ParseInLocation(session.UsageMonthLayout, a.usageMonth, time.Local)
+		if err != nil {
+			a.usageMonth = time.Now().Format(session.UsageMonthLayout)
+			break
+		}
+		step := 1
+		if keyMsg.String() == "left" {
+			step = -1
+		}
+		a.usageMonth = month.AddDate(0, step, 0).Format(session.UsageMonthLayout)
+	case "a":
+		a.usageMonth = ""
+	}
+	return a, nil
+}
+
+// renderUsage renders the usage report overlay: tokens and estimated cost
+// by project or group for a month
+func (a *App) renderUsage() string {
+	innerWidth := min(max(60, a.width*2/3), a.width-4)
+	border := strings.Repeat("─", innerWidth)
+	pad := func(s string) string {
+		if w := lipgloss.Width(s); w < innerWidth {
+			return s + strings.Repeat(" ", innerWidth-w)
+		}
+		return s
+	}
+
+	key := func(s *session.Session) string { return s.ProjectPath }
+	if a.usageBy == "group" {
+		key = func(s *session.Session) string {
+			if s.GroupPath == "" {
+				return "(no group)"
+			}
+			return s.GroupPath
+		}
+	}
+	totals := session.RollupUsage(a.manager.Sessions, a.usageMonth, a.manager.GetPrices(), key)
+
+	period := "All time"
+	if month, err := time.Parse(session.UsageMonthLayout, a.usageMonth); err == nil {
+		period = month.Format("January 2006")
+	}
+	title := fmt.Sprintf("Usage by %s · %s", a.usageBy, period)
+
+	// Columns: key, sessions, input, output, cached, cost
+	const numW = 9
+	keyW := innerWidth - 2 - 5*(numW+1)
+	row := func(k, sessions, input, output, cached, cost string) string {
+		return " " + padStr(truncate(k, keyW), keyW) +
+			fmt.Sprintf(" %*s %*s %*s %*s %*s", numW, sessions, numW, input, numW, output, numW, cached, numW, cost)
+	}
+	usageRow := func(t session.UsageTotal) string {
+		return row(t.Key, fmt.Sprint(t.Sessions), session.FormatTokens(t.Tokens.Input),
+			session.FormatTokens(t.Tokens.Output), session.FormatTokens(t.Tokens.CacheWrite+t.Tokens.CacheRead),
+			formatCost(t.Cost, t.Priced))
+	}
+
+	var lines []string
+	lines = append(lines, "╭"+border+"╮")
+	lines = append(lines, "│"+pad(lipgloss.PlaceHorizontal(innerWidth, lipgloss.Center, title))+"│")
+	lines = append(lines, "├"+border+"┤")
+	lines = append(lines, "│"+pad(helpStyle.Render(row(strings.ToUpper(a.usageBy[:1])+a.usageBy[1:], "Sessions", "Input", "Output", "Cached", "Cost")))+"│")
+
+	// Keep the box on screen: borders, title, header, total, notes and help take 10 lines
+	maxRows := max(1, a.height-10)
+	sum := session.UsageTotal{Key: "Total", Priced: true}
+	for i, t := range totals {
+		if i < maxRows {
+			lines = append(lines, "│"+pad(usageRow(t))+"│")
+		} else if i == maxRows {
+			lines = append(lines, "│"+pad(helpStyle.Render(fmt.Sprintf(" … %d more", len(totals)-maxRows)))+"│")
+		}
+		sum.Sessions += t.Sessions
+		sum.Tokens.Add(t.Tokens)
+		sum.Cost += t.Cost
+		sum.Priced = sum.Priced && t.Priced
+	}
+	if len(totals) == 0 {
+		lines = append(lines, "│"+pad(helpStyle.Render(" No usage recorded"))+"│")
+	} else {
+		lines = append(lines, "│"+pad(previewTitleStyle.Render(usageRow(sum)))+"│")
+	}
+	if !sum.Priced {
+		lines = append(lines, "│"+pad(helpStyle.Render(` * some models have no price ("prices" in settings)`))+"│")
+	}
+	lines = append(lines, "├"+border+"┤")
+	lines = append(lines, "│"+pad(" ←→:month a:all time Tab:project/group Esc:close")+"│")
+	lines = append(lines, "╰"+border+"╯")
+
+	box := strings.Join(lines, "\n")
+	return lipgloss.Place(a.width, a.height, lipgloss.Center, lipgloss.Center, box)
+}
+
 // buildNewSessionPaths builds the list of paths for the new session dialog
 func (a *App) buildNewSessionPaths() {
 	a.newSessionPaths = nil
@@ -1457,24 +1984,7 @@ func (a *App) updateNewSessionDialog(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Create a pending session immediately (before JSONL exists)
 		// This uses a temporary ID that will be updated when JSONL is created
 		if windowID > 0 {
-			pendingID := fmt.Sprintf("pending-%d", windowID)
-			now := time.Now()
-			pendingSession := &session.Session{
-				ID:              pendingID,
-				ClaudeSessionID: pendingID,
-				Name:            name,
-				ProjectPath:     path,
-				KittyWindowID:   windowID,
-				Status:          session.StatusWaiting,
-				Renamed:         name != "",
-				CreatedAt:       now,
-				LastAccessedAt:  now,
-			}
-			if name == "" {
-				pendingSession.Name = filepath.Base(path) + " (new)"
-			}
-			a.manager.Sessions = append(a.manager.Sessions, pendingSession)
-			a.manager.Save()
+			a.manager.AddPendingSession(path, name, windowID)
 		}
 
 		// Store pending state for when JSONL is created
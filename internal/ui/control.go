@@ -0,0 +1,129 @@
+package ui
+
+import (
+	"errors"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hadar/claude-deck/internal/control"
+	"github.com/hadar/claude-deck/internal/session"
+	"github.com/hadar/claude-deck/internal/terminal"
+)
+
+// controlRequestMsg carries a request from the control socket to the main loop
+type controlRequestMsg struct {
+	req *control.Request
+}
+
+// startControl opens the control socket (a second deck just runs without one)
+func (a *App) startControl() {
+	server, err := control.Listen(control.SocketPath())
+	if err != nil {
+		return
+	}
+	a.control = server
+}
+
+// watchControl waits for the next control request
+// Like watchFiles, it returns after each request and must be restarted
+func (a *App) watchControl() tea.Cmd {
+	if a.control == nil {
+		return nil
+	}
+	requests := a.control.Requests()
+	return func() tea.Msg {
+		return controlRequestMsg{req: <-requests}
+	}
+}
+
+// broadcastStatusChanges notifies control subscribers of status changes
+func (a *App) broadcastStatusChanges(updates []session.StatusUpdate) {
+	if a.control == nil {
+		return
+	}
+	for _, u := range updates {
+		if u.Status == u.OldStatus {
+			continue
+		}
+		name := u.OldName
+		if s := a.manager.FindSession(u.SessionID); s != nil {
+			name = s.Name
+		}
+		a.control.Broadcast(control.NotifyStatus, control.StatusEvent{
+			ID:        u.SessionID,
+			Name:      name,
+			Status:    u.Status.String(),
+			OldStatus: u.OldStatus.String(),
+		})
+	}
+}
+
+// handleControl runs a control request on the main loop and replies to the caller
+func (a *App) handleControl(req *control.Request) tea.Cmd {
+	if req.Method == control.MethodList {
+		infos := make([]control.SessionInfo, 0, len(a.manager.Sessions))
+		for _, s := range a.manager.Sessions {
+			infos = append(infos, control.NewSessionInfo(s))
+		}
+		req.Reply(infos, nil)
+		return nil
+	}
+	if req.Method == control.MethodRefresh {
+		terminal.InvalidateWindows()
+		req.Reply(nil, nil)
+		return a.refreshStatusesAsync()
+	}
+
+	var params control.SessionParams
+	if err := req.Decode(&params); err != nil {
+		req.Reply(nil, err)
+		return nil
+	}
+	s, err := a.manager.ResolveSession(params.ID)
+	if err != nil {
+		req.Reply(nil, &control.Error{Code: control.CodeNotFound, Message: err.Error()})
+		return nil
+	}
+
+	var cmd tea.Cmd
+	switch req.Method {
+	case control.MethodSelect:
+		a.list.SelectSession(s.ID)
+		cmd = a.updateSelectedPreview()
+
+	case control.MethodOpen:
+		a.list.SelectSession(s.ID)
+		_, cmd = a.openSession(s, false)
+
+	case control.MethodFocus:
+		windowID := session.GetActiveWindowID(s)
+		if windowID == 0 {
+			err = errors.New("session has no open tab")
+			break
+		}
+		err = terminal.Current().Focus(windowID)
+
+	case control.MethodRename:
+		err = a.renameSession(s.ID, params.Name)
+		a.skipNextStatusSave = true
+
+	case control.MethodMove:
+		groupPath := ""
+		if params.Group != "" {
+			g, gErr := a.manager.ResolveGroup(params.Group)
+			if gErr != nil {
+				req.Reply(nil, &control.Error{Code: control.CodeNotFound, Message: gErr.Error()})
+				return nil
+			}
+			groupPath = g.Path
+		}
+		err = a.manager.MoveSession(s.ID, groupPath)
+		a.list.Refresh()
+	}
+
+	if err != nil {
+		req.Reply(nil, err)
+	} else {
+		req.Reply(control.NewSessionInfo(s), nil)
+	}
+	return cmd
+}
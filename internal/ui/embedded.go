@@ -0,0 +1,210 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/hadar/claude-deck/internal/session"
+	"github.com/hadar/claude-deck/internal/terminal"
+)
+
+// Keys handled by the deck while attached to an embedded session
+// (everything else is forwarded to the session's terminal)
+const (
+	detachKey = "ctrl+]"
+	zoomKey   = "ctrl+\\"
+)
+
+// embeddedOutputMsg is sent when an embedded terminal's screen changes
+type embeddedOutputMsg struct{}
+
+// embeddedStateMsg is sent when an embedded process exits or changes its title
+type embeddedStateMsg struct{}
+
+// watchEmbedded waits for the next embedded terminal event
+// Like watchFiles, it returns after each event and must be restarted
+func (a *App) watchEmbedded() tea.Cmd {
+	eb := terminal.Embedded()
+	if eb == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		select {
+		case <-eb.StateChanges():
+			return embeddedStateMsg{}
+		case <-eb.Output():
+			return embeddedOutputMsg{}
+		}
+	}
+}
+
+// embeddedWindowID returns the live embedded window for a session, or 0
+func embeddedWindowID(s *session.Session) int {
+	eb := terminal.Embedded()
+	if eb == nil || s == nil || s.KittyWindowID <= 0 || !eb.Alive(s.KittyWindowID) {
+		return 0
+	}
+	return s.KittyWindowID
+}
+
+// attach starts forwarding keys to an embedded window
+func (a *App) attach(windowID int, zoomed bool) tea.Cmd {
+	a.attachedWindow = windowID
+	a.zoomed = zoomed
+	a.focus = FocusPreview
+	a.resizeEmbedded()
+	return a.setStatus("Attached - ctrl+] to detach, ctrl+\\ to zoom")
+}
+
+// detach returns keyboard control to the session list
+func (a *App) detach() {
+	a.attachedWindow = 0
+	a.zoomed = false
+	a.focus = FocusList
+	a.resizeEmbedded()
+}
+
+// resizeEmbedded sizes the attached (or selected) embedded window to where it's drawn
+func (a *App) resizeEmbedded() {
+	eb := terminal.Embedded()
+	if eb == nil {
+		return
+	}
+	windowID := a.attachedWindow
+	if windowID == 0 {
+		if item := a.list.SelectedItem(); item != nil && !item.IsGroup() {
+			windowID = embeddedWindowID(item.Session)
+		}
+	}
+	if windowID == 0 {
+		return
+	}
+	if a.zoomed {
+		eb.Resize(windowID, a.width, a.height-statusHeight)
+	} else {
+		eb.Resize(windowID, a.preview.width, a.preview.height)
+	}
+}
+
+// updateAttached forwards keys to the attached embedded window
+func (a *App) updateAttached(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	eb := terminal.Embedded()
+	if eb == nil || !eb.Alive(a.attachedWindow) {
+		a.detach()
+		return a, nil
+	}
+
+	switch msg.String() {
+	case detachKey:
+		a.detach()
+		return a, a.setStatus("Detached")
+	case zoomKey:
+		a.zoomed = !a.zoomed
+		a.resizeEmbedded()
+		return a, nil
+	}
+
+	if data := keyToBytes(msg, eb.AppCursorKeys(a.attachedWindow)); len(data) > 0 {
+		eb.Write(a.attachedWindow, data)
+	}
+	return a, nil
+}
+
+// renderEmbedded renders an embedded window's screen clipped/padded to width x height
+func renderEmbedded(windowID, width, height int, showCursor bool) (string, bool) {
+	eb := terminal.Embedded()
+	if eb == nil {
+		return "", false
+	}
+	screen, ok := eb.Screen(windowID, showCursor)
+	if !ok {
+		return "", false
+	}
+
+	lines := strings.Split(screen, "\n")
+	result := make([]string, height)
+	for i := 0; i < height; i++ {
+		line := ""
+		if i < len(lines) {
+			line = ansi.Truncate(lines[i], width, "")
+		}
+		if w := lipgloss.Width(line); w < width {
+			line += strings.Repeat(" ", width-w)
+		}
+		result[i] = line
+	}
+	return strings.Join(result, "\n"), true
+}
+
+// renderZoomed renders the attached window full screen with a one-line hint bar
+func (a *App) renderZoomed() (string, bool) {
+	screen, ok := renderEmbedded(a.attachedWindow, a.width, a.height-statusHeight, true)
+	if !ok {
+		return "", false
+	}
+	hint := helpStyle.Render("ctrl+]:detach  ctrl+\\:unzoom")
+	return screen + "\n" + hint, true
+}
+
+// keyToBytes encodes a key press as the bytes a terminal would send
+// appCursor selects application cursor key sequences (ESC O A instead of ESC [ A).
+func keyToBytes(msg tea.KeyMsg, appCursor bool) []byte {
+	var seq string
+	switch msg.Type {
+	case tea.KeyRunes:
+		seq = string(msg.Runes)
+	case tea.KeySpace:
+		seq = " "
+	case tea.KeyUp, tea.KeyDown, tea.KeyRight, tea.KeyLeft:
+		dir := map[tea.KeyType]string{tea.KeyUp: "A", tea.KeyDown: "B", tea.KeyRight: "C", tea.KeyLeft: "D"}[msg.Type]
+		if appCursor {
+			seq = "\x1bO" + dir
+		} else {
+			seq = "\x1b[" + dir
+		}
+	case tea.KeyShiftUp:
+		seq = "\x1b[1;2A"
+	case tea.KeyShiftDown:
+		seq = "\x1b[1;2B"
+	case tea.KeyCtrlRight:
+		seq = "\x1b[1;5C"
+	case tea.KeyCtrlLeft:
+		seq = "\x1b[1;5D"
+	case tea.KeyShiftTab:
+		seq = "\x1b[Z"
+	case tea.KeyHome:
+		seq = "\x1b[H"
+	case tea.KeyEnd:
+		seq = "\x1b[F"
+	case tea.KeyPgUp:
+		seq = "\x1b[5~"
+	case tea.KeyPgDown:
+		seq = "\x1b[6~"
+	case tea.KeyInsert:
+		seq = "\x1b[2~"
+	case tea.KeyDelete:
+		seq = "\x1b[3~"
+	case tea.KeyF1:
+		seq = "\x1bOP"
+	case tea.KeyF2:
+		seq = "\x1bOQ"
+	case tea.KeyF3:
+		seq = "\x1bOR"
+	case tea.KeyF4:
+		seq = "\x1bOS"
+	default:
+		// Control characters (ctrl+a, enter, tab, backspace, esc...) map to their byte value
+		if (msg.Type >= 0 && msg.Type < 32) || msg.Type == 127 {
+			seq = string([]byte{byte(msg.Type)})
+		}
+	}
+	if seq == "" {
+		return nil
+	}
+	if msg.Alt {
+		seq = "\x1b" + seq
+	}
+	return []byte(seq)
+}
@@ -1,12 +1,16 @@
 package ui
 
 import (
+	"context"
+	"fmt"
 	"sort"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/hadar/claude-deck/internal/query"
 	"github.com/hadar/claude-deck/internal/session"
 )
 
@@ -49,6 +53,11 @@ type ListModel struct {
 	filter     string
 	filtered   []int
 
+	filterQuery query.Node // parsed filter (nil matches everything)
+	filterErr   error      // why the filter being typed doesn't parse
+	filterWords []string   // the filter's fuzzy words, highlighted in names
+	ranked      bool       // filtered rows are ordered by match score, not by group
+
 	// Auto-group expansion state
 	activeExpanded   bool
 	inactiveExpanded bool
@@ -82,6 +91,11 @@ type ListModel struct {
 	contentSearchQuery  string
 	contentSearchCursor int
 	contentSearchResults []session.SearchResult
+	contentSearchCancel  context.CancelFunc // stops the running search
+
+	// Cost column ($)
+	showUsage bool
+	prices    map[string]session.Price
 }
 
 // NewListModel creates a new list model
@@ -91,6 +105,8 @@ func NewListModel(manager *session.Manager) *ListModel {
 		hoverIndex:       -1,
 		activeExpanded:   manager.GetActiveExpanded(),
 		inactiveExpanded: manager.GetInactiveExpanded(),
+		showUsage:        manager.GetListUsage(),
+		prices:           manager.GetPrices(),
 	}
 	m.buildItems()
 	return m
@@ -109,7 +125,7 @@ func (m *ListModel) buildItems() {
 		} else if s.GroupPath != "" {
 			// Session belongs to a user group - don't add to Active/Inactive
 			continue
-		} else if s.Status == session.StatusWaiting || s.Status == session.StatusRunning {
+		} else if s.Status.IsActive() {
 			activeSessions = append(activeSessions, s)
 		} else {
 			inactiveSessions = append(inactiveSessions, s)
@@ -186,6 +202,7 @@ func (m *ListModel) buildItems() {
 
 func (m *ListModel) applyFilter() {
 	m.filtered = nil
+	m.ranked = false
 
 	// Content search mode - use search results order, avoid duplicates
 	if m.contentSearching && len(m.contentSearchResults) > 0 {
@@ -204,12 +221,15 @@ func (m *ListModel) applyFilter() {
 			}
 		}
 	} else {
-		// Normal name filter
+		// Normal filter
 		for i, item := range m.items {
-			if m.filter == "" || m.matchesFilter(item) {
+			if m.matchesFilter(item) {
 				m.filtered = append(m.filtered, i)
 			}
 		}
+		if len(m.filterWords) > 0 {
+			m.rankFiltered()
+		}
 	}
 
 	if m.cursor >= len(m.filtered) {
@@ -218,19 +238,90 @@ func (m *ListModel) applyFilter() {
 }
 
 func (m *ListModel) matchesFilter(item ListItem) bool {
-	name := strings.ToLower(item.Name())
-	filter := strings.ToLower(m.filter)
-	return strings.Contains(name, filter)
+	if m.filterQuery == nil {
+		return true
+	}
+	return m.filterQuery.Match(query.Target{Name: item.Name(), Session: item.Session})
+}
+
+// rankFiltered orders the filtered rows by how well they match the filter's
+// words, best first (ties keep list order); sessions listed twice appear once
+func (m *ListModel) rankFiltered() {
+	seen := make(map[string]bool)
+	scores := make(map[int]int, len(m.filtered))
+	ranked := m.filtered[:0]
+	for _, idx := range m.filtered {
+		item := m.items[idx]
+		if !item.IsGroup() {
+			if seen[item.Session.ID] {
+				continue
+			}
+			seen[item.Session.ID] = true
+		}
+		scores[idx] = query.Score(m.filterQuery, query.Target{Name: item.Name(), Session: item.Session})
+		ranked = append(ranked, idx)
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return scores[ranked[i]] > scores[ranked[j]]
+	})
+	m.filtered = ranked
+	m.ranked = true
 }
 
+// nameHighlights returns the byte offsets in name the filter's words matched
+func (m *ListModel) nameHighlights(name string) map[int]bool {
+	if len(m.filterWords) == 0 {
+		return nil
+	}
+	matched := make(map[int]bool)
+	for _, word := range m.filterWords {
+		if _, positions, ok := query.Fuzzy(word, name); ok {
+			for _, pos := range positions {
+				matched[pos] = true
+			}
+		}
+	}
+	return matched
+}
+
+// SetShowUsage shows or hides the cost column, priced with prices
+func (m *ListModel) SetShowUsage(show bool, prices map[string]session.Price) {
+	m.showUsage = show
+	m.prices = prices
+}
+
+// ShowUsage returns whether the cost column is shown
+func (m *ListModel) ShowUsage() bool {
+	return m.showUsage
+}
+
+// SetFilter parses and applies a filter query
+// A query that doesn't parse (e.g. half typed) keeps the last one that did.
 func (m *ListModel) SetFilter(filter string) {
 	m.filter = filter
+	node, err := query.Parse(filter)
+	m.filterErr = err
+	if err == nil {
+		m.filterQuery = node
+		m.filterWords = query.Words(node)
+	}
 	m.applyFilter()
 }
 
 func (m *ListModel) ClearFilter() {
-	m.filter = ""
-	m.applyFilter()
+	m.SetFilter("")
+}
+
+// Filter returns the current filter query
+func (m *ListModel) Filter() string {
+	return m.filter
+}
+
+// ApplyQuery shows a query in the search line and filters by it
+func (m *ListModel) ApplyQuery(q string) {
+	m.searchQuery = q
+	m.searchCursor = len(q)
+	m.SetFilter(q)
 }
 
 func (m *ListModel) Refresh() {
@@ -348,6 +439,25 @@ func (m *ListModel) SetCursor(pos int) {
 	}
 }
 
+// SelectSession moves the cursor to a session, clearing a filter that hides it
+// Returns false if the session isn't visible (e.g. inside a collapsed group)
+func (m *ListModel) SelectSession(id string) bool {
+	for pass := 0; pass < 2; pass++ {
+		for i, idx := range m.filtered {
+			if item := m.items[idx]; !item.IsGroup() && item.Session.ID == id {
+				m.cursor = i
+				m.ensureVisible()
+				return true
+			}
+		}
+		if m.filter == "" {
+			break
+		}
+		m.ClearFilter()
+	}
+	return false
+}
+
 func (m *ListModel) HandleClick(y int) bool {
 	targetIdx := m.offset + y
 	if targetIdx >= 0 && targetIdx < len(m.filtered) {
@@ -597,8 +707,7 @@ func (m *ListModel) IsSearching() bool {
 func (m *ListModel) CancelSearch() {
 	m.searching = false
 	m.searchQuery = ""
-	m.filter = ""
-	m.applyFilter()
+	m.SetFilter("")
 }
 
 // ConfirmSearch exits search mode but keeps filter
@@ -627,8 +736,7 @@ func (m *ListModel) HandleSearchKey(key string) {
 	if handled {
 		m.searchQuery = newText
 		m.searchCursor = newCursor
-		m.filter = m.searchQuery
-		m.applyFilter()
+		m.SetFilter(m.searchQuery)
 	}
 }
 
@@ -647,6 +755,7 @@ func (m *ListModel) IsContentSearching() bool {
 
 // CancelContentSearch exits content search mode
 func (m *ListModel) CancelContentSearch() {
+	m.stopContentSearch()
 	m.contentSearching = false
 	m.contentSearchQuery = ""
 	m.contentSearchResults = nil
@@ -656,27 +765,28 @@ func (m *ListModel) CancelContentSearch() {
 // ConfirmContentSearch selects the current result
 // Does NOT rebuild filter - keeps selection intact for handleOpen
 func (m *ListModel) ConfirmContentSearch() {
+	m.stopContentSearch()
 	m.contentSearching = false
 	m.contentSearchResults = nil
 	// Don't call applyFilter here - we want to keep the current selection
 }
 
-// GetSearchSnippet returns the search snippet for the currently selected item
-func (m *ListModel) GetSearchSnippet() string {
+// GetSearchHits returns the content search hits for the currently selected item, best first
+func (m *ListModel) GetSearchHits() []session.SearchHit {
 	if !m.contentSearching || len(m.contentSearchResults) == 0 {
-		return ""
+		return nil
 	}
 	item := m.SelectedItem()
 	if item == nil || item.IsGroup() {
-		return ""
+		return nil
 	}
 	// Find matching search result
 	for _, r := range m.contentSearchResults {
 		if r.Session.ID == item.Session.ID {
-			return r.Snippet
+			return r.Hits
 		}
 	}
-	return ""
+	return nil
 }
 
 // HandleContentSearchKey processes a key during content search mode
@@ -707,35 +817,48 @@ func (m *ListModel) HandleContentSearchKey(key string) bool {
 	return false
 }
 
-// ContentSearchResultsMsg carries async search results
+// ContentSearchResultsMsg carries the ranked results of an async search
 type ContentSearchResultsMsg struct {
 	Query   string
 	Results []session.SearchResult
 }
 
-// RunContentSearch returns a command to execute the search async
+// RunContentSearch returns a command that runs the search in the background
+// The results arrive as a ContentSearchResultsMsg; a running search for an older
+// query is stopped.
 func (m *ListModel) RunContentSearch() tea.Cmd {
+	m.stopContentSearch()
 	if len(m.contentSearchQuery) < 3 {
 		m.contentSearchResults = nil
 		m.applyFilter()
 		return nil
 	}
-	query := m.contentSearchQuery
-	sessions := m.manager.Sessions
+	ctx, cancel := context.WithCancel(context.Background())
+	m.contentSearchCancel = cancel
+	query, sessions := m.contentSearchQuery, m.manager.Sessions
 	return func() tea.Msg {
-		results := session.SearchContent(sessions, query)
-		return ContentSearchResultsMsg{Query: query, Results: results}
+		return ContentSearchResultsMsg{Query: query, Results: session.SearchContent(ctx, sessions, query)}
+	}
+}
+
+// stopContentSearch cancels the running search, if any
+func (m *ListModel) stopContentSearch() {
+	if m.contentSearchCancel != nil {
+		m.contentSearchCancel()
+		m.contentSearchCancel = nil
 	}
 }
 
-// HandleContentSearchResults processes async search results
+// HandleContentSearchResults shows the results of an async search
 func (m *ListModel) HandleContentSearchResults(msg ContentSearchResultsMsg) {
 	// Only apply if query still matches
-	if msg.Query == m.contentSearchQuery {
-		m.contentSearchResults = msg.Results
-		m.cursor = 0
-		m.applyFilter()
+	if msg.Query != m.contentSearchQuery || !m.contentSearching {
+		return
 	}
+	m.contentSearchResults = msg.Results
+	m.cursor = 0
+	m.applyFilter()
+	m.stopContentSearch()
 }
 
 // View renders the list as a table
@@ -756,6 +879,9 @@ func (m *ListModel) View() string {
 	dateW := 12
 	// Account for: prefix(3) + separator(" │ " = 3) + date(12) + margin(4)
 	nameW := m.width - dateW - 3 - 3 - 4
+	if m.showUsage {
+		nameW -= usageColumnWidth + 3
+	}
 	if nameW < 20 {
 		nameW = 20
 	}
@@ -767,6 +893,9 @@ func (m *ListModel) View() string {
 
 	// Header row - same format as data rows (pin + status + space = 3 chars prefix)
 	headerText := padStr("Name", nameW) + " │ " + padStr("Date", dateW)
+	if m.showUsage {
+		headerText += " │ " + padStr("Cost", usageColumnWidth)
+	}
 	header := "   " + helpStyle.Render(headerText)
 	lines = append(lines, header)
 
@@ -826,7 +955,11 @@ func (m *ListModel) View() string {
 		if cursor <= len(searchText) {
 			searchText = searchText[:cursor] + "_" + searchText[cursor:]
 		}
-		lines = append(lines, " "+prefix+searchText)
+		line := " " + prefix + searchText
+		if m.searching && m.filterErr != nil {
+			line = ansi.Truncate(line+"  "+statusErrorStyle.Render(m.filterErr.Error()), m.width, "…")
+		}
+		lines = append(lines, line)
 	}
 
 	return strings.Join(lines, "\n")
@@ -849,6 +982,9 @@ func padStr(s string, width int) string {
 // renderRow renders a single row in table format - MUST NOT exceed width
 func (m *ListModel) renderRow(item ListItem, selected, hovered bool, nameW, dateW int) string {
 	totalWidth := nameW + dateW + 6 // prefix(~4) + separator(3)
+	if m.showUsage {
+		totalWidth += usageColumnWidth + 3
+	}
 
 	if item.IsGroup() {
 		arrow := "▶"
@@ -880,10 +1016,11 @@ func (m *ListModel) renderRow(item ListItem, selected, hovered bool, nameW, date
 	// Session row
 	s := item.Session
 
-	// Tree prefix for items in groups
+	// Tree prefix for items in groups (ranked rows aren't in group order)
+	indented := item.Indent > 0 && !m.ranked
 	treePrefix := ""
 	prefixExtra := 0
-	if item.Indent > 0 {
+	if indented {
 		if item.IsLastInTree {
 			treePrefix = "└"
 		} else {
@@ -897,6 +1034,7 @@ func (m *ListModel) renderRow(item ListItem, selected, hovered bool, nameW, date
 
 	// Build name/date content
 	var nameText string
+	var highlights map[int]bool // byte offsets of matched characters in nameText
 	showDeleteX := false
 	if selected && m.renaming {
 		nameWithCursor := m.renameInput[:m.renameCursor] + "_" + m.renameInput[m.renameCursor:]
@@ -910,9 +1048,21 @@ func (m *ListModel) renderRow(item ListItem, selected, hovered bool, nameW, date
 			name = s.FolderName()
 		}
 		nameText = padStr(name, effectiveNameW)
+		highlights = m.nameHighlights(name)
+		if len(name) > effectiveNameW {
+			// Only what's left of the name before the ".."
+			for pos := range highlights {
+				if pos >= effectiveNameW-2 {
+					delete(highlights, pos)
+				}
+			}
+		}
 	}
 	dateText := padStr(s.LastAccessedAt.Format("Jan 2 15:04"), dateW)
 	content := nameText + " │ " + dateText
+	if m.showUsage {
+		content += " │ " + padStr(formatSessionCost(s, m.prices), usageColumnWidth)
+	}
 
 	// Get status style - preserve color even when selected by adding background
 	statusStyle := StatusStyle(s.Status.String())
@@ -923,7 +1073,7 @@ func (m *ListModel) renderRow(item ListItem, selected, hovered bool, nameW, date
 
 	// Build styled prefix parts
 	var prefix string
-	if item.Indent > 0 {
+	if indented {
 		if selected {
 			prefix = selectedItemStyle.Render(" "+treePrefix)
 		} else {
@@ -962,7 +1112,22 @@ func (m *ListModel) renderRow(item ListItem, selected, hovered bool, nameW, date
 		}
 	}
 
-	// Apply style to content
+	// Apply style to content, highlighting the filter's matches in the name
+	if len(highlights) > 0 {
+		style, matchStyle := itemStyle, matchHighlightStyle
+		if selected {
+			style, matchStyle = selectedItemStyle, matchHighlightStyle.Background(surfaceColor)
+		} else if hovered {
+			style = hoverItemStyle
+		}
+		row := highlightRuns(content, highlights, style, matchStyle)
+		if selected {
+			if pad := totalWidth - 4 - prefixExtra - lipgloss.Width(row); pad > 0 {
+				row += style.Render(strings.Repeat(" ", pad))
+			}
+		}
+		return prefix + row
+	}
 	if selected {
 		// Calculate remaining width for content
 		contentWidth := totalWidth - 4 - prefixExtra // rough prefix width
@@ -976,6 +1141,57 @@ func (m *ListModel) renderRow(item ListItem, selected, hovered bool, nameW, date
 	return prefix + itemStyle.Render(content)
 }
 
+// usageColumnWidth is the width of the cost column
+const usageColumnWidth = 8
+
+// formatSessionCost formats a session's estimated cost for the cost column
+// ("" without usage; * marks models missing from the price table)
+func formatSessionCost(s *session.Session, prices map[string]session.Price) string {
+	if s.Usage.Tokens().Total() == 0 {
+		return ""
+	}
+	return formatCost(s.Usage.Cost(prices))
+}
+
+// formatCost formats an estimated cost in USD, marking it * if some usage wasn't priced
+func formatCost(cost float64, priced bool) string {
+	text := fmt.Sprintf("$%.2f", cost)
+	if cost >= 1000 {
+		text = fmt.Sprintf("$%.0f", cost)
+	}
+	if !priced {
+		text += "*"
+	}
+	return text
+}
+
+// highlightRuns renders text in style, with the runes starting at the
+// highlighted byte offsets in matchStyle
+func highlightRuns(text string, highlighted map[int]bool, style, matchStyle lipgloss.Style) string {
+	var b strings.Builder
+	render := func(run string, match bool) {
+		if match {
+			b.WriteString(matchStyle.Render(run))
+		} else {
+			b.WriteString(style.Render(run))
+		}
+	}
+	start, inMatch := 0, false
+	for i := range text {
+		if highlighted[i] == inMatch {
+			continue
+		}
+		if i > start {
+			render(text[start:i], inMatch)
+		}
+		start, inMatch = i, highlighted[i]
+	}
+	if start < len(text) {
+		render(text[start:], inMatch)
+	}
+	return b.String()
+}
+
 // truncateRow truncates a row to maxWidth, accounting for ANSI codes
 func truncateRow(s string, maxWidth int) string {
 	return lipgloss.NewStyle().MaxWidth(maxWidth).Render(s)
@@ -1016,6 +1232,13 @@ type ListKeyMap struct {
 	Layout        key.Binding
 	Theme         key.Binding
 	Resume        key.Binding
+	Zoom          key.Binding
+	Hooks         key.Binding
+	Transcript    key.Binding
+	Tools         key.Binding
+	SavedQueries  key.Binding
+	Usage         key.Binding
+	UsageColumn   key.Binding
 }
 
 // DefaultListKeyMap returns the default key bindings
@@ -1097,6 +1320,34 @@ func DefaultListKeyMap() ListKeyMap {
 			key.WithKeys("S"),
 			key.WithHelp("S", "resume"),
 		),
+		Zoom: key.NewBinding(
+			key.WithKeys("Z"),
+			key.WithHelp("Z", "zoom embedded session"),
+		),
+		Hooks: key.NewBinding(
+			key.WithKeys("I"),
+			key.WithHelp("I", "install/remove Claude hooks"),
+		),
+		Transcript: key.NewBinding(
+			key.WithKeys("V"),
+			key.WithHelp("V", "view transcript"),
+		),
+		Tools: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "show tool calls"),
+		),
+		SavedQueries: key.NewBinding(
+			key.WithKeys("F"),
+			key.WithHelp("F", "saved filters"),
+		),
+		Usage: key.NewBinding(
+			key.WithKeys("U"),
+			key.WithHelp("U", "usage"),
+		),
+		UsageColumn: key.NewBinding(
+			key.WithKeys("$"),
+			key.WithHelp("$", "cost column"),
+		),
 	}
 }
 
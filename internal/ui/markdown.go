@@ -0,0 +1,417 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// markdownSpan is a run of inline text in one style
+type markdownSpan struct {
+	text  string
+	style lipgloss.Style
+}
+
+// renderMarkdown renders markdown to styled lines that fit width
+// Handles headings, lists, quotes, rules, fenced code blocks (highlighted by
+// language), bold, italic and inline code; anything else is shown as text.
+// Code lines aren't wrapped - the preview truncates them.
+func renderMarkdown(text string, width int, base lipgloss.Style) []string {
+	if width <= 0 {
+		width = 80
+	}
+
+	var lines []string
+	inCode := false
+	var lang string
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if fence, ok := strings.CutPrefix(trimmed, "```"); ok {
+			if inCode {
+				inCode = false
+				continue
+			}
+			inCode = true
+			lang = strings.ToLower(strings.TrimSpace(fence))
+			if lang != "" {
+				lines = append(lines, codeFenceStyle.Render("  "+lang))
+			}
+			continue
+		}
+		if inCode {
+			lines = append(lines, "  "+highlightCode(line, lang))
+			continue
+		}
+
+		switch {
+		case trimmed == "":
+			lines = append(lines, "")
+		case isMarkdownRule(trimmed):
+			lines = append(lines, codeFenceStyle.Render(strings.Repeat("─", min(width, 40))))
+		case isMarkdownHeading(trimmed):
+			heading := strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+			lines = append(lines, wrapSpans(parseInline(heading, markdownHeadingStyle), width, "", "")...)
+		case strings.HasPrefix(trimmed, ">"):
+			quote := strings.TrimSpace(strings.TrimPrefix(trimmed, ">"))
+			bar := markdownQuoteStyle.Render("│ ")
+			lines = append(lines, wrapSpans(parseInline(quote, markdownQuoteStyle), width, bar, bar)...)
+		default:
+			if indent, marker, item, ok := parseListItem(line); ok {
+				first := strings.Repeat(" ", indent) + markdownBulletStyle.Render(marker) + " "
+				rest := strings.Repeat(" ", indent+ansi.StringWidth(marker)+1)
+				lines = append(lines, wrapSpans(parseInline(item, base), width, first, rest)...)
+				continue
+			}
+			lines = append(lines, wrapSpans(parseInline(trimmed, base), width, "", "")...)
+		}
+	}
+	return lines
+}
+
+// isMarkdownRule returns whether a line is a horizontal rule (---, ***, ___)
+func isMarkdownRule(line string) bool {
+	if len(line) < 3 {
+		return false
+	}
+	c := line[0]
+	if c != '-' && c != '*' && c != '_' {
+		return false
+	}
+	count := 0
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case c:
+			count++
+		case ' ':
+		default:
+			return false
+		}
+	}
+	return count >= 3
+}
+
+// isMarkdownHeading returns whether a line is an ATX heading (# to ######)
+func isMarkdownHeading(line string) bool {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	return level >= 1 && level <= 6 && level < len(line) && line[level] == ' '
+}
+
+// parseListItem splits a list item into its indent, marker ("•" or "1.") and text
+func parseListItem(line string) (int, string, string, bool) {
+	line = strings.ReplaceAll(line, "\t", "    ")
+	rest := strings.TrimLeft(line, " ")
+	indent := len(line) - len(rest)
+
+	marker, text, ok := strings.Cut(rest, " ")
+	if !ok || strings.TrimSpace(text) == "" {
+		return 0, "", "", false
+	}
+	switch {
+	case marker == "-" || marker == "*" || marker == "+":
+		marker = "•"
+	case len(marker) >= 2 && len(marker) <= 4 && (strings.HasSuffix(marker, ".") || strings.HasSuffix(marker, ")")):
+		for _, r := range marker[:len(marker)-1] {
+			if r < '0' || r > '9' {
+				return 0, "", "", false
+			}
+		}
+	default:
+		return 0, "", "", false
+	}
+	return indent, marker, strings.TrimSpace(text), true
+}
+
+// parseInline splits text into spans for **bold**, *italic* and `code`
+// Markers that aren't closed are kept as text.
+func parseInline(text string, style lipgloss.Style) []markdownSpan {
+	var spans []markdownSpan
+	plain := 0
+	flush := func(end int) {
+		if end > plain {
+			spans = append(spans, markdownSpan{text[plain:end], style})
+		}
+	}
+
+	for i := 0; i < len(text); {
+		if text[i] == '`' {
+			if end := strings.IndexByte(text[i+1:], '`'); end >= 0 {
+				flush(i)
+				spans = append(spans, markdownSpan{text[i+1 : i+1+end], inlineCodeStyle})
+				i += end + 2
+				plain = i
+				continue
+			}
+		}
+
+		if marker, end := emphasisAt(text, i); end >= 0 {
+			inner := style.Bold(true)
+			if len(marker) == 1 {
+				inner = style.Italic(true)
+			}
+			flush(i)
+			spans = append(spans, parseInline(text[i+len(marker):end], inner)...)
+			i = end + len(marker)
+			plain = i
+			continue
+		}
+		i++
+	}
+	flush(len(text))
+	return spans
+}
+
+// emphasisAt returns the emphasis marker at i and where it closes (-1 if none)
+func emphasisAt(text string, i int) (string, int) {
+	for _, marker := range []string{"**", "__", "*", "_"} {
+		if strings.HasPrefix(text[i:], marker) {
+			return marker, closingMarker(text, i, marker)
+		}
+	}
+	return "", -1
+}
+
+// closingMarker finds the marker closing an emphasis opened at start, or -1
+// Emphasis has to hug its text, and markers inside words (snake_case) don't count.
+func closingMarker(text string, start int, marker string) int {
+	open := start + len(marker)
+	if open >= len(text) || text[open] == ' ' || (start > 0 && isWordChar(text[start-1])) {
+		return -1
+	}
+	for j := open + 1; j+len(marker) <= len(text); j++ {
+		if text[j:j+len(marker)] != marker || text[j-1] == ' ' {
+			continue
+		}
+		after := j + len(marker)
+		if after < len(text) && (isWordChar(text[after]) || text[after] == marker[0]) {
+			continue
+		}
+		return j
+	}
+	return -1
+}
+
+// wrapSpans word-wraps styled spans to width
+// first prefixes the first line and rest the others (e.g. a bullet and its indent).
+func wrapSpans(spans []markdownSpan, width int, first, rest string) []string {
+	// Words can mix styles ("**bold**,"), so a word is a list of spans
+	var words [][]markdownSpan
+	newWord := true
+	for _, span := range spans {
+		for i, piece := range strings.Split(span.text, " ") {
+			if i > 0 {
+				newWord = true
+			}
+			if piece == "" {
+				continue
+			}
+			if newWord {
+				words = append(words, nil)
+				newWord = false
+			}
+			words[len(words)-1] = append(words[len(words)-1], markdownSpan{piece, span.style})
+		}
+	}
+
+	var lines []string
+	var b strings.Builder
+	b.WriteString(first)
+	lineWidth := ansi.StringWidth(first)
+	empty := true
+	for _, word := range words {
+		wordWidth := 0
+		for _, span := range word {
+			wordWidth += ansi.StringWidth(span.text)
+		}
+		if !empty && lineWidth+1+wordWidth > width {
+			lines = append(lines, b.String())
+			b.Reset()
+			b.WriteString(rest)
+			lineWidth = ansi.StringWidth(rest)
+			empty = true
+		}
+		if !empty {
+			b.WriteString(" ")
+			lineWidth++
+		}
+		for _, span := range word {
+			b.WriteString(span.style.Render(span.text))
+		}
+		lineWidth += wordWidth
+		empty = false
+	}
+	return append(lines, b.String())
+}
+
+// Kinds of code tokens
+const (
+	codePlain = iota
+	codeKeyword
+	codeString
+	codeNumber
+	codeComment
+)
+
+// codeToken is a highlighted piece of a code line
+type codeToken struct {
+	text string
+	kind int
+}
+
+// codeLanguage is what highlighting needs to know about a language
+type codeLanguage struct {
+	keywords      map[string]bool
+	lineComment   string
+	blockComments bool // /* ... */
+}
+
+func keywordSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(words) {
+		set[w] = true
+	}
+	return set
+}
+
+var (
+	goLanguage = &codeLanguage{keywords: keywordSet(`break case chan const continue default defer else fallthrough
+		for func go goto if import interface map package range return select struct switch type var
+		nil true false iota`), lineComment: "//", blockComments: true}
+	pythonLanguage = &codeLanguage{keywords: keywordSet(`and as assert async await break class continue def del elif else
+		except finally for from global if import in is lambda nonlocal not or pass raise return try while
+		with yield None True False self`), lineComment: "#"}
+	jsLanguage = &codeLanguage{keywords: keywordSet(`async await break case catch class const continue default delete do
+		else export extends finally for from function if import in instanceof interface let new of return
+		static super switch this throw try type typeof var void while yield null undefined true false`),
+		lineComment: "//", blockComments: true}
+	rustLanguage = &codeLanguage{keywords: keywordSet(`as async await break const continue crate else enum extern fn for
+		if impl in let loop match mod move mut pub ref return self Self static struct super trait type
+		unsafe use where while true false`), lineComment: "//", blockComments: true}
+	cLanguage = &codeLanguage{keywords: keywordSet(`auto bool break case catch char class const continue default delete
+		do double else enum extends final float for if implements import int long namespace new null
+		nullptr package private protected public return short signed static struct switch template this
+		throw try typedef union unsigned using void volatile while true false`), lineComment: "//", blockComments: true}
+	shellLanguage = &codeLanguage{keywords: keywordSet(`if then else elif fi for while until do done case esac in
+		function return local export`), lineComment: "#"}
+	yamlLanguage = &codeLanguage{keywords: keywordSet(`true false null yes no`), lineComment: "#"}
+	jsonLanguage = &codeLanguage{keywords: keywordSet(`true false null`)}
+	sqlLanguage  = &codeLanguage{keywords: keywordSet(`select from where and or not insert into values update set
+		delete create table drop alter index join left right inner outer on group by order having limit as
+		null is in primary key SELECT FROM WHERE AND OR NOT INSERT INTO VALUES UPDATE SET DELETE CREATE
+		TABLE DROP ALTER INDEX JOIN LEFT RIGHT INNER OUTER ON GROUP BY ORDER HAVING LIMIT AS NULL IS IN
+		PRIMARY KEY`), lineComment: "--"}
+)
+
+// codeLanguages maps fence info strings to languages
+var codeLanguages = map[string]*codeLanguage{
+	"go": goLanguage, "golang": goLanguage,
+	"python": pythonLanguage, "py": pythonLanguage,
+	"javascript": jsLanguage, "js": jsLanguage, "jsx": jsLanguage,
+	"typescript": jsLanguage, "ts": jsLanguage, "tsx": jsLanguage,
+	"rust": rustLanguage, "rs": rustLanguage,
+	"c": cLanguage, "cpp": cLanguage, "c++": cLanguage, "java": cLanguage, "kotlin": cLanguage,
+	"sh": shellLanguage, "bash": shellLanguage, "shell": shellLanguage, "zsh": shellLanguage,
+	"yaml": yamlLanguage, "yml": yamlLanguage, "toml": yamlLanguage,
+	"json": jsonLanguage,
+	"sql":  sqlLanguage,
+}
+
+// highlightCode styles a line of a fenced code block
+func highlightCode(line, lang string) string {
+	var b strings.Builder
+	for _, tok := range tokenizeCode(line, lang) {
+		b.WriteString(codeTokenStyle(tok.kind).Render(tok.text))
+	}
+	return b.String()
+}
+
+func codeTokenStyle(kind int) lipgloss.Style {
+	switch kind {
+	case codeKeyword:
+		return codeKeywordStyle
+	case codeString:
+		return codeStringStyle
+	case codeNumber:
+		return codeNumberStyle
+	case codeComment:
+		return codeCommentStyle
+	default:
+		return codeStyle
+	}
+}
+
+// tokenizeCode splits a code line into keywords, strings, numbers and comments
+// Each line is tokenized on its own, so block comments and strings spanning
+// lines only colour their first line. Unknown languages are a single plain token.
+func tokenizeCode(line, lang string) []codeToken {
+	line = strings.ReplaceAll(line, "\t", "    ")
+	language := codeLanguages[lang]
+	if language == nil {
+		return []codeToken{{line, codePlain}}
+	}
+
+	var tokens []codeToken
+	add := func(text string, kind int) {
+		if n := len(tokens); n > 0 && tokens[n-1].kind == kind {
+			tokens[n-1].text += text
+			return
+		}
+		tokens = append(tokens, codeToken{text, kind})
+	}
+
+	for i := 0; i < len(line); {
+		rest := line[i:]
+		c := line[i]
+		switch {
+		case language.lineComment != "" && strings.HasPrefix(rest, language.lineComment) &&
+			(language.lineComment != "#" || i == 0 || line[i-1] == ' '):
+			add(rest, codeComment)
+			return tokens
+		case language.blockComments && strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest[2:], "*/")
+			if end < 0 {
+				add(rest, codeComment)
+				return tokens
+			}
+			add(rest[:end+4], codeComment)
+			i += end + 4
+		case c == '"' || c == '\'' || c == '`':
+			end := i + 1
+			for end < len(line) && line[end] != c {
+				if line[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end+1, len(line))
+			add(line[i:end], codeString)
+			i = end
+		case c >= '0' && c <= '9' && (i == 0 || !isWordChar(line[i-1])):
+			end := i + 1
+			for end < len(line) && (isWordChar(line[end]) || line[end] == '.') {
+				end++
+			}
+			add(line[i:end], codeNumber)
+			i = end
+		case isWordChar(c) || c >= 0x80:
+			end := i + 1
+			for end < len(line) && (isWordChar(line[end]) || line[end] >= 0x80) {
+				end++
+			}
+			word := line[i:end]
+			if language.keywords[word] {
+				add(word, codeKeyword)
+			} else {
+				add(word, codePlain)
+			}
+			i = end
+		default:
+			add(string(c), codePlain)
+			i++
+		}
+	}
+	return tokens
+}
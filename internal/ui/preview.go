@@ -7,6 +7,7 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/hadar/claude-deck/internal/session"
 )
 
@@ -27,7 +28,10 @@ type PreviewModel struct {
 	loading       bool
 	err           error
 	lastSessionID string
-	searchSnippet string // If set, show "Found" section with this snippet
+	searchHits    []session.SearchHit // If set, show "Found" section with these snippets
+	hitCursor     int                 // selected hit (Tab cycles)
+	showTools     bool                // Show tool calls and their results (t)
+	prices        map[string]session.Price
 }
 
 // NewPreviewModel creates a new preview model
@@ -35,6 +39,11 @@ func NewPreviewModel() *PreviewModel {
 	return &PreviewModel{}
 }
 
+// SetPrices sets the price table the header's cost estimate uses
+func (m *PreviewModel) SetPrices(prices map[string]session.Price) {
+	m.prices = prices
+}
+
 // SetSession updates the preview for a new session
 // Returns a command to load the preview async
 func (m *PreviewModel) SetSession(s *session.Session) tea.Cmd {
@@ -58,16 +67,27 @@ func (m *PreviewModel) SetSession(s *session.Session) tea.Cmd {
 	m.loading = true
 	m.messages = nil
 
-	// Return command to load async
-	sessionID := s.ID
-	sess := s
+	return m.load()
+}
+
+// load returns a command that reads the preview messages of the current session
+func (m *PreviewModel) load() tea.Cmd {
+	sess := m.session
+	sessionID := m.lastSessionID
+	showTools := m.showTools
 	return func() tea.Msg {
-		messages, err := session.GetPreview(sess)
+		var messages []session.PreviewMessage
+		var err error
+		if showTools {
+			messages, err = session.GetPreviewWithTools(sess)
+		} else {
+			messages, err = session.GetPreview(sess)
+		}
 
 		// Filter out tool-related messages
 		var filtered []session.PreviewMessage
 		for _, msg := range messages {
-			if strings.TrimSpace(msg.Content) == "" {
+			if strings.TrimSpace(msg.Content) == "" && len(msg.Tools) == 0 {
 				continue
 			}
 			if strings.HasPrefix(msg.Content, "[Tool:") {
@@ -114,26 +134,21 @@ func (m *PreviewModel) Refresh() tea.Cmd {
 		return nil
 	}
 	m.loading = true
-	sess := m.session
-	sessionID := m.lastSessionID
-	return func() tea.Msg {
-		messages, err := session.GetPreview(sess)
-		var filtered []session.PreviewMessage
-		for _, msg := range messages {
-			if strings.TrimSpace(msg.Content) == "" {
-				continue
-			}
-			if strings.HasPrefix(msg.Content, "[Tool:") {
-				continue
-			}
-			filtered = append(filtered, msg)
-		}
-		return PreviewLoadedMsg{
-			SessionID: sessionID,
-			Messages:  filtered,
-			Err:       err,
-		}
+	return m.load()
+}
+
+// ShowTools returns whether tool calls are shown
+func (m *PreviewModel) ShowTools() bool {
+	return m.showTools
+}
+
+// SetShowTools sets whether tool calls are shown, reloading the preview if it changed
+func (m *PreviewModel) SetShowTools(show bool) tea.Cmd {
+	if m.showTools == show {
+		return nil
 	}
+	m.showTools = show
+	return m.Refresh()
 }
 
 // SetSize updates the preview dimensions
@@ -142,9 +157,25 @@ func (m *PreviewModel) SetSize(width, height int) {
 	m.height = height
 }
 
-// SetSearchSnippet sets the search snippet to display in "Found" section
-func (m *PreviewModel) SetSearchSnippet(snippet string) {
-	m.searchSnippet = snippet
+// SetSearchHits sets the content search hits to display in "Found" section
+func (m *PreviewModel) SetSearchHits(hits []session.SearchHit) {
+	m.searchHits = hits
+	m.hitCursor = 0
+}
+
+// NextHit selects the next (dir 1) or previous (dir -1) search hit, wrapping around
+func (m *PreviewModel) NextHit(dir int) {
+	if len(m.searchHits) > 0 {
+		m.hitCursor = (m.hitCursor + dir + len(m.searchHits)) % len(m.searchHits)
+	}
+}
+
+// CurrentHit returns the selected search hit, or nil
+func (m *PreviewModel) CurrentHit() *session.SearchHit {
+	if len(m.searchHits) == 0 {
+		return nil
+	}
+	return &m.searchHits[m.hitCursor]
 }
 
 // View renders the preview pane with fixed header and scrollable messages
@@ -200,7 +231,7 @@ func (m *PreviewModel) View() string {
 	for i := 0; i < headerHeight && lineIdx < height; i++ {
 		line := headerLines[i]
 		if lipgloss.Width(line) > m.width {
-			line = truncateString(line, m.width-3) + "..."
+			line = ansi.Truncate(line, m.width, "...")
 		}
 		result[lineIdx] = m.padLine(line)
 		lineIdx++
@@ -212,7 +243,7 @@ func (m *PreviewModel) View() string {
 		if msgIdx < len(messageLines) {
 			line := messageLines[msgIdx]
 			if lipgloss.Width(line) > m.width {
-				line = truncateString(line, m.width-3) + "..."
+				line = ansi.Truncate(line, m.width, "...")
 			}
 			result[lineIdx] = m.padLine(line)
 		} else {
@@ -262,11 +293,20 @@ func truncateString(s string, maxLen int) string {
 func (m *PreviewModel) renderMessages() string {
 	var lines []string
 
-	// Show "Found" section if we have a search snippet
-	if m.searchSnippet != "" {
-		lines = append(lines, previewTitleStyle.Render("Found:"))
-		wrapped := wrapText(m.searchSnippet, m.width-4)
-		lines = append(lines, userMessageStyle.Render(wrapped))
+	// Show "Found" section if we have search hits
+	if len(m.searchHits) > 0 {
+		lines = append(lines, previewTitleStyle.Render(fmt.Sprintf("Found (%d/%d):", m.hitCursor+1, len(m.searchHits))))
+		for i, hit := range m.searchHits {
+			marker := "  "
+			if i == m.hitCursor {
+				marker = searchPromptStyle.Render("▸ ")
+			}
+			lines = append(lines, marker+renderHitMeta(hit))
+			wrapped := wrapText(hit.Snippet, m.width-4)
+			for _, line := range strings.Split(wrapped, "\n") {
+				lines = append(lines, "  "+RoleStyle(hit.Role).Render(line))
+			}
+		}
 		lines = append(lines, "") // blank line
 		lines = append(lines, previewTitleStyle.Render("Recent:"))
 	}
@@ -311,6 +351,14 @@ func (m *PreviewModel) renderHeader() string {
 		lines = append(lines, previewMetaStyle.Render("Modified: ")+helpStyle.Render(m.session.LastAccessedAt.Format("Jan 2 15:04")))
 	}
 
+	// Token usage and estimated cost
+	if tokens := m.session.Usage.Tokens(); tokens.Total() > 0 {
+		usage := fmt.Sprintf("%s in · %s out · %s cached · ~%s",
+			session.FormatTokens(tokens.Input), session.FormatTokens(tokens.Output),
+			session.FormatTokens(tokens.CacheWrite+tokens.CacheRead), formatCost(m.session.Usage.Cost(m.prices)))
+		lines = append(lines, previewMetaStyle.Render("Usage: ")+helpStyle.Render(usage))
+	}
+
 	return strings.Join(lines, "\n")
 }
 
@@ -334,11 +382,110 @@ func (m *PreviewModel) renderMessage(msg session.PreviewMessage) string {
 	}
 
 	header := roleStyle.Render(roleName) + previewMetaStyle.Render(timeStr)
+	lines := []string{header}
+
+	// Wrap text to fit width; Claude writes markdown
+	switch {
+	case msg.Content == "":
+	case msg.Role == "user":
+		lines = append(lines, contentStyle.Render(wrapText(msg.Content, m.width-2)))
+	default:
+		lines = append(lines, renderMarkdown(msg.Content, m.width-2, contentStyle)...)
+	}
+
+	for _, tool := range msg.Tools {
+		lines = append(lines, renderToolCall(tool, maxPreviewDiffLines)...)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// renderHitMeta renders who wrote a search hit's message, when, and where it is
+func renderHitMeta(hit session.SearchHit) string {
+	meta := assistantRoleStyle.Render("Claude")
+	if hit.Role == "user" {
+		meta = userRoleStyle.Render("You")
+	}
+	info := fmt.Sprintf(" message %d", hit.Index+1)
+	if !hit.Timestamp.IsZero() {
+		info += " · " + hit.Timestamp.Format("Jan 2 15:04")
+	}
+	return meta + previewMetaStyle.Render(info)
+}
+
+// maxPreviewDiffLines is how many lines of each edit's diff the preview shows
+const maxPreviewDiffLines = 12
+
+// renderToolCall renders a tool call as a one-line summary and its collapsed result
+// File edits also show their diff, cut to maxDiffLines lines.
+func renderToolCall(tool session.ToolCall, maxDiffLines int) []string {
+	diffs := tool.Diffs()
+	call := toolNameStyle.Render("⚙ " + tool.Name)
+	if len(diffs) == 1 {
+		// The call line doubles as the file header
+		call += " " + renderDiffHeader(diffs[0])
+	} else if tool.Summary != "" {
+		call += " " + helpStyle.Render(tool.Summary)
+	}
+	lines := []string{call}
+	for i, d := range diffs {
+		if len(diffs) > 1 {
+			lines = append(lines, "  "+renderDiffHeader(diffs[i]))
+		}
+		lines = append(lines, renderDiff(d, maxDiffLines)...)
+	}
+
+	switch {
+	case tool.Result == nil:
+		lines = append(lines, helpStyle.Render("  ⎿ …"))
+	case tool.Result.IsError:
+		lines = append(lines, helpStyle.Render("  ⎿ ")+statusErrorStyle.Render("✗ "+tool.Result.Summary))
+	default:
+		lines = append(lines, helpStyle.Render("  ⎿ ")+statusRunningStyle.Render("✓")+helpStyle.Render(" "+tool.Result.Summary))
+	}
+	return lines
+}
 
-	// Wrap text to fit width
-	content := wrapText(msg.Content, m.width-2)
+// renderDiffHeader renders a file's path and how many lines were added and removed
+func renderDiffHeader(d session.FileDiff) string {
+	header := diffHeaderStyle.Render(d.Path)
+	if d.Added > 0 {
+		header += " " + diffAddStyle.Render(fmt.Sprintf("+%d", d.Added))
+	}
+	if d.Removed > 0 {
+		header += " " + diffRemoveStyle.Render(fmt.Sprintf("-%d", d.Removed))
+	}
+	return header
+}
 
-	return header + "\n" + contentStyle.Render(content)
+// renderDiff renders diff lines in the theme's success/error colours
+// At most maxLines are shown (0 for all).
+func renderDiff(d session.FileDiff, maxLines int) []string {
+	lines := d.Lines
+	var more int
+	if maxLines > 0 && len(lines) > maxLines {
+		more = len(lines) - maxLines
+		lines = lines[:maxLines]
+	}
+
+	out := make([]string, 0, len(lines)+1)
+	for _, l := range lines {
+		text := strings.ReplaceAll(l.Text, "\t", "    ")
+		switch l.Op {
+		case session.DiffAdd:
+			out = append(out, diffAddStyle.Render("    + "+text))
+		case session.DiffRemove:
+			out = append(out, diffRemoveStyle.Render("    - "+text))
+		case session.DiffGap:
+			out = append(out, helpStyle.Render("    ⋯"))
+		default:
+			out = append(out, helpStyle.Render("      "+text))
+		}
+	}
+	if more > 0 {
+		out = append(out, helpStyle.Render(fmt.Sprintf("    … %d more lines", more)))
+	}
+	return out
 }
 
 // wrapText wraps text to fit within maxWidth
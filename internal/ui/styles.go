@@ -201,6 +201,9 @@ var (
 	statusRunningStyle    lipgloss.Style
 	statusWaitingStyle    lipgloss.Style
 	statusIdleStyle       lipgloss.Style
+	statusPermissionStyle lipgloss.Style
+	statusReplyStyle      lipgloss.Style
+	statusErrorStyle      lipgloss.Style
 	previewTitleStyle     lipgloss.Style
 	previewMetaStyle      lipgloss.Style
 	userMessageStyle      lipgloss.Style
@@ -217,6 +220,21 @@ var (
 	searchStyle           lipgloss.Style
 	searchPromptStyle     lipgloss.Style
 	matchHighlightStyle   lipgloss.Style
+	currentMatchStyle     lipgloss.Style
+	toolNameStyle         lipgloss.Style
+	diffHeaderStyle       lipgloss.Style
+	diffAddStyle          lipgloss.Style
+	diffRemoveStyle       lipgloss.Style
+	markdownHeadingStyle  lipgloss.Style
+	markdownBulletStyle   lipgloss.Style
+	markdownQuoteStyle    lipgloss.Style
+	inlineCodeStyle       lipgloss.Style
+	codeFenceStyle        lipgloss.Style
+	codeStyle             lipgloss.Style
+	codeKeywordStyle      lipgloss.Style
+	codeStringStyle       lipgloss.Style
+	codeNumberStyle       lipgloss.Style
+	codeCommentStyle      lipgloss.Style
 )
 
 // CurrentThemeName tracks the active theme
@@ -297,6 +315,17 @@ func ApplyTheme(name string) {
 	statusIdleStyle = lipgloss.NewStyle().
 		Foreground(overlayColor)
 
+	statusPermissionStyle = lipgloss.NewStyle().
+		Foreground(warningColor).
+		Bold(true)
+
+	statusReplyStyle = lipgloss.NewStyle().
+		Foreground(secondaryColor)
+
+	statusErrorStyle = lipgloss.NewStyle().
+		Foreground(errorColor).
+		Bold(true)
+
 	previewTitleStyle = lipgloss.NewStyle().
 		Bold(true).
 		Foreground(primaryColor)
@@ -362,6 +391,59 @@ func ApplyTheme(name string) {
 	matchHighlightStyle = lipgloss.NewStyle().
 		Foreground(warningColor).
 		Bold(true)
+
+	currentMatchStyle = lipgloss.NewStyle().
+		Foreground(baseColor).
+		Background(warningColor).
+		Bold(true)
+
+	toolNameStyle = lipgloss.NewStyle().
+		Foreground(secondaryColor).
+		Bold(true)
+
+	diffHeaderStyle = lipgloss.NewStyle().
+		Foreground(textColor).
+		Bold(true)
+
+	diffAddStyle = lipgloss.NewStyle().
+		Foreground(successColor)
+
+	diffRemoveStyle = lipgloss.NewStyle().
+		Foreground(errorColor)
+
+	markdownHeadingStyle = lipgloss.NewStyle().
+		Foreground(primaryColor).
+		Bold(true)
+
+	markdownBulletStyle = lipgloss.NewStyle().
+		Foreground(secondaryColor)
+
+	markdownQuoteStyle = lipgloss.NewStyle().
+		Foreground(mutedColor).
+		Italic(true)
+
+	inlineCodeStyle = lipgloss.NewStyle().
+		Foreground(warningColor)
+
+	codeFenceStyle = lipgloss.NewStyle().
+		Foreground(overlayColor)
+
+	codeStyle = lipgloss.NewStyle().
+		Foreground(textColor)
+
+	codeKeywordStyle = lipgloss.NewStyle().
+		Foreground(secondaryColor).
+		Bold(true)
+
+	codeStringStyle = lipgloss.NewStyle().
+		Foreground(successColor)
+
+	codeNumberStyle = lipgloss.NewStyle().
+		Foreground(warningColor)
+
+	codeCommentStyle = lipgloss.NewStyle().
+		Foreground(mutedColor).
+		Italic(true)
 }
 
 // StatusStyle returns the appropriate style for a status
@@ -371,6 +453,12 @@ func StatusStyle(status string) lipgloss.Style {
 		return statusRunningStyle
 	case "waiting":
 		return statusWaitingStyle
+	case "needs permission":
+		return statusPermissionStyle
+	case "awaiting reply":
+		return statusReplyStyle
+	case "errored":
+		return statusErrorStyle
 	default:
 		return statusIdleStyle
 	}
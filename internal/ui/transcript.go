@@ -0,0 +1,579 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/hadar/claude-deck/internal/session"
+)
+
+// TranscriptPageMsg is sent when a page of the transcript viewer has been read
+type TranscriptPageMsg struct {
+	Path string
+	From int64 // offset the page was read from
+	Page session.ConversationPage
+	Err  error
+}
+
+// transcriptJump is a jump waiting for more pages to load
+type transcriptJump int
+
+const (
+	jumpNone     transcriptJump = iota
+	jumpBottom                  // to the end of the transcript
+	jumpNextUser                // to the next user message
+	jumpSearch                  // to the first match of a new search
+	jumpMessage                 // to the message at target (a search hit)
+)
+
+// transcriptLine is one rendered (wrapped) line of the transcript
+type transcriptLine struct {
+	text   string
+	role   string
+	header bool   // role + time line starting a message
+	styled string // pre-rendered tool call or diff line (text is its plain form)
+}
+
+// transcriptMatch is a search match: line index and byte column
+type transcriptMatch struct {
+	line int
+	col  int
+}
+
+// TranscriptModel is the full-screen transcript viewer
+// Pages of the JSONL are read lazily as the view scrolls towards the end.
+type TranscriptModel struct {
+	session  *session.Session
+	path     string
+	messages []session.ConversationMessage
+	next     int64 // offset of the next page
+	done     bool  // no more pages (until the file grows)
+	loading  bool  // a page read is in flight
+	pending  transcriptJump
+	target   int64 // JSONL offset of the message jumpMessage goes to
+	follow   bool // scroll to the end when the appended lines arrive
+	err      error
+
+	showTools bool // show tool calls and file diffs
+
+	width    int
+	height   int
+	lines    []transcriptLine
+	msgStart []int // first line of each message
+	offset   int   // first visible line
+
+	// In-transcript search
+	searching   bool // typing a query
+	input       string
+	inputCursor int
+	query       string
+	matches     []transcriptMatch
+	current     int // index into matches
+}
+
+// NewTranscriptModel creates a closed transcript viewer
+func NewTranscriptModel() *TranscriptModel {
+	return &TranscriptModel{}
+}
+
+// Open shows the transcript of a session, starting at the top
+// Returns a command that reads the first page
+func (m *TranscriptModel) Open(s *session.Session, showTools bool) tea.Cmd {
+	*m = TranscriptModel{width: m.width, height: m.height, session: s, path: s.JSONLPath, showTools: showTools}
+	return m.loadMore()
+}
+
+// OpenAt shows the transcript of a session scrolled to the message at a JSONL
+// offset, with match highlighted as a search
+func (m *TranscriptModel) OpenAt(s *session.Session, showTools bool, offset int64, match string) tea.Cmd {
+	cmd := m.Open(s, showTools)
+	m.pending = jumpMessage
+	m.target = offset
+	m.query = match
+	return cmd
+}
+
+// Close hides the viewer and drops its messages
+func (m *TranscriptModel) Close() {
+	*m = TranscriptModel{width: m.width, height: m.height}
+}
+
+// IsOpen returns whether the viewer is showing
+func (m *TranscriptModel) IsOpen() bool {
+	return m.session != nil
+}
+
+// IsSearching returns whether a search query is being typed
+func (m *TranscriptModel) IsSearching() bool {
+	return m.searching
+}
+
+// SetSize sets the size of the whole screen
+func (m *TranscriptModel) SetSize(width, height int) {
+	if width == m.width && height == m.height {
+		return
+	}
+	m.width = width
+	m.height = height
+	if m.IsOpen() {
+		m.rebuild()
+	}
+}
+
+// loadMore reads the next page unless one is already being read
+func (m *TranscriptModel) loadMore() tea.Cmd {
+	if m.loading || m.done || m.path == "" {
+		return nil
+	}
+	m.loading = true
+	path, from := m.path, m.next
+	return func() tea.Msg {
+		page, err := session.ReadConversation(path, from, session.ConversationPageSize)
+		return TranscriptPageMsg{Path: path, From: from, Page: page, Err: err}
+	}
+}
+
+// HandlePage adds a page that finished loading and carries on with a pending jump
+func (m *TranscriptModel) HandlePage(msg TranscriptPageMsg) tea.Cmd {
+	// Ignore pages for a closed or different transcript
+	if !m.IsOpen() || msg.Path != m.path || msg.From != m.next {
+		return nil
+	}
+	m.loading = false
+	if msg.Err != nil {
+		m.err = msg.Err
+		m.done = true
+		m.pending = jumpNone
+		return nil
+	}
+
+	m.messages = append(m.messages, msg.Page.Messages...)
+	m.next = msg.Page.Next
+	m.done = msg.Page.Done
+	m.rebuild()
+	if m.query != "" {
+		m.findMatches()
+	}
+	// A transcript viewed at the end keeps following it as Claude writes
+	if m.follow {
+		m.follow = false
+		m.offset = m.maxOffset()
+	}
+
+	switch m.pending {
+	case jumpBottom:
+		if m.done {
+			m.pending = jumpNone
+			m.offset = m.maxOffset()
+		}
+	case jumpNextUser:
+		if m.jumpToUser(1) || m.done {
+			m.pending = jumpNone
+		}
+	case jumpSearch:
+		if m.done {
+			m.pending = jumpNone
+			m.jumpToMatch(0)
+		}
+	case jumpMessage:
+		if m.jumpToTarget() || m.done {
+			m.pending = jumpNone
+		}
+	}
+	if m.pending != jumpNone {
+		return m.loadMore()
+	}
+	return m.loadIfNearEnd()
+}
+
+// Refresh reads lines appended to a fully loaded transcript
+func (m *TranscriptModel) Refresh(path string) tea.Cmd {
+	if !m.IsOpen() || path != m.path || !m.done || m.err != nil {
+		return nil
+	}
+	m.done = false
+	m.follow = m.pending == jumpNone && m.offset >= m.maxOffset()
+	return m.loadMore()
+}
+
+// loadIfNearEnd reads the next page once less than a screen is left below the view
+func (m *TranscriptModel) loadIfNearEnd() tea.Cmd {
+	if m.offset+2*m.bodyHeight() >= len(m.lines) {
+		return m.loadMore()
+	}
+	return nil
+}
+
+// Update handles keys and mouse wheel events while the viewer is open
+// Returns whether the viewer should close.
+func (m *TranscriptModel) Update(msg tea.Msg) (tea.Cmd, bool) {
+	switch msg := msg.(type) {
+	case tea.MouseMsg:
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			m.scroll(-3)
+		case tea.MouseButtonWheelDown:
+			m.scroll(3)
+			return m.loadIfNearEnd(), false
+		}
+		return nil, false
+
+	case tea.KeyMsg:
+		if m.searching {
+			return m.updateSearchInput(msg), false
+		}
+
+		page := m.bodyHeight() - 1
+		switch msg.String() {
+		case "esc", "q", "V":
+			return nil, true
+		case "up", "k":
+			m.scroll(-1)
+		case "down", "j":
+			m.scroll(1)
+		case "pgup", "b", "shift+up":
+			m.scroll(-page)
+		case "pgdown", " ", "f", "shift+down":
+			m.scroll(page)
+		case "home", "g":
+			m.pending = jumpNone
+			m.offset = 0
+		case "end", "G":
+			if !m.done {
+				m.pending = jumpBottom
+				return m.loadMore(), false
+			}
+			m.offset = m.maxOffset()
+		case "]":
+			if !m.jumpToUser(1) && !m.done {
+				m.pending = jumpNextUser
+				return m.loadMore(), false
+			}
+		case "[":
+			m.jumpToUser(-1)
+		case "t":
+			m.showTools = !m.showTools
+			m.rebuild()
+			if m.query != "" {
+				m.findMatches()
+			}
+		case "/":
+			m.searching = true
+			m.input = m.query
+			m.inputCursor = len(m.input)
+		case "n":
+			m.jumpToMatch(1)
+		case "N":
+			m.jumpToMatch(-1)
+		}
+		return m.loadIfNearEnd(), false
+	}
+	return nil, false
+}
+
+// updateSearchInput handles keys while typing a search query
+func (m *TranscriptModel) updateSearchInput(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.searching = false
+	case "enter":
+		m.searching = false
+		m.query = strings.TrimSpace(m.input)
+		m.current = 0
+		m.findMatches()
+		if m.query == "" {
+			return nil
+		}
+		// Search the whole transcript, not just what's loaded
+		if !m.done {
+			m.pending = jumpSearch
+			return m.loadMore()
+		}
+		m.jumpToMatch(0)
+	default:
+		if text, cursor, ok := handleTextInputKey(m.input, m.inputCursor, msg.String()); ok {
+			m.input, m.inputCursor = text, cursor
+		}
+	}
+	return nil
+}
+
+// scroll moves the view by n lines
+func (m *TranscriptModel) scroll(n int) {
+	m.offset = max(0, min(m.offset+n, m.maxOffset()))
+}
+
+// bodyHeight is the number of transcript lines on screen (minus header and footer)
+func (m *TranscriptModel) bodyHeight() int {
+	return max(1, m.height-2)
+}
+
+// maxOffset is the offset that shows the last line at the bottom
+func (m *TranscriptModel) maxOffset() int {
+	return max(0, len(m.lines)-m.bodyHeight())
+}
+
+// jumpToUser scrolls to the next (dir 1) or previous (dir -1) user message
+// Returns false if there's none in the loaded part.
+func (m *TranscriptModel) jumpToUser(dir int) bool {
+	if dir > 0 {
+		for i, msg := range m.messages {
+			if msg.Role == "user" && msg.Content != "" && m.msgStart[i] > m.offset {
+				m.offset = min(m.msgStart[i], m.maxOffset())
+				return true
+			}
+		}
+		return false
+	}
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].Role == "user" && m.messages[i].Content != "" && m.msgStart[i] < m.offset {
+			m.offset = m.msgStart[i]
+			return true
+		}
+	}
+	return false
+}
+
+// jumpToTarget scrolls to the message at the target offset, and to the first
+// match in it. Returns false if it isn't loaded yet.
+func (m *TranscriptModel) jumpToTarget() bool {
+	for i, msg := range m.messages {
+		if msg.Offset < m.target {
+			continue
+		}
+		m.offset = min(m.msgStart[i], m.maxOffset())
+		for j, match := range m.matches {
+			if match.line >= m.msgStart[i] {
+				m.current = j
+				if match.line >= m.offset+m.bodyHeight() {
+					m.offset = min(match.line-3, m.maxOffset())
+				}
+				break
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// findMatches finds every case-insensitive occurrence of the query in message text
+func (m *TranscriptModel) findMatches() {
+	m.matches = nil
+	if m.query == "" {
+		return
+	}
+	query := strings.ToLower(m.query)
+	for i, line := range m.lines {
+		if line.header || line.styled != "" {
+			continue
+		}
+		lower := strings.ToLower(line.text)
+		for col := 0; ; {
+			idx := strings.Index(lower[col:], query)
+			if idx < 0 {
+				break
+			}
+			m.matches = append(m.matches, transcriptMatch{line: i, col: col + idx})
+			col += idx + len(query)
+		}
+	}
+	// Pages loaded later only add matches, so the current one stays put
+	m.current = min(m.current, max(0, len(m.matches)-1))
+}
+
+// jumpToMatch moves to the next (dir 1) or previous (dir -1) match, or with dir 0
+// to the first match at or below the top of the view
+func (m *TranscriptModel) jumpToMatch(dir int) {
+	if len(m.matches) == 0 {
+		return
+	}
+	switch dir {
+	case 0:
+		m.current = 0
+		for i, match := range m.matches {
+			if match.line >= m.offset {
+				m.current = i
+				break
+			}
+		}
+	default:
+		m.current = (m.current + dir + len(m.matches)) % len(m.matches)
+	}
+
+	// Keep the match on screen, a few lines from the top
+	line := m.matches[m.current].line
+	if line < m.offset || line >= m.offset+m.bodyHeight() {
+		m.offset = max(0, min(line-3, m.maxOffset()))
+	}
+}
+
+// rebuild wraps the loaded messages to the current width
+func (m *TranscriptModel) rebuild() {
+	width := max(10, m.width-2)
+	m.lines = m.lines[:0]
+	m.msgStart = m.msgStart[:0]
+
+	// Results arrive in later messages than their calls
+	var results map[string]*session.ToolResult
+	if m.showTools {
+		results = make(map[string]*session.ToolResult)
+		for _, msg := range m.messages {
+			for id, result := range msg.Results {
+				results[id] = result
+			}
+		}
+	}
+
+	for _, msg := range m.messages {
+		m.msgStart = append(m.msgStart, len(m.lines))
+		// Messages that only carry tool results have nothing to show
+		if msg.Content == "" && (!m.showTools || len(msg.Tools) == 0) {
+			continue
+		}
+		if len(m.lines) > 0 {
+			m.lines = append(m.lines, transcriptLine{})
+			m.msgStart[len(m.msgStart)-1]++
+		}
+
+		header := "Claude"
+		if msg.Role == "user" {
+			header = "You"
+		}
+		if !msg.Timestamp.IsZero() {
+			header += " " + msg.Timestamp.Format("Jan 2 15:04")
+		}
+		m.lines = append(m.lines, transcriptLine{text: header, role: msg.Role, header: true})
+		if msg.Content != "" {
+			for _, line := range strings.Split(wrapText(msg.Content, width), "\n") {
+				m.lines = append(m.lines, transcriptLine{text: line, role: msg.Role})
+			}
+		}
+		if !m.showTools {
+			continue
+		}
+		for _, tool := range msg.Tools {
+			tool.Result = results[tool.ID]
+			for _, line := range renderToolCall(tool, 0) {
+				m.lines = append(m.lines, transcriptLine{text: ansi.Strip(line), role: msg.Role, styled: line})
+			}
+		}
+	}
+	m.offset = min(m.offset, m.maxOffset())
+}
+
+// View renders the viewer full screen
+func (m *TranscriptModel) View() string {
+	body := m.bodyHeight()
+	result := make([]string, 0, body+2)
+
+	// Header: session name and position
+	title := previewTitleStyle.Render("Transcript: " + m.session.Name)
+	var position string
+	switch {
+	case m.err != nil:
+		position = fmt.Sprintf("Error: %v", m.err)
+	case len(m.lines) == 0 && m.loading:
+		position = "Loading..."
+	case len(m.lines) == 0:
+		position = "No messages"
+	default:
+		position = fmt.Sprintf("%d messages", len(m.messages))
+		if !m.done {
+			position = fmt.Sprintf("%d+ messages", len(m.messages))
+		}
+		position += fmt.Sprintf("  %d%%", (min(m.offset+body, len(m.lines)))*100/len(m.lines))
+	}
+	result = append(result, m.padLine(title+"  "+helpStyle.Render(position)))
+
+	currentLine := -1
+	if len(m.matches) > 0 {
+		currentLine = m.matches[m.current].line
+	}
+	for i := 0; i < body; i++ {
+		idx := m.offset + i
+		if idx >= len(m.lines) {
+			result = append(result, m.padLine(""))
+			continue
+		}
+		result = append(result, m.padLine(m.renderLine(idx, idx == currentLine)))
+	}
+
+	// Footer: search prompt, match count or key help
+	var footer string
+	switch {
+	case m.searching:
+		footer = searchPromptStyle.Render("/") + m.input[:m.inputCursor] + "█" + m.input[m.inputCursor:]
+	case m.query != "" && m.pending == jumpSearch:
+		footer = helpStyle.Render("Searching...")
+	case m.query != "":
+		status := "no matches"
+		if len(m.matches) > 0 {
+			status = fmt.Sprintf("match %d/%d", m.current+1, len(m.matches))
+		}
+		footer = helpStyle.Render(fmt.Sprintf("%q: %s  n/N:next/prev  /:search  Esc:close", m.query, status))
+	default:
+		footer = helpStyle.Render("↑↓/PgUp/PgDn:scroll  g/G:top/bottom  [/]:prev/next prompt  t:tools  /:search  Esc:close")
+	}
+	result = append(result, m.padLine(footer))
+
+	return strings.Join(result, "\n")
+}
+
+// renderLine styles a transcript line, highlighting search matches
+func (m *TranscriptModel) renderLine(idx int, hasCurrent bool) string {
+	line := m.lines[idx]
+	if line.styled != "" {
+		return ansi.Truncate(line.styled, m.width, "")
+	}
+	text := truncateString(line.text, m.width)
+	if line.header {
+		if line.role == "user" {
+			return userRoleStyle.Render(text)
+		}
+		return assistantRoleStyle.Render(text)
+	}
+	style := RoleStyle(line.role)
+	if m.query == "" {
+		return style.Render(text)
+	}
+
+	// Split the line around matches (only where lowercasing keeps byte offsets)
+	lower := strings.ToLower(text)
+	query := strings.ToLower(m.query)
+	if len(lower) != len(text) {
+		return style.Render(text)
+	}
+	var b strings.Builder
+	currentCol := -1
+	if hasCurrent {
+		currentCol = m.matches[m.current].col
+	}
+	col := 0
+	for {
+		idx := strings.Index(lower[col:], query)
+		if idx < 0 {
+			break
+		}
+		start, end := col+idx, col+idx+len(query)
+		b.WriteString(style.Render(text[col:start]))
+		if start == currentCol {
+			b.WriteString(currentMatchStyle.Render(text[start:end]))
+		} else {
+			b.WriteString(matchHighlightStyle.Render(text[start:end]))
+		}
+		col = end
+	}
+	b.WriteString(style.Render(text[col:]))
+	return b.String()
+}
+
+// padLine pads a line to the full width
+func (m *TranscriptModel) padLine(line string) string {
+	if w := lipgloss.Width(line); w < m.width {
+		return line + strings.Repeat(" ", m.width-w)
+	}
+	return line
+}
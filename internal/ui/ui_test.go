@@ -1,8 +1,18 @@
 package ui
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/hadar/claude-deck/internal/session"
 )
 
 func TestTruncate(t *testing.T) {
@@ -300,6 +310,9 @@ func TestStatusStyle(t *testing.T) {
 	_ = StatusStyle("running")
 	_ = StatusStyle("waiting")
 	_ = StatusStyle("idle")
+	_ = StatusStyle("needs permission")
+	_ = StatusStyle("awaiting reply")
+	_ = StatusStyle("errored")
 	_ = StatusStyle("unknown")
 }
 
@@ -339,3 +352,451 @@ func TestThemeNames(t *testing.T) {
 		t.Errorf("ThemeNames has %d entries but Themes has %d", len(ThemeNames), len(Themes))
 	}
 }
+
+func TestKeyToBytes(t *testing.T) {
+	tests := []struct {
+		name      string
+		key       tea.KeyMsg
+		appCursor bool
+		want      string
+	}{
+		{"runes", tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("hé")}, false, "hé"},
+		{"alt rune", tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b"), Alt: true}, false, "\x1bb"},
+		{"space", tea.KeyMsg{Type: tea.KeySpace}, false, " "},
+		{"enter", tea.KeyMsg{Type: tea.KeyEnter}, false, "\r"},
+		{"ctrl+c", tea.KeyMsg{Type: tea.KeyCtrlC}, false, "\x03"},
+		{"backspace", tea.KeyMsg{Type: tea.KeyBackspace}, false, "\x7f"},
+		{"esc", tea.KeyMsg{Type: tea.KeyEsc}, false, "\x1b"},
+		{"up", tea.KeyMsg{Type: tea.KeyUp}, false, "\x1b[A"},
+		{"up app cursor", tea.KeyMsg{Type: tea.KeyUp}, true, "\x1bOA"},
+		{"shift+tab", tea.KeyMsg{Type: tea.KeyShiftTab}, false, "\x1b[Z"},
+		{"delete", tea.KeyMsg{Type: tea.KeyDelete}, false, "\x1b[3~"},
+		{"unmapped", tea.KeyMsg{Type: tea.KeyF20}, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(keyToBytes(tt.key, tt.appCursor))
+			if got != tt.want {
+				t.Errorf("keyToBytes() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListQueryFilter(t *testing.T) {
+	m := &session.Manager{
+		Sessions: []*session.Session{
+			{ID: "a", ClaudeSessionID: "a", Name: "alpha", ProjectPath: "/work/api", Order: 1},
+			{ID: "b", ClaudeSessionID: "b", Name: "beta", ProjectPath: "/work/web", Order: 2, MessageCount: 80},
+		},
+	}
+	list := NewListModel(m)
+	names := func() string {
+		var names []string
+		for _, idx := range list.filtered {
+			names = append(names, list.items[idx].Name())
+		}
+		return strings.Join(names, ",")
+	}
+
+	list.SetFilter("project:web")
+	if got := names(); got != "beta" {
+		t.Errorf("project:web shows %q", got)
+	}
+	list.SetFilter("msgs:>50 OR alpha")
+	if got := names(); got != "alpha,beta" {
+		t.Errorf("msgs:>50 OR alpha shows %q", got)
+	}
+
+	// A half-typed query keeps the last one that parsed
+	list.SetFilter("project:web")
+	list.SetFilter("project:web msgs:>")
+	if list.filterErr == nil || names() != "beta" {
+		t.Errorf("invalid query: err %v, shows %q", list.filterErr, names())
+	}
+}
+
+func TestListFuzzyRanking(t *testing.T) {
+	m := &session.Manager{
+		Sessions: []*session.Session{
+			{ID: "a", ClaudeSessionID: "a", Name: "dev checklist", Order: 1},
+			{ID: "b", ClaudeSessionID: "b", Name: "deck", Order: 2},
+			{ID: "c", ClaudeSessionID: "c", Name: "notes", ProjectPath: "/work/claude-deck", Order: 3},
+		},
+	}
+	list := NewListModel(m)
+	names := func() string {
+		var names []string
+		for _, idx := range list.filtered {
+			names = append(names, list.items[idx].Name())
+		}
+		return strings.Join(names, ",")
+	}
+
+	// Best match first (ties keep list order); the folder name counts too
+	list.SetFilter("deck")
+	if got := names(); got != "deck,notes,dev checklist" || !list.ranked {
+		t.Errorf("deck shows %q (ranked %v)", got, list.ranked)
+	}
+	if got := fmt.Sprint(sortedKeys(list.nameHighlights("dev checklist"))); got != "[0 1 4 8]" {
+		t.Errorf("highlights = %s", got)
+	}
+
+	// Field-only queries keep list order
+	list.SetFilter("status:idle")
+	if list.ranked || names() != "dev checklist,deck,notes" {
+		t.Errorf("status:idle shows %q (ranked %v)", names(), list.ranked)
+	}
+
+	row := highlightRuns("dev checklist", map[int]bool{0: true, 4: true}, itemStyle, matchHighlightStyle)
+	if ansi.Strip(row) != "dev checklist" {
+		t.Errorf("highlightRuns() = %q", ansi.Strip(row))
+	}
+}
+
+func sortedKeys(set map[int]bool) []int {
+	var keys []int
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+func TestListSelectSession(t *testing.T) {
+	m := &session.Manager{
+		Sessions: []*session.Session{
+			{ID: "a", ClaudeSessionID: "a", Name: "alpha", Order: 1},
+			{ID: "b", ClaudeSessionID: "b", Name: "beta", Order: 2},
+		},
+	}
+	list := NewListModel(m)
+
+	if !list.SelectSession("b") {
+		t.Fatal("SelectSession(b) = false")
+	}
+	if item := list.SelectedItem(); item == nil || item.IsGroup() || item.Session.ID != "b" {
+		t.Errorf("selected %+v, want session b", item)
+	}
+
+	// A filter hiding the session is cleared
+	list.SetFilter("alpha")
+	if !list.SelectSession("b") || list.filter != "" {
+		t.Error("SelectSession should clear a filter that hides the session")
+	}
+	if list.SelectSession("missing") {
+		t.Error("SelectSession(missing) = true")
+	}
+}
+
+func TestListContentSearchResults(t *testing.T) {
+	m := &session.Manager{
+		Sessions: []*session.Session{
+			{ID: "a", ClaudeSessionID: "a", Name: "alpha", Order: 1},
+			{ID: "b", ClaudeSessionID: "b", Name: "beta", Order: 2},
+			{ID: "c", ClaudeSessionID: "c", Name: "gamma", Order: 3},
+		},
+	}
+	list := NewListModel(m)
+	list.StartContentSearch()
+	list.contentSearchQuery = "needle"
+
+	list.HandleContentSearchResults(ContentSearchResultsMsg{
+		Query:   "needle",
+		Results: []session.SearchResult{{Session: m.Sessions[1]}, {Session: m.Sessions[2]}},
+	})
+	if len(list.filtered) != 2 {
+		t.Fatalf("filtered = %v, want 2 results", list.filtered)
+	}
+	if item := list.SelectedItem(); item == nil || item.Session.ID != "b" {
+		t.Errorf("selected %+v, want first result b", item)
+	}
+
+	// Results for an older query are dropped
+	list.HandleContentSearchResults(ContentSearchResultsMsg{
+		Query:   "need",
+		Results: []session.SearchResult{{Session: m.Sessions[0]}},
+	})
+	if len(list.contentSearchResults) != 2 {
+		t.Errorf("stale results applied: %d results", len(list.contentSearchResults))
+	}
+}
+
+func TestTranscriptViewer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	var b strings.Builder
+	for i := 0; i < session.ConversationPageSize+50; i++ {
+		role := "assistant"
+		if i%10 == 0 {
+			role = "user"
+		}
+		fmt.Fprintf(&b, `{"type":"%s","message":{"role":"%s","content":"message %d"}}`+"\n", role, role, i)
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewTranscriptModel()
+	m.SetSize(80, 22)
+	run := func(cmd tea.Cmd) {
+		for cmd != nil {
+			msg, ok := cmd().(TranscriptPageMsg)
+			if !ok {
+				t.Fatal("expected a transcript page")
+			}
+			cmd = m.HandlePage(msg)
+		}
+	}
+	key := func(k string) {
+		var msg tea.KeyMsg
+		switch k {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		}
+		cmd, closed := m.Update(msg)
+		if closed {
+			t.Fatalf("%q closed the viewer", k)
+		}
+		run(cmd)
+	}
+
+	run(m.Open(&session.Session{Name: "test", JSONLPath: path}, false))
+	if len(m.messages) != session.ConversationPageSize || m.done {
+		t.Fatalf("loaded %d messages, done %v; want one page", len(m.messages), m.done)
+	}
+
+	key("]")
+	if m.offset != m.msgStart[10] {
+		t.Errorf("next prompt at line %d, want %d", m.offset, m.msgStart[10])
+	}
+
+	key("G")
+	if !m.done || len(m.messages) != session.ConversationPageSize+50 || m.offset != m.maxOffset() {
+		t.Errorf("G: %d messages, done %v, offset %d/%d", len(m.messages), m.done, m.offset, m.maxOffset())
+	}
+
+	key("g")
+	for _, k := range []string{"/", "m", "e", "s", "s", "a", "g", "e", " ", "2", "4", "5", "enter"} {
+		key(k)
+	}
+	if len(m.matches) != 1 || m.lines[m.matches[0].line].text != "message 245" {
+		t.Fatalf("matches = %+v", m.matches)
+	}
+	if line := m.matches[0].line; line < m.offset || line >= m.offset+m.bodyHeight() {
+		t.Errorf("match line %d not visible from offset %d", line, m.offset)
+	}
+	if !strings.Contains(m.View(), "match 1/1") {
+		t.Error("view should show the match count")
+	}
+
+	if _, closed := m.Update(tea.KeyMsg{Type: tea.KeyEsc}); !closed {
+		t.Error("esc should close the viewer")
+	}
+}
+
+func TestRenderToolCall(t *testing.T) {
+	tests := []struct {
+		name string
+		tool session.ToolCall
+		want []string
+	}{
+		{"running", session.ToolCall{Name: "Bash", Summary: "go test"}, []string{"⚙ Bash", "go test", "⎿ …"}},
+		{"ok", session.ToolCall{Name: "Read", Result: &session.ToolResult{Summary: "12 lines"}}, []string{"⚙ Read", "✓", "12 lines"}},
+		{"failed", session.ToolCall{Name: "Bash", Result: &session.ToolResult{IsError: true, Summary: "Exit code 1"}}, []string{"✗ Exit code 1"}},
+		{"edit diff", session.ToolCall{Name: "Edit", Input: map[string]any{"file_path": "/work/a.go", "old_string": "x := 1", "new_string": "x := 2"}},
+			[]string{"⚙ Edit", "/work/a.go", "+1", "-1", "    - x := 1", "    + x := 2"}},
+		{"long write", session.ToolCall{Name: "Write", Input: map[string]any{"file_path": "/work/b.go", "content": strings.Repeat("line\n", 20)}},
+			[]string{"+20", "… 8 more lines"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := strings.Join(renderToolCall(tt.tool, maxPreviewDiffLines), "\n")
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("renderToolCall() = %q, missing %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestTranscriptToolCalls(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.jsonl")
+	content := `{"type":"user","message":{"role":"user","content":"Bump x"}}
+{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Edit","input":{"file_path":"/work/a.go","old_string":"x := 1","new_string":"x := 2"}}]}}
+{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"ok"}]}}
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewTranscriptModel()
+	m.SetSize(80, 20)
+	msg := m.Open(&session.Session{Name: "test", JSONLPath: path}, true)()
+	m.HandlePage(msg.(TranscriptPageMsg))
+
+	text := func() string {
+		var lines []string
+		for _, line := range m.lines {
+			lines = append(lines, line.text)
+		}
+		return strings.Join(lines, "\n")
+	}
+	got := text()
+	for _, want := range []string{"⚙ Edit /work/a.go +1 -1", "    - x := 1", "    + x := 2", "⎿ ✓ 1 line"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("transcript = %q, missing %q", got, want)
+		}
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	if got := text(); strings.Contains(got, "Edit") || !strings.Contains(got, "Bump x") {
+		t.Errorf("with tools hidden, transcript = %q", got)
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  []string
+	}{
+		{"heading", "## Plan", 40, []string{"Plan"}},
+		{"emphasis", "This is **very** *important* and `code`", 80, []string{"This is very important and code"}},
+		{"snake_case kept", "rename my_var_name to x", 80, []string{"rename my_var_name to x"}},
+		{"unclosed marker", "2 * 3 = 6", 80, []string{"2 * 3 = 6"}},
+		{"bullets with hanging indent", "- first item wraps here\n  1. nested", 16, []string{"• first item", "  wraps here", "  1. nested"}},
+		{"code block", "Run:\n```go\nfunc main() {}\n```\nDone", 80, []string{"Run:", "  go", "  func main() {}", "Done"}},
+		{"quote and rule", "> note\n---", 80, []string{"│ note", strings.Repeat("─", 40)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, line := range renderMarkdown(tt.text, tt.width, assistantMessageStyle) {
+				got = append(got, ansi.Strip(line))
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("renderMarkdown() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenizeCode(t *testing.T) {
+	tests := []struct {
+		line string
+		lang string
+		want []codeToken
+	}{
+		{`return "x" // done`, "go", []codeToken{{"return", codeKeyword}, {" ", codePlain}, {`"x"`, codeString}, {" ", codePlain}, {"// done", codeComment}}},
+		{"x = 42", "py", []codeToken{{"x = ", codePlain}, {"42", codeNumber}}},
+		{"echo a#b # note", "bash", []codeToken{{"echo a#b ", codePlain}, {"# note", codeComment}}},
+		{"if x", "unknown", []codeToken{{"if x", codePlain}}},
+	}
+
+	for _, tt := range tests {
+		got := tokenizeCode(tt.line, tt.lang)
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("tokenizeCode(%q, %q) = %v, want %v", tt.line, tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestSearchHitInTranscript(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	var b strings.Builder
+	for i := 0; i < session.ConversationPageSize+50; i++ {
+		text := fmt.Sprintf("message %d", i)
+		if i == 30 || i == 230 {
+			text = "here is the Migration Plan"
+		}
+		fmt.Fprintf(&b, `{"type":"user","message":{"role":"user","content":"%s"}}`+"\n", text)
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := &session.Session{ID: "s", Name: "test", JSONLPath: path}
+
+	results := session.SearchContent(context.Background(), []*session.Session{s}, "migration plan")
+	if len(results) != 1 || len(results[0].Hits) != 2 {
+		t.Fatalf("results = %+v, want one session with two hits", results)
+	}
+
+	// Tab cycles through the hits
+	preview := NewPreviewModel()
+	preview.SetSearchHits(results[0].Hits)
+	preview.NextHit(1)
+	hit := preview.CurrentHit()
+	if hit.Index != 230 {
+		t.Fatalf("second hit is message %d, want 230", hit.Index)
+	}
+	preview.NextHit(1)
+	if preview.CurrentHit().Index != 30 {
+		t.Error("NextHit should wrap around to the first hit")
+	}
+
+	// The transcript opens at the hit's message (on its second page) with the match current
+	m := NewTranscriptModel()
+	m.SetSize(80, 22)
+	for cmd := m.OpenAt(s, false, hit.Offset, hit.Match); cmd != nil; {
+		cmd = m.HandlePage(cmd().(TranscriptPageMsg))
+	}
+	if m.pending != jumpNone || len(m.messages) <= 230 {
+		t.Fatalf("pending %v with %d messages loaded", m.pending, len(m.messages))
+	}
+	if m.offset != m.msgStart[230] {
+		t.Errorf("offset = %d, want message 230 at line %d", m.offset, m.msgStart[230])
+	}
+	if len(m.matches) != 2 || m.matches[m.current].line <= m.msgStart[230] {
+		t.Errorf("matches = %+v, current %d; want the match in message 230", m.matches, m.current)
+	}
+}
+
+func TestUsageDisplay(t *testing.T) {
+	sonnet := func(month string, input int64) session.Usage {
+		return session.Usage{month: {"claude-sonnet-4-5": {Input: input}}}
+	}
+	m := &session.Manager{
+		Sessions: []*session.Session{
+			{ID: "a", ClaudeSessionID: "a", Name: "alpha", ProjectPath: "/work/api", Order: 1, Usage: sonnet("2026-10", 2_000_000)},
+			{ID: "b", ClaudeSessionID: "b", Name: "beta", ProjectPath: "/work/web", Order: 2, Usage: session.Usage{"2026-10": {"mystery": {Output: 10}}}},
+			{ID: "c", ClaudeSessionID: "c", Name: "gamma", ProjectPath: "/work/api", Order: 3},
+		},
+	}
+	prices := m.GetPrices()
+
+	// The cost column shows each session's estimate; unpriced models are marked
+	list := NewListModel(m)
+	list.SetSize(80, 10)
+	list.SetShowUsage(true, prices)
+	view := ansi.Strip(list.View())
+	if !strings.Contains(view, "Cost") || !strings.Contains(view, "$6.00") || !strings.Contains(view, "$0.00*") {
+		t.Errorf("cost column missing:\n%s", view)
+	}
+
+	preview := NewPreviewModel()
+	preview.SetPrices(prices)
+	preview.session = m.Sessions[0]
+	if header := ansi.Strip(preview.renderHeader()); !strings.Contains(header, "Usage: 2.0M in · 0 out · 0 cached · ~$6.00") {
+		t.Errorf("header missing usage:\n%s", header)
+	}
+
+	// The report totals projects for the month
+	app := &App{manager: m, width: 100, height: 30, showUsage: true, usageMonth: "2026-10", usageBy: "project"}
+	report := ansi.Strip(app.renderUsage())
+	for _, want := range []string{"Usage by project · October 2026", "/work/api", "/work/web", "Total", "no price"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q:\n%s", want, report)
+		}
+	}
+	app.updateUsage(tea.KeyMsg{Type: tea.KeyLeft})
+	if app.usageMonth != "2026-09" || !strings.Contains(ansi.Strip(app.renderUsage()), "No usage recorded") {
+		t.Errorf("previous month: %q", app.usageMonth)
+	}
+}